@@ -0,0 +1,93 @@
+// Copyright 2023 Adam Chalkley
+//
+// https://github.com/atc0005/go-nagios
+//
+// Licensed under the MIT License. See LICENSE file in the project root for
+// full license information.
+
+package nagios
+
+import (
+	"errors"
+	"strings"
+)
+
+// joinedError is an error wrapping a collection of errors. This provides the
+// behavior of errors.Join (added in Go 1.20) for use with older Go versions:
+// errors.Is and errors.As match any of the wrapped errors.
+type joinedError struct {
+	errs []error
+	sep  string
+}
+
+// joinErrors returns an error wrapping the given errors with the message of
+// each on a separate line. Nil errors are discarded; nil is returned if all
+// given errors are nil.
+func joinErrors(errs ...error) error {
+	return newJoinedError("\n", errs...)
+}
+
+// chainErrors returns an error wrapping the given errors with their messages
+// separated by a colon (e.g., for a specific sentinel error which is also
+// reported as a more general one).
+func chainErrors(errs ...error) error {
+	return newJoinedError(": ", errs...)
+}
+
+// newJoinedError returns an error wrapping the given non-nil errors with
+// their messages separated by sep. Nil is returned if all given errors are
+// nil.
+func newJoinedError(sep string, errs ...error) error {
+	e := &joinedError{sep: sep}
+
+	for _, err := range errs {
+		if err != nil {
+			e.errs = append(e.errs, err)
+		}
+	}
+
+	if len(e.errs) == 0 {
+		return nil
+	}
+
+	return e
+}
+
+// Error returns the messages of the wrapped errors joined by the separator.
+func (e *joinedError) Error() string {
+	msgs := make([]string, len(e.errs))
+	for i, err := range e.errs {
+		msgs[i] = err.Error()
+	}
+
+	return strings.Join(msgs, e.sep)
+}
+
+// Unwrap returns the wrapped errors. This is used by errors.Is and errors.As
+// as of Go 1.20.
+func (e *joinedError) Unwrap() []error {
+	return e.errs
+}
+
+// Is reports whether any of the wrapped errors matches target.
+func (e *joinedError) Is(target error) bool {
+	for _, err := range e.errs {
+		if errors.Is(err, target) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// As finds the first wrapped error matching target and, if found, sets target
+// to that error value and returns true.
+func (e *joinedError) As(target interface{}) bool {
+	for _, err := range e.errs {
+		if errors.As(err, target) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -96,6 +96,72 @@ var (
 	// metric is not in a supported format.
 	ErrInvalidPerformanceDataFormat = errors.New("invalid performance data format")
 
+	// ErrPerformanceDataUnknownUoM indicates that a given performance data
+	// metric uses a Unit of Measurement which is not one of the known units
+	// documented by the Nagios Plugin Dev Guidelines.
+	ErrPerformanceDataUnknownUoM = errors.New("unknown performance data unit of measurement")
+
+	// ErrPerformanceDataDuplicateLabel indicates that multiple performance
+	// data metrics were found using the same label.
+	ErrPerformanceDataDuplicateLabel = errors.New("duplicate performance data label")
+
+	// ErrPerformanceDataRoundTripMismatch indicates that parsing emitted
+	// performance data did not produce the same results as the original
+	// parsed performance data.
+	ErrPerformanceDataRoundTripMismatch = errors.New("performance data round trip mismatch")
+
+	// ErrPerformanceDataInconsistentThresholds indicates that the Warn and
+	// Crit thresholds for a performance data metric are logically
+	// contradictory.
+	ErrPerformanceDataInconsistentThresholds = errors.New("inconsistent performance data thresholds")
+
+	// ErrPerformanceDataUnexpectedSentinel indicates that the literal "U"
+	// (undetermined) value was found in a performance data field other than
+	// the Value field (e.g., Min or Max) where it is not permitted.
+	ErrPerformanceDataUnexpectedSentinel = errors.New("undetermined value sentinel only valid for performance data Value field")
+
+	// ErrPerformanceDataValueUndetermined indicates that an operation
+	// requiring a numeric performance data value was attempted using a
+	// metric with the literal "U" (undetermined) value.
+	ErrPerformanceDataValueUndetermined = errors.New("performance data value undetermined")
+
+	// ErrPerformanceDataPrometheusIncompatible indicates that a performance
+	// data label cannot be converted to a valid Prometheus metric name.
+	ErrPerformanceDataPrometheusIncompatible = errors.New("performance data label incompatible with Prometheus metric naming rules")
+
+	// ErrPerformanceDataPercentOutOfRange indicates that the value of a
+	// performance data metric using the percentage Unit of Measurement is
+	// outside of the permitted range.
+	ErrPerformanceDataPercentOutOfRange = errors.New("performance data percentage value out of range")
+
+	// ErrPerformanceDataInvalidUTF8 indicates that a performance data label
+	// is not valid UTF-8.
+	ErrPerformanceDataInvalidUTF8 = errors.New("performance data label is not valid UTF-8")
+
+	// ErrPerformanceDataUndeterminedWithThresholds indicates that a
+	// performance data metric with the literal "U" (undetermined) value
+	// specifies Warn or Crit thresholds which can never be evaluated.
+	ErrPerformanceDataUndeterminedWithThresholds = errors.New("performance data thresholds set for undetermined value")
+
+	// ErrInvalidPluginOutput indicates that plugin output does not follow
+	// the format described by the Nagios Plugin API.
+	ErrInvalidPluginOutput = errors.New("invalid plugin output format")
+
+	// ErrServicePerfDataFileTemplateMismatch indicates that a line of a
+	// Nagios service performance data file does not match the given
+	// template or that the template is unusable.
+	ErrServicePerfDataFileTemplateMismatch = errors.New("service performance data file line does not match template")
+
+	// ErrPerformanceDataTooManyMetrics indicates that a performance data
+	// string contains more metrics than permitted by the MaxMetricsPerLine
+	// parsing option.
+	ErrPerformanceDataTooManyMetrics = errors.New("too many performance data metrics")
+
+	// ErrInvalidRangeThreshold indicates that the values given for a
+	// threshold range cannot be represented in the Nagios range format
+	// (e.g., a start value greater than the end value).
+	ErrInvalidRangeThreshold = errors.New("invalid range threshold")
+
 	// TODO: Should we use field-specific errors or is the more general
 	// ErrInvalidPerformanceDataFormat "good enough" ? Wrapped versions of
 	// that error will likely already indicate which field is a problem, but
@@ -8,9 +8,19 @@
 package nagios
 
 import (
+	"bufio"
+	"bytes"
+	"encoding/json"
 	"fmt"
+	"hash/fnv"
+	"io"
+	"math"
+	"net/url"
 	"regexp"
+	"strconv"
 	"strings"
+	"unicode"
+	"unicode/utf8"
 )
 
 const (
@@ -30,11 +40,13 @@ const (
 	// validate the Value field. In addition to the characters used to
 	// represent whole and fractional numbers a literal U character is also
 	// permitted (indicates that the actual value could not be determined).
-	perfDataValueFieldRegex string = `[-0-9.]+|U`
+	// An optional leading plus sign is also permitted.
+	perfDataValueFieldRegex string = `[-+]?[-0-9.]+|U`
 
 	// perfDataMinMaxFieldsRegex represents the regex character class
-	// used to validate the Min and Max fields.
-	perfDataMinMaxFieldsRegex string = `[-0-9.]+`
+	// used to validate the Min and Max fields. An optional leading plus sign
+	// is permitted.
+	perfDataMinMaxFieldsRegex string = `[-+]?[-0-9.]+`
 
 	// perfDataThresholdRangeSyntaxRegex represents the regex character class
 	// used to validate and parse the Warn and Crit fields.
@@ -66,6 +78,10 @@ const (
 		// range from -∞ to +∞
 		`|(?:^~:$)`
 
+	// perfDataThresholdRangeCharacters are the only characters permitted in
+	// the Warn and Crit fields.
+	perfDataThresholdRangeCharacters string = "@~:-.0123456789"
+
 	// perfDataLabelFieldDisallowedCharacters are the characters disallowed in
 	// the Label field; the equals sign and single quote characters are not
 	// allowed.
@@ -86,8 +102,19 @@ const (
 
 	// perfDataValueAndUoMFieldsRegex is used to build capture groups for
 	// "Value" and "UoM". The "Value" capture group is a required match
-	// whereas the "UoM" capture group is optional.
-	perfDataValueAndUoMFieldsRegex string = `(?P<Value>[-0-9.]+)(?P<UoM>[^\d;'"]*)?`
+	// whereas the "UoM" capture group is optional. The "Value" capture group
+	// permits an optional leading plus or minus sign (e.g., "+5", "-5").
+	perfDataValueAndUoMFieldsRegex string = `^(?P<Value>[-+]?[-0-9.]+)(?P<UoM>[^\d;'"]*)?$`
+
+	// perfDataUoMAndValueFieldsRegex is used to build capture groups for a
+	// nonstandard "UoM" followed by "Value" form (e.g., "C20") when the
+	// AllowLeadingUoM parsing option is enabled. Both capture groups are
+	// required.
+	perfDataUoMAndValueFieldsRegex string = `^(?P<UoM>[^\d;'"+\-.]+)(?P<Value>[-+]?[-0-9.]+)$`
+
+	// perfDataNumericCharacters are the characters permitted in the numeric
+	// portion of the Value field.
+	perfDataNumericCharacters string = "+-0123456789."
 
 	// perfDataUnitOfMeasurementRegex represents the regex negated character
 	// class used to validate the UnitOfMeasurement field.
@@ -96,6 +123,16 @@ const (
 	// perfDataUnitOfMeasurementRegex string = `[^0-9;"']+`
 )
 
+// Compiled forms of the performance data regular expressions. These are
+// compiled once at package initialization instead of on each use.
+var (
+	perfDataValueFieldRe           = regexp.MustCompile(perfDataValueFieldRegex)
+	perfDataMinMaxFieldsRe         = regexp.MustCompile(perfDataMinMaxFieldsRegex)
+	perfDataThresholdRangeSyntaxRe = regexp.MustCompile(perfDataThresholdRangeSyntaxRegex)
+	perfDataValueAndUoMFieldsRe    = regexp.MustCompile(perfDataValueAndUoMFieldsRegex)
+	perfDataUoMAndValueFieldsRe    = regexp.MustCompile(perfDataUoMAndValueFieldsRegex)
+)
+
 // PerformanceData represents the performance data generated by a Nagios
 // plugin.
 //
@@ -108,8 +145,9 @@ type PerformanceData struct {
 
 	// Label is the text string used as a label for a specific performance
 	// data point. The label length is arbitrary, but ideally the first 19
-	// characters are unique due to a limitation in RRD. There is also a
-	// limitation in the amount of data that NRPE returns to Nagios. When
+	// characters are unique due to a limitation in RRD (see
+	// CheckRRDLabelCollisions). There is also a limitation in the amount of
+	// data that NRPE returns to Nagios. When
 	// emitted by a Nagios plugin, single quotes are required if spaces are in
 	// the label.
 	//
@@ -165,6 +203,305 @@ type PerformanceData struct {
 	// Max is in class [-0-9.] and must be the same UOM as Value and Min. Max
 	// is not required if UOM=%. An empty string is permitted.
 	Max string
+
+	// Values is the collection of values parsed from a nonstandard "value
+	// list" metric (e.g., "temp=20,21,22C") when the AllowValueList parsing
+	// option is enabled. The first value is also recorded in the Value
+	// field. Values is nil unless multiple values were parsed and is not
+	// included in output generated by the String method.
+	Values []string
+
+	// raw is the original metric string that this value was parsed from.
+	// This is retained for debugging purposes and is empty for manually
+	// constructed values.
+	raw string
+
+	// present records which optional fields were present (possibly empty) in
+	// the original metric string. This is only set for parsed values.
+	present perfDataFieldMask
+
+	// annotations are optional notes attached to the metric by client code
+	// (see WithAnnotation). Annotations are never included in plugin output
+	// but are included in JSON output.
+	annotations map[string]string
+}
+
+// perfDataFieldMask is a bitmask used to record the presence of optional
+// performance data metric fields.
+type perfDataFieldMask uint8
+
+const (
+	perfDataFieldWarn perfDataFieldMask = 1 << iota
+	perfDataFieldCrit
+	perfDataFieldMin
+	perfDataFieldMax
+)
+
+// PerfDataParseOptions controls optional parsing behavior used when
+// processing performance data strings. The zero value provides the default
+// (strict) behavior used by ParsePerfData.
+//
+// Options which relax parsing rules are intended for interoperability with
+// misbehaving plugins and deviate from the [Nagios Plugin Dev Guidelines].
+//
+// [Nagios Plugin Dev Guidelines]: https://nagios-plugins.org/doc/guidelines.html#AEN200
+type PerfDataParseOptions struct {
+	// AllowCommaDecimal indicates whether a single comma in the numeric
+	// portion of the Value field is accepted as a decimal separator (e.g.,
+	// "temp=23,5C" as emitted by a plugin using a misconfigured locale). If
+	// enabled, the comma is replaced with a period prior to validation.
+	// Input containing multiple commas (or a comma and a period) is still
+	// rejected.
+	AllowCommaDecimal bool
+
+	// StrictUoM indicates whether the UnitOfMeasurement field is required to
+	// be one of the values in KnownUnitsOfMeasurement (if not empty). Icinga
+	// 2 discards unknown units of measurement; this option allows catching
+	// them early instead.
+	StrictUoM bool
+
+	// UndeterminedValueTokens is an optional collection of Value field
+	// tokens (e.g., "N/A", "-") which indicate that the actual value could
+	// not be determined. Values matching one of these tokens are normalized
+	// to the literal "U" value during parsing. The literal "U" value is
+	// always accepted.
+	UndeterminedValueTokens []string
+
+	// AllowValueList indicates whether multiple comma separated values for a
+	// single label (e.g., "temp=20,21,22C") are accepted. If enabled, the
+	// values are recorded in the Values field with the first value also
+	// recorded in the Value field for backwards compatibility. A Unit of
+	// Measurement (if any) is expected after the last value. This option
+	// takes precedence over AllowCommaDecimal for values containing commas.
+	AllowValueList bool
+
+	// CanonicalizeUoM indicates whether case variants of known units of
+	// measurement (e.g., "kb", "MS") are converted to their canonical form
+	// (e.g., "KB", "ms") during parsing. See CanonicalizeUoM for details.
+	CanonicalizeUoM bool
+
+	// TrimTrailingGarbage indicates whether trailing characters which are
+	// not part of the performance data (see
+	// PerfDataTrailingGarbageCharacters) are removed prior to splitting the
+	// input into individual metrics. This is intended for output from
+	// plugins which emit stray trailing characters (e.g., a Windows CRLF line
+	// ending after a double quoted performance data string or a trailing
+	// period). A trailing period following a digit is retained as it may be
+	// part of a numeric field (e.g., "100.").
+	TrimTrailingGarbage bool
+
+	// NormalizeLabelCase indicates whether labels are converted to lowercase
+	// during parsing (e.g., "CPU" and "cpu" are both stored as "cpu"). This
+	// is intended for use with case-insensitive downstream storage.
+	NormalizeLabelCase bool
+
+	// StripPositiveSign indicates whether an explicit leading plus sign
+	// (e.g., "delta=+5") is removed from the Value, Min and Max fields during
+	// parsing. By default the sign is preserved as emitted by the plugin.
+	StripPositiveSign bool
+
+	// AllowLeadingUoM indicates whether a Unit of Measurement preceding the
+	// numeric value (e.g., "temp=C20" as emitted by some legacy plugins) is
+	// accepted if the value does not match the standard value first format.
+	// The results are stored in the Value and UnitOfMeasurement fields as
+	// usual.
+	AllowLeadingUoM bool
+
+	// RejectDuplicateLabels indicates whether multiple metrics sharing the
+	// same label (after any label normalization) are rejected. If enabled,
+	// an error wrapping ErrPerformanceDataDuplicateLabel which identifies
+	// the positions of both metrics is returned.
+	RejectDuplicateLabels bool
+
+	// AllowInfinityBounds indicates whether infinity tokens ("inf", "+inf",
+	// "-inf", "infinity", "+infinity" or "-infinity", case-insensitive) are
+	// accepted in the Min and Max fields to indicate an unbounded minimum or
+	// maximum. Accepted tokens are normalized to PerfDataInfinityToken or
+	// PerfDataNegativeInfinityToken. See also the MinAsFloat and MaxAsFloat
+	// methods.
+	AllowInfinityBounds bool
+
+	// AllowValuelessMetrics indicates whether a metric consisting of a bare
+	// label without an equals sign (e.g., "load1") is accepted. If enabled,
+	// the metric is parsed with the given label and the literal "U"
+	// (undetermined) Value. A metric with an equals sign but no value (e.g.,
+	// "load1=") is rejected regardless of this option.
+	AllowValuelessMetrics bool
+
+	// AllowNaN indicates whether the "NaN" (not a number) token
+	// (case-insensitive) is accepted as a Value, such as emitted by plugins
+	// computing a ratio when dividing by zero. Nagios has no native
+	// representation of NaN; the value could not be determined, so the
+	// token is mapped to the literal "U" (undetermined) value.
+	AllowNaN bool
+
+	// StrictPercentage indicates whether values of metrics using the "%"
+	// Unit of Measurement are required to be within the range 0 to 100, or
+	// within the range given by the Min and Max fields if specified. If
+	// enabled, an error wrapping ErrPerformanceDataPercentOutOfRange is
+	// returned for values (e.g., "-5%" or "150%") outside of that range. The
+	// literal "U" (undetermined) value is not evaluated.
+	//
+	// This is disabled by default as some plugins legitimately emit
+	// percentages above 100 (e.g., CPU usage summed across multiple cores).
+	StrictPercentage bool
+
+	// MetricSeparators is an optional set of characters used to separate
+	// individual metrics in the raw performance data string. If empty (the
+	// default), metrics are separated by any whitespace (as with
+	// strings.Fields). Setting this to "\n" (for example) splits only on
+	// newlines, preserving other whitespace (e.g., tabs or spaces in quoted
+	// labels) within each metric. Leading and trailing whitespace is
+	// removed from each metric and empty metrics are skipped.
+	MetricSeparators string
+
+	// EuropeanNumberFormat indicates whether the numeric portion of the
+	// Value field is accepted using periods as thousands separators and a
+	// comma as the decimal separator (e.g., "1.234,56" as emitted by a
+	// plugin using a German locale). If enabled, thousands separators are
+	// removed and the comma is replaced with a period prior to validation
+	// (e.g., "1.234,56" becomes "1234.56").
+	//
+	// Ambiguous or malformed input is rejected: multiple commas (e.g.,
+	// "1,2,3"), periods following the comma, thousands groups not of three
+	// digits (e.g., "1.23,5") and a single period which could be either a
+	// decimal or thousands separator (e.g., "1.234"). Values using a period
+	// as a decimal separator which cannot be a thousands separator (e.g.,
+	// "0.260" or "12.5") are accepted unmodified. The AllowValueList option
+	// takes precedence for values containing commas.
+	EuropeanNumberFormat bool
+
+	// RequireUTF8Labels indicates whether labels are required to be valid
+	// UTF-8. Labels may otherwise contain arbitrary bytes (only the equals
+	// sign and single quote characters are disallowed), but invalid UTF-8
+	// can corrupt output produced by downstream consumers such as JSON or
+	// Prometheus exporters. If enabled, an error wrapping
+	// ErrPerformanceDataInvalidUTF8 is returned for such labels.
+	RequireUTF8Labels bool
+
+	// RejectUndeterminedWithThresholds indicates whether metrics with the
+	// literal "U" (undetermined) value and a Warn or Crit threshold are
+	// rejected. Such thresholds can never be evaluated, which usually
+	// indicates a plugin which did not clear its thresholds after failing to
+	// obtain a measurement. If enabled, an error wrapping
+	// ErrPerformanceDataUndeterminedWithThresholds is returned.
+	RejectUndeterminedWithThresholds bool
+
+	// StripThresholdUnits indicates whether a trailing Unit of Measurement
+	// matching that of the metric is removed from each endpoint of the Warn
+	// and Crit thresholds (e.g., "80%" becomes "80" and "10%:80%" becomes
+	// "10:80" for a metric using the "%" unit). Thresholds using any other
+	// unit are still rejected. By default thresholds including a unit are
+	// rejected.
+	StripThresholdUnits bool
+
+	// DecodePercentEncodedLabels indicates whether labels are decoded from
+	// percent-encoding (e.g., "cpu%20load" becomes "cpu load") as used by
+	// some transport layers to carry spaces or special characters. The
+	// decoded label is validated in the same manner as any other label
+	// (e.g., a decoded equals sign is rejected). A plus sign is not decoded
+	// to a space. An error is returned if a label contains a malformed
+	// escape sequence (e.g., "cpu%2").
+	DecodePercentEncodedLabels bool
+
+	// IcingaCompatUoM indicates whether a UnitOfMeasurement field value
+	// which is not one of the KnownUnitsOfMeasurement values is discarded
+	// (as if not specified) during parsing. This matches the documented
+	// behavior of Icinga 2 for unknown units of measurement. If enabled, the
+	// StrictUoM option has no effect on parsing as unknown units of
+	// measurement are discarded before they are validated.
+	IcingaCompatUoM bool
+
+	// StripZeroWidthPrefix indicates whether invisible zero width characters
+	// (see PerfDataZeroWidthCharacters) are removed from the start of the
+	// input prior to splitting it into individual metrics. A leading UTF-8
+	// byte order mark is always removed; this option extends that behavior
+	// to other invisible characters which would otherwise become part of the
+	// first label.
+	StripZeroWidthPrefix bool
+
+	// MaxMetricsPerLine is the maximum number of metrics permitted in the
+	// input. Metrics are counted (stopping once the limit is exceeded)
+	// before the input is split into individual metrics; if the limit is
+	// exceeded an error is returned without splitting or parsing the input.
+	// This is intended to guard against pathological input (e.g., from
+	// untrusted plugin output) consuming excessive resources. Zero (the
+	// default) or a negative value indicates no limit.
+	MaxMetricsPerLine int
+
+	// AllowSemicolonInQuotedLabel indicates whether semicolons within a
+	// single quoted label (e.g., "'rx;tx'=10") are treated as part of the
+	// label instead of as field separators. Labels containing whitespace in
+	// addition to a semicolon also require the MetricSeparators option.
+	AllowSemicolonInQuotedLabel bool
+
+	// warnings collects descriptions of lenient coercions applied during
+	// parsing; nil unless parsing via ParsePerfDataWithWarnings.
+	warnings *perfDataParseWarnings
+}
+
+// perfDataParseWarnings collects descriptions of lenient coercions applied
+// while parsing performance data along with the (0-based) index of the
+// metric currently being parsed.
+type perfDataParseWarnings struct {
+	metric   int
+	messages []string
+}
+
+// warnf records a description of a lenient coercion applied to the metric
+// currently being parsed. This is a no-op unless parsing via
+// ParsePerfDataWithWarnings.
+func (opts PerfDataParseOptions) warnf(format string, a ...interface{}) {
+	if opts.warnings == nil {
+		return
+	}
+
+	opts.warnings.messages = append(
+		opts.warnings.messages,
+		fmt.Sprintf("metric %d: ", opts.warnings.metric)+fmt.Sprintf(format, a...),
+	)
+}
+
+// Canonical tokens used to represent infinity in the Min and Max fields when
+// the AllowInfinityBounds parsing option is enabled.
+const (
+	PerfDataInfinityToken         string = "inf"
+	PerfDataNegativeInfinityToken string = "-inf"
+)
+
+// PerfDataTrailingGarbageCharacters is the set of characters removed from
+// the end of a raw performance data string when the TrimTrailingGarbage
+// parsing option is enabled: carriage return, newline, tab, space and
+// period. A period is only removed if it does not follow a digit.
+const PerfDataTrailingGarbageCharacters string = "\r\n\t ."
+
+// PerfDataZeroWidthCharacters is the set of invisible characters removed from
+// the start of a raw performance data string when the StripZeroWidthPrefix
+// parsing option is enabled: zero width space, zero width non-joiner, zero
+// width joiner, word joiner and zero width no-break space (byte order mark).
+const PerfDataZeroWidthCharacters string = "\u200B\u200C\u200D\u2060\uFEFF"
+
+// perfDataByteOrderMark is the UTF-8 byte order mark which is always removed
+// from the start of a raw performance data string.
+const perfDataByteOrderMark string = "\uFEFF"
+
+// KnownUnitsOfMeasurement is the collection of units of measurement
+// documented by the [Nagios Plugin Dev Guidelines]. This collection is used
+// when the StrictUoM or IcingaCompatUoM parsing options are enabled and may
+// be extended by client code to permit additional units.
+//
+// [Nagios Plugin Dev Guidelines]: https://nagios-plugins.org/doc/guidelines.html#AEN200
+var KnownUnitsOfMeasurement = []string{
+	"s",
+	"us",
+	"ms",
+	"%",
+	"B",
+	"KB",
+	"MB",
+	"GB",
+	"TB",
+	"c",
 }
 
 // ParsePerfData parses a raw performance data string into a collection of
@@ -176,21 +513,37 @@ type PerformanceData struct {
 // spaces). Some fields are also optional. See the [Nagios Plugin Dev
 // Guidelines] for additional details.
 //
+// A leading UTF-8 byte order mark (as emitted by some Windows tools) is
+// removed prior to parsing.
+//
 // [Nagios Plugin Dev Guidelines]: https://nagios-plugins.org/doc/guidelines.html#AEN200
 func ParsePerfData(rawPerfdata string) ([]PerformanceData, error) {
+	return ParsePerfDataWithOptions(rawPerfdata, PerfDataParseOptions{})
+}
 
-	if strings.TrimSpace(rawPerfdata) == "" {
-		return nil, fmt.Errorf(
-			"missing input performance data string: %w",
-			ErrInvalidPerformanceDataFormat,
-		)
+// ParsePerfDataWithOptions parses a raw performance data string into a
+// collection of PerformanceData values using the given parsing options. See
+// ParsePerfData for details regarding the expected input format.
+func ParsePerfDataWithOptions(rawPerfdata string, opts PerfDataParseOptions) ([]PerformanceData, error) {
+
+	var trailingGarbage string
+	if opts.TrimTrailingGarbage {
+		trimmed := trimPerfDataTrailingGarbage(rawPerfdata)
+		trailingGarbage = rawPerfdata[len(trimmed):]
+		rawPerfdata = trimmed
 	}
 
-	// Remove any double quotes if present.
-	rawPerfdata = strings.Trim(rawPerfdata, `"`)
+	if opts.StripZeroWidthPrefix {
+		if trimmed := strings.TrimLeft(rawPerfdata, PerfDataZeroWidthCharacters); trimmed != rawPerfdata {
+			opts.warnf("removed leading zero width characters %+q", rawPerfdata[:len(rawPerfdata)-len(trimmed)])
+			rawPerfdata = trimmed
+		}
+	}
 
-	// DEBUG
-	// fmt.Printf("rawPerfdata without double quotes: %s\n", rawPerfdata)
+	rawPerfdata, err := preparePerfDataInput(rawPerfdata)
+	if err != nil {
+		return nil, err
+	}
 
 	// Split raw perfdata string into individual metrics using whitespace
 	// separators.
@@ -208,34 +561,391 @@ func ParsePerfData(rawPerfdata string) ([]PerformanceData, error) {
 	//
 	// If we are working with a single metric we get back that one metric, so
 	// we're working from at least a slice of one element.
-	perfdataStrings := strings.Fields(rawPerfdata)
+	//
+	// Any limit on the number of metrics is enforced first so that
+	// pathological input is rejected without splitting it.
+	if opts.MaxMetricsPerLine > 0 &&
+		countPerfDataMetrics(rawPerfdata, opts, opts.MaxMetricsPerLine) > opts.MaxMetricsPerLine {
+		return nil, fmt.Errorf(
+			"found more than %d metrics: %w",
+			opts.MaxMetricsPerLine,
+			ErrPerformanceDataTooManyMetrics,
+		)
+	}
+
+	perfdataStrings := splitPerfDataMetrics(rawPerfdata, opts)
 
 	// DEBUG
 	// fmt.Printf("space separated fields from rawPerfdata: %q\n", perfdataStrings)
 
 	results := make([]PerformanceData, 0, len(perfdataStrings))
 
-	for _, perfdataString := range perfdataStrings {
-		perfdata, err := parsePerfData(perfdataString)
+	var labelPositions map[string]int
+	if opts.RejectDuplicateLabels {
+		labelPositions = make(map[string]int, len(perfdataStrings))
+	}
+
+	for i, perfdataString := range perfdataStrings {
+		if opts.warnings != nil {
+			opts.warnings.metric = i
+		}
+
+		perfdata, err := parsePerfData(perfdataString, opts)
 		if err != nil {
 			return nil, err
 		}
+
+		if i == len(perfdataStrings)-1 && trailingGarbage != "" {
+			opts.warnf("removed trailing characters %q", trailingGarbage)
+		}
+
+		if opts.RejectDuplicateLabels {
+			if firstPos, exists := labelPositions[perfdata.Label]; exists {
+				return nil, fmt.Errorf(
+					"label %q of metric %d previously used by metric %d: %w",
+					perfdata.Label,
+					i,
+					firstPos,
+					ErrPerformanceDataDuplicateLabel,
+				)
+			}
+			labelPositions[perfdata.Label] = i
+		}
+
 		results = append(results, perfdata)
 	}
 
 	return results, nil
 }
 
+// ParsePerfDataWithWarnings parses a raw performance data string using the
+// given parsing options in the same way as ParsePerfDataWithOptions and also
+// returns a description of each lenient coercion applied (e.g., a comma
+// decimal separator converted via AllowCommaDecimal or a "NaN" value mapped
+// via AllowNaN). Each warning is prefixed with the (0-based) index of the
+// affected metric. This provides visibility into input which was accepted
+// but is not standard.
+//
+// A nil warnings collection is returned if no lenient coercions were
+// applied. If parsing fails, the error is returned with any warnings
+// collected prior to the failure.
+func ParsePerfDataWithWarnings(rawPerfdata string, opts PerfDataParseOptions) ([]PerformanceData, []string, error) {
+	opts.warnings = &perfDataParseWarnings{}
+
+	perfData, err := ParsePerfDataWithOptions(rawPerfdata, opts)
+
+	return perfData, opts.warnings.messages, err
+}
+
+// ParseSinglePerfData parses a raw performance data string containing exactly
+// one metric into a PerformanceData value. An error is returned if the input
+// is empty, contains more than one (whitespace separated) metric or if
+// parsing fails. See ParsePerfData for details regarding the expected input
+// format.
+func ParseSinglePerfData(rawPerfdata string) (PerformanceData, error) {
+	rawPerfdata, err := preparePerfDataInput(rawPerfdata)
+	if err != nil {
+		return PerformanceData{}, err
+	}
+
+	perfdataStrings := strings.Fields(rawPerfdata)
+	if len(perfdataStrings) != 1 {
+		return PerformanceData{}, fmt.Errorf(
+			"input contains %d metrics; expected exactly one: %w",
+			len(perfdataStrings),
+			ErrInvalidPerformanceDataFormat,
+		)
+	}
+
+	return parsePerfData(perfdataStrings[0], PerfDataParseOptions{})
+}
+
+// ParsePerfDataSeq parses a raw performance data string one metric at a time,
+// calling yield for each parsed PerformanceData value (or parsing error).
+// Iteration stops early if yield returns false. This allows callers to
+// process very large performance data strings without first collecting all
+// parsed metrics in memory.
+//
+// If parsing a metric fails the error is passed to yield along with a zero
+// value PerformanceData; it is up to the caller whether to continue
+// iteration. See ParsePerfData for details regarding the expected input
+// format.
+//
+// The function signature is compatible with the Go 1.23 range-over-func
+// iterator protocol (iter.Seq2) when wrapped in a closure.
+func ParsePerfDataSeq(rawPerfdata string, yield func(PerformanceData, error) bool) {
+	rawPerfdata, err := preparePerfDataInput(rawPerfdata)
+	if err != nil {
+		yield(PerformanceData{}, err)
+		return
+	}
+
+	// Lazily split the raw perfdata string into individual metrics using
+	// whitespace separators (equivalent to strings.Fields) instead of
+	// building the full collection up front.
+	remaining := rawPerfdata
+	for {
+		var perfdataString string
+		perfdataString, remaining = nextPerfDataField(remaining)
+		if perfdataString == "" {
+			return
+		}
+
+		if !yield(parsePerfData(perfdataString, PerfDataParseOptions{})) {
+			return
+		}
+	}
+}
+
+// ParsePerfDataReader reads performance data strings from r, one per line,
+// and parses each using ParsePerfData. Blank lines and lines beginning with a
+// "#" character (comments) are skipped. The parsed results for each valid
+// line are returned in order.
+//
+// Parsing continues after a malformed line; if any lines fail to parse, the
+// results for all valid lines are returned along with an error aggregating
+// the parsing failures annotated with their (1-based) line numbers.
+func ParsePerfDataReader(r io.Reader) ([][]PerformanceData, error) {
+	var results [][]PerformanceData
+	var errs []error
+
+	scanner := bufio.NewScanner(r)
+
+	var lineNum int
+	for scanner.Scan() {
+		lineNum++
+
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		perfData, err := ParsePerfData(line)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("line %d: %w", lineNum, err))
+			continue
+		}
+
+		results = append(results, perfData)
+	}
+
+	if err := scanner.Err(); err != nil {
+		errs = append(errs, fmt.Errorf("failed to read input after line %d: %w", lineNum, err))
+	}
+
+	return results, joinErrors(errs...)
+}
+
+// PerfDataLabels extracts only the labels of the metrics in a raw
+// performance data string, in order. This is faster than parsing the full
+// performance data string via ParsePerfData when only labels are needed.
+// Labels are validated, but all other fields (including the Value) are
+// ignored. An error is returned if the input is empty or if a label cannot
+// be extracted.
+func PerfDataLabels(rawPerfdata string) ([]string, error) {
+	rawPerfdata, err := preparePerfDataInput(rawPerfdata)
+	if err != nil {
+		return nil, err
+	}
+
+	var labels []string
+	remaining := rawPerfdata
+	for {
+		var perfdataString string
+		perfdataString, remaining = nextPerfDataField(remaining)
+		if perfdataString == "" {
+			return labels, nil
+		}
+
+		// The label and value precede the first semicolon (if present).
+		labelAndRawValue, _, _ := strings.Cut(perfdataString, ";")
+
+		label, _, err := splitLabelAndRawValue(labelAndRawValue)
+		if err != nil {
+			return nil, fmt.Errorf("failed to extract label: %w", err)
+		}
+
+		labels = append(labels, label)
+	}
+}
+
+// splitPerfDataMetrics splits a raw performance data string into individual
+// metric strings using the MetricSeparators parsing option, or any
+// whitespace if not set.
+func splitPerfDataMetrics(rawPerfdata string, opts PerfDataParseOptions) []string {
+	if opts.MetricSeparators == "" {
+		return strings.Fields(rawPerfdata)
+	}
+
+	fields := strings.FieldsFunc(rawPerfdata, func(r rune) bool {
+		return strings.ContainsRune(opts.MetricSeparators, r)
+	})
+
+	metrics := make([]string, 0, len(fields))
+	for _, field := range fields {
+		if field = strings.TrimSpace(field); field != "" {
+			metrics = append(metrics, field)
+		}
+	}
+
+	return metrics
+}
+
+// trimPerfDataTrailingGarbage removes trailing characters listed in
+// PerfDataTrailingGarbageCharacters from the given raw performance data
+// string. Trailing periods following a digit are retained as they may be
+// part of a numeric field (e.g., "100.").
+func trimPerfDataTrailingGarbage(rawPerfdata string) string {
+	for {
+		trimmed := strings.TrimRight(rawPerfdata, "\r\n\t ")
+		withoutPeriods := strings.TrimRight(trimmed, ".")
+
+		if withoutPeriods == trimmed {
+			return trimmed
+		}
+
+		if last := len(withoutPeriods) - 1; last >= 0 &&
+			withoutPeriods[last] >= '0' && withoutPeriods[last] <= '9' {
+			return trimmed
+		}
+
+		rawPerfdata = withoutPeriods
+	}
+}
+
+// countPerfDataMetrics returns the number of metric strings that
+// splitPerfDataMetrics would return for the given raw performance data
+// string. Counting stops once limit is exceeded so that the cost of
+// rejecting pathological input does not depend on the size of the input
+// beyond that point.
+func countPerfDataMetrics(rawPerfdata string, opts PerfDataParseOptions, limit int) int {
+	isSeparator := unicode.IsSpace
+	if opts.MetricSeparators != "" {
+		isSeparator = func(r rune) bool {
+			return strings.ContainsRune(opts.MetricSeparators, r)
+		}
+	}
+
+	var count int
+	var counted bool
+
+	for _, r := range rawPerfdata {
+		switch {
+		case isSeparator(r):
+			counted = false
+
+		// Metrics consisting only of whitespace are discarded.
+		case !counted && !unicode.IsSpace(r):
+			counted = true
+			count++
+
+			if count > limit {
+				return count
+			}
+		}
+	}
+
+	return count
+}
+
+// preparePerfDataInput performs common preprocessing of a raw performance
+// data string prior to splitting it into individual metrics. An error is
+// returned if the input string is empty (or contains only double quotes and
+// whitespace).
+func preparePerfDataInput(rawPerfdata string) (string, error) {
+	// Remove a byte order mark (e.g., from output captured from Windows
+	// tools) which would otherwise become part of the first label.
+	rawPerfdata = strings.TrimPrefix(rawPerfdata, perfDataByteOrderMark)
+
+	// Remove any double quotes if present.
+	rawPerfdata = strings.Trim(rawPerfdata, `"`)
+
+	if strings.TrimSpace(rawPerfdata) == "" {
+		return "", fmt.Errorf(
+			"missing input performance data string: %w",
+			ErrInvalidPerformanceDataFormat,
+		)
+	}
+
+	// DEBUG
+	// fmt.Printf("rawPerfdata without double quotes: %s\n", rawPerfdata)
+
+	return rawPerfdata, nil
+}
+
+// nextPerfDataField returns the next whitespace separated field from the
+// given input string along with the remaining unprocessed input. An empty
+// field is returned once the input is exhausted.
+func nextPerfDataField(input string) (string, string) {
+	start := strings.IndexFunc(input, func(r rune) bool { return !unicode.IsSpace(r) })
+	if start < 0 {
+		return "", ""
+	}
+	input = input[start:]
+
+	end := strings.IndexFunc(input, unicode.IsSpace)
+	if end < 0 {
+		return input, ""
+	}
+
+	return input[:end], input[end:]
+}
+
+// AsValueUndetermined returns a copy of the PerformanceData value with the
+// Value field set to the literal "U" value, indicating that the actual value
+// could not be determined. This is the preferred way to emit a metric whose
+// value is unavailable.
+func (pd PerformanceData) AsValueUndetermined() PerformanceData {
+	undetermined := pd.Clone()
+	undetermined.Value = "U"
+	undetermined.Values = nil
+
+	return undetermined
+}
+
+// IsValueUndetermined reports whether the Value field is set to the literal
+// "U" value, indicating that the actual value could not be determined.
+func (pd PerformanceData) IsValueUndetermined() bool {
+	return strings.TrimSpace(pd.Value) == "U"
+}
+
+// IsZeroValue reports whether the Value field parses as exactly zero (e.g.,
+// "0", "0.0" or "-0"). The literal "U" (undetermined) value is not
+// considered zero; false is returned for it and for any other non-numeric
+// or non-zero value.
+func (pd PerformanceData) IsZeroValue() bool {
+	if pd.IsValueUndetermined() {
+		return false
+	}
+
+	value, err := strconv.ParseFloat(strings.TrimSpace(pd.Value), 64)
+
+	return err == nil && value == 0
+}
+
 // Validate performs basic validation of PerformanceData fields using logic
 // specified in the [Nagios Plugin Dev Guidelines]. An error is returned for
 // any validation failures.
 //
+// An error wrapping ErrPerformanceDataMissingValue is returned if the Value
+// field is empty. If the actual value could not be determined the Value
+// field should be set to the literal "U" value instead (see
+// AsValueUndetermined).
+//
 // [Nagios Plugin Dev Guidelines]: https://nagios-plugins.org/doc/guidelines.html#AEN200
 func (pd PerformanceData) Validate() error {
 	if err := validatePerfDataLabelField(pd.Label); err != nil {
 		return err
 	}
 
+	if strings.TrimSpace(pd.Value) == "" {
+		return fmt.Errorf(
+			"field Value for metric %q is empty: %w",
+			pd.Label,
+			ErrPerformanceDataMissingValue,
+		)
+	}
+
 	if err := validatePerfDataValueField(pd.Value); err != nil {
 		return err
 	}
@@ -259,94 +969,1449 @@ func (pd PerformanceData) Validate() error {
 	return validatePerfDataMaxField(pd.Max)
 }
 
-// String provides a PerformanceData metric in format ready for use in plugin
-// output.
-func (pd PerformanceData) String() string {
-	return fmt.Sprintf(
-		// The expected format of a performance data metric:
-		//
-		// 'label'=value[UOM];[warn];[crit];[min];[max]
-		//
-		// References:
-		//
-		// https://nagios-plugins.org/doc/guidelines.html
-		// https://assets.nagios.com/downloads/nagioscore/docs/nagioscore/3/en/perfdata.html
-		// https://assets.nagios.com/downloads/nagioscore/docs/nagioscore/3/en/pluginapi.html
-		// https://www.monitoring-plugins.org/doc/guidelines.html
-		// https://icinga.com/docs/icinga-2/latest/doc/05-service-monitoring/#performance-data-metrics
-		" '%s'=%s%s;%s;%s;%s;%s",
-		pd.Label,
-		pd.Value,
-		pd.UnitOfMeasurement,
-		pd.Warn,
-		pd.Crit,
-		pd.Min,
-		pd.Max,
-	)
-}
+// ValidateAll performs the same validation as Validate, but checks every
+// field instead of returning on the first failure. All validation failures
+// are returned as a single error wrapping each failure (see errors.Is); nil
+// is returned if validation is successful. Use Validate if fast-fail behavior is
+// preferred.
+func (pd PerformanceData) ValidateAll() error {
+	var errs []error
 
-// parsePerfData parses an input string representing a performance data
-// emitted by a Nagios plugin metric such as "load1=0.260;5.000;10.000;0;" (no
-// quotes) into a PerformanceData value.
-func parsePerfData(perfdataString string) (PerformanceData, error) {
+	if err := validatePerfDataLabelField(pd.Label); err != nil {
+		errs = append(errs, err)
+	}
 
-	// Split based on semicolons.
-	//
-	// This turns an input string such as:
-	//
-	// load1=0.260;5.000;10.000;0;
-	//
-	// into:
-	//
-	// load1=0.260 (label of "load1", separator of "=", value of "0.260")
-	// 5.000 (Warn; warning threshold)
-	// 10.000 (Crit; critical threshold)
-	// 0 (Min)
-	//
-	// It is possible that no semicolons are provided, in which case it is
-	// assumed that we are working with a single performance data metric.
-	perfdataFields := strings.Split(perfdataString, ";")
+	switch {
+	case strings.TrimSpace(pd.Value) == "":
+		errs = append(errs, fmt.Errorf(
+			"field Value for metric %q is empty: %w",
+			pd.Label,
+			ErrPerformanceDataMissingValue,
+		))
+	default:
+		if err := validatePerfDataValueField(pd.Value); err != nil {
+			errs = append(errs, err)
+		}
+	}
 
-	// After splitting the input string on using a semicolon as separator
-	// there must be a minimum of one field (i.e., no semicolons present) and
-	// no more than the maximum/expected number based on the total fields of a
-	// performance data metric string.
-	switch numFields := len(perfdataFields); {
-	case numFields < perfDataMinSemicolonSeparatedFields:
-		return PerformanceData{}, fmt.Errorf(
-			"input appears to be empty; after processing %d fields found; expected minimum of %d: %w",
-			numFields,
-			perfDataMinSemicolonSeparatedFields,
-			ErrInvalidPerformanceDataFormat,
-		)
+	validators := []func() error{
+		func() error { return validatePerfDataUoMField(pd.UnitOfMeasurement) },
+		func() error { return validatePerfDataWarnField(pd.Warn) },
+		func() error { return validatePerfDataCritField(pd.Crit) },
+		func() error { return validatePerfDataMinField(pd.Min) },
+		func() error { return validatePerfDataMaxField(pd.Max) },
+	}
 
-	case numFields > perfDataMaxSemicolonSeparatedFields:
-		return PerformanceData{}, fmt.Errorf(
-			"input contains %d semicolon separated fields; expected no more than %d: %w",
-			numFields,
-			perfDataMaxSemicolonSeparatedFields,
-			ErrInvalidPerformanceDataFormat,
-		)
+	for _, validate := range validators {
+		if err := validate(); err != nil {
+			errs = append(errs, err)
+		}
 	}
 
-	// DEBUG
-	// fmt.Printf(
-	// 	"%d semicolon separated fields from perfdataString: %q\n",
-	// 	len(perfdataFields),
-	// 	perfdataFields,
-	// )
+	return joinErrors(errs...)
+}
 
-	label, rawValue, err := extractLabelAndRawValue(perfdataFields[0])
-	if err != nil {
-		return PerformanceData{}, fmt.Errorf("failed to extract label and raw value: %w", err)
+// FastValidate performs the same checks as Validate without the use of
+// regular expressions and is intended for hot paths where many metrics are
+// validated before being emitted. Numeric fields and thresholds are checked
+// using character scans equivalent to the regular expressions used by
+// Validate, so both accept and reject exactly the same metrics.
+//
+// As with Validate, the Value, Min and Max fields are accepted if they
+// contain any of the characters "-0123456789." (or, for the Value field, the
+// literal "U"); this includes exponent notation (e.g., "1e3").
+func (pd PerformanceData) FastValidate() error {
+	if err := validatePerfDataLabelField(pd.Label); err != nil {
+		return err
 	}
 
-	value, uom, err := extractValueAndUoM(rawValue)
-	if err != nil {
-		return PerformanceData{}, fmt.Errorf("failed to extract value and uom: %w", err)
+	value := strings.TrimSpace(pd.Value)
+
+	if value == "" {
+		return fmt.Errorf(
+			"field Value for metric %q is empty: %w",
+			pd.Label,
+			ErrPerformanceDataMissingValue,
+		)
 	}
 
-	rawWarn, rawCrit, rawMin, rawMax := extractRawWarnCritMinMaxRawFieldVals(perfdataFields)
+	if !containsPerfDataValue(value) {
+		return fmt.Errorf(
+			"field Value fails validation: %w",
+			ErrInvalidPerformanceDataFormat,
+		)
+	}
+
+	if err := validatePerfDataUoMField(pd.UnitOfMeasurement); err != nil {
+		return err
+	}
+
+	if err := fastValidatePerfDataThresholdField("Warn", pd.Warn); err != nil {
+		return err
+	}
+
+	if err := fastValidatePerfDataThresholdField("Crit", pd.Crit); err != nil {
+		return err
+	}
+
+	if err := fastValidatePerfDataBoundField("Min", pd.Min); err != nil {
+		return err
+	}
+
+	return fastValidatePerfDataBoundField("Max", pd.Max)
+}
+
+// fastValidatePerfDataThresholdField is the regular expression free
+// equivalent of validatePerfDataWarnField and validatePerfDataCritField.
+func fastValidatePerfDataThresholdField(field string, input string) error {
+	input = strings.TrimSpace(input)
+
+	if input == "" {
+		return nil
+	}
+
+	if err := validatePerfDataThresholdCharacters(input); err != nil {
+		return fmt.Errorf("field %s fails validation: %w", field, err)
+	}
+
+	if isPerfDataThresholdSyntax(input) {
+		return nil
+	}
+
+	return fmt.Errorf(
+		"field %s fails validation: %w",
+		field,
+		ErrInvalidPerformanceDataFormat,
+	)
+}
+
+// fastValidatePerfDataBoundField is the regular expression free equivalent
+// of validatePerfDataMinField and validatePerfDataMaxField.
+func fastValidatePerfDataBoundField(field string, input string) error {
+	input = strings.TrimSpace(input)
+
+	if input == "" {
+		return nil
+	}
+
+	if input == "U" {
+		return fmt.Errorf(
+			"field %s fails validation: %w",
+			field,
+			chainErrors(ErrPerformanceDataUnexpectedSentinel, ErrInvalidPerformanceDataFormat),
+		)
+	}
+
+	if containsPerfDataNumeric(input) || isPerfDataInfinityToken(input) {
+		return nil
+	}
+
+	return fmt.Errorf(
+		"field %s fails validation: %w",
+		field,
+		ErrInvalidPerformanceDataFormat,
+	)
+}
+
+// containsPerfDataNumeric reports whether the given input string contains
+// any of the characters "-0123456789." (equivalent to
+// perfDataMinMaxFieldsRegex, which is not anchored).
+func containsPerfDataNumeric(input string) bool {
+	return strings.ContainsAny(input, "-0123456789.")
+}
+
+// containsPerfDataValue reports whether the given Value field input string
+// contains any of the characters "-0123456789." or the literal "U"
+// (equivalent to perfDataValueFieldRegex, which is not anchored).
+func containsPerfDataValue(input string) bool {
+	return containsPerfDataNumeric(input) || strings.Contains(input, "U")
+}
+
+// isPerfDataThresholdSyntax reports whether the given input string is in the
+// range format (equivalent to perfDataThresholdRangeSyntaxRegex).
+func isPerfDataThresholdSyntax(input string) bool {
+	if input == "~:" {
+		return true
+	}
+
+	input = strings.TrimPrefix(input, "@")
+
+	if strings.HasPrefix(input, "~:") {
+		rest, ok := scanPerfDataThresholdNumber(input[len("~:"):])
+		return ok && rest == ""
+	}
+
+	rest, ok := scanPerfDataThresholdNumber(input)
+	switch {
+	case !ok:
+		return false
+	case rest == "":
+		return true
+	case rest[0] != ':':
+		return false
+	}
+
+	rest = rest[1:]
+	if rest == "" {
+		return true
+	}
+
+	rest, ok = scanPerfDataThresholdNumber(rest)
+
+	return ok && rest == ""
+}
+
+// scanPerfDataThresholdNumber consumes a number with an optional leading
+// minus sign and optional fractional part (e.g., "5", "-1.5") from the start
+// of the given input string and returns the remaining input. The ok result
+// is false if the input does not start with such a number.
+func scanPerfDataThresholdNumber(input string) (rest string, ok bool) {
+	input = strings.TrimPrefix(input, "-")
+
+	digits := func(s string) int {
+		var n int
+		for n < len(s) && s[n] >= '0' && s[n] <= '9' {
+			n++
+		}
+		return n
+	}
+
+	n := digits(input)
+	if n == 0 {
+		return input, false
+	}
+	input = input[n:]
+
+	if input == "" || input[0] != '.' {
+		return input, true
+	}
+
+	n = digits(input[1:])
+	if n == 0 {
+		return input, false
+	}
+
+	return input[1+n:], true
+}
+
+// MinAsFloat returns the Min field as a float64 value. Infinity tokens (see
+// the AllowInfinityBounds parsing option) are returned as math.Inf(1) or
+// math.Inf(-1). An error is returned if the field is empty or not numeric.
+func (pd PerformanceData) MinAsFloat() (float64, error) {
+	return perfDataBoundAsFloat("Min", pd.Label, pd.Min)
+}
+
+// MaxAsFloat returns the Max field as a float64 value. Infinity tokens (see
+// the AllowInfinityBounds parsing option) are returned as math.Inf(1) or
+// math.Inf(-1). An error is returned if the field is empty or not numeric.
+func (pd PerformanceData) MaxAsFloat() (float64, error) {
+	return perfDataBoundAsFloat("Max", pd.Label, pd.Max)
+}
+
+// perfDataBoundAsFloat converts the given Min or Max field value to a
+// float64 value.
+func perfDataBoundAsFloat(field string, label string, input string) (float64, error) {
+	input = strings.TrimSpace(input)
+
+	if input == "" {
+		return 0, fmt.Errorf(
+			"field %s of metric %q is not set: %w",
+			field,
+			label,
+			ErrInvalidPerformanceDataFormat,
+		)
+	}
+
+	if token, isInfinity := normalizeInfinityBound(input); isInfinity {
+		if token == PerfDataNegativeInfinityToken {
+			return math.Inf(-1), nil
+		}
+		return math.Inf(1), nil
+	}
+
+	f, err := strconv.ParseFloat(input, 64)
+	if err != nil || math.IsNaN(f) || math.IsInf(f, 0) {
+		return 0, fmt.Errorf(
+			"field %s value %q of metric %q is not numeric: %w",
+			field,
+			input,
+			label,
+			ErrInvalidPerformanceDataFormat,
+		)
+	}
+
+	return f, nil
+}
+
+// MarshalText implements the encoding.TextMarshaler interface. The
+// PerformanceData metric is validated and then rendered in the same format
+// as String (without the leading space). As with String, only the Value
+// field is rendered; a list of values (see the AllowValueList parsing
+// option) is not.
+//
+// Unlike String, an error is returned instead of structurally invalid
+// output. In addition to the checks performed by Validate, fields other than
+// the Label are required to be free of whitespace and semicolons and the
+// Label is required to be free of semicolons and line breaks. Whitespace
+// within the (single quoted) Label is permitted.
+func (pd PerformanceData) MarshalText() ([]byte, error) {
+	if err := pd.Validate(); err != nil {
+		return nil, fmt.Errorf("failed to marshal metric %q: %w", pd.Label, err)
+	}
+
+	if err := pd.validateRenderable(); err != nil {
+		return nil, fmt.Errorf("failed to marshal metric %q: %w", pd.Label, err)
+	}
+
+	return []byte(strings.TrimPrefix(pd.String(), " ")), nil
+}
+
+// validateRenderable asserts that the fields of the PerformanceData value
+// do not contain characters which would change the structure of the metric
+// when rendered by String. An error is returned for the first field which
+// fails validation.
+func (pd PerformanceData) validateRenderable() error {
+	if strings.ContainsAny(pd.Label, ";\r\n") {
+		return fmt.Errorf(
+			"field Label %q contains a semicolon or line break: %w",
+			pd.Label,
+			ErrInvalidPerformanceDataFormat,
+		)
+	}
+
+	fields := []struct {
+		name  string
+		value string
+	}{
+		{name: "Value", value: pd.Value},
+		{name: "UnitOfMeasurement", value: pd.UnitOfMeasurement},
+		{name: "Warn", value: pd.Warn},
+		{name: "Crit", value: pd.Crit},
+		{name: "Min", value: pd.Min},
+		{name: "Max", value: pd.Max},
+	}
+
+	for _, field := range fields {
+		if strings.ContainsRune(field.value, ';') || strings.IndexFunc(field.value, unicode.IsSpace) >= 0 {
+			return fmt.Errorf(
+				"field %s %q contains whitespace or a semicolon: %w",
+				field.name,
+				field.value,
+				ErrInvalidPerformanceDataFormat,
+			)
+		}
+	}
+
+	return nil
+}
+
+// UnmarshalText implements the encoding.TextUnmarshaler interface. The given
+// text is parsed as a single performance data metric in the same manner as
+// ParseSinglePerfData, except that whitespace within a single quoted label
+// (e.g., "'percent packet loss'=0%") and infinity tokens in the Min and Max
+// fields (see the AllowInfinityBounds parsing option) are permitted so that
+// any output of MarshalText is accepted. The result is stored in the
+// receiver. An error is returned (and the receiver is left unmodified) if the
+// text contains more than one metric or fails to parse.
+func (pd *PerformanceData) UnmarshalText(text []byte) error {
+	raw, err := preparePerfDataInput(string(text))
+	if err != nil {
+		return fmt.Errorf("failed to unmarshal performance data: %w", err)
+	}
+
+	metrics := splitQuotedPerfDataMetrics(raw)
+	if len(metrics) != 1 {
+		return fmt.Errorf(
+			"failed to unmarshal performance data: input contains %d metrics; expected exactly one: %w",
+			len(metrics),
+			ErrInvalidPerformanceDataFormat,
+		)
+	}
+
+	parsed, err := parsePerfData(metrics[0], PerfDataParseOptions{AllowInfinityBounds: true})
+	if err != nil {
+		return fmt.Errorf("failed to unmarshal performance data: %w", err)
+	}
+
+	*pd = parsed
+
+	return nil
+}
+
+// splitQuotedPerfDataMetrics splits a raw performance data string into
+// individual metric strings on whitespace, except for whitespace within a
+// single quoted label.
+func splitQuotedPerfDataMetrics(rawPerfdata string) []string {
+	var metrics []string
+	var metric strings.Builder
+
+	inQuotes := false
+	for _, r := range rawPerfdata {
+		switch {
+		case r == '\'':
+			inQuotes = !inQuotes
+		case !inQuotes && unicode.IsSpace(r):
+			if metric.Len() > 0 {
+				metrics = append(metrics, metric.String())
+				metric.Reset()
+			}
+			continue
+		}
+
+		metric.WriteRune(r)
+	}
+
+	if metric.Len() > 0 {
+		metrics = append(metrics, metric.String())
+	}
+
+	return metrics
+}
+
+// perfDataAnnotatedJSON is the JSON representation of an annotated
+// PerformanceData value.
+type perfDataAnnotatedJSON struct {
+	Metric      string            `json:"metric"`
+	Annotations map[string]string `json:"annotations"`
+}
+
+// MarshalJSON implements the json.Marshaler interface. A PerformanceData
+// value without annotations is encoded as a JSON string containing the
+// output of MarshalText. A value with annotations (see WithAnnotation) is
+// encoded as a JSON object containing that string ("metric") and the
+// annotations ("annotations"). An error is returned if MarshalText fails.
+func (pd PerformanceData) MarshalJSON() ([]byte, error) {
+	text, err := pd.MarshalText()
+	if err != nil {
+		return nil, err
+	}
+
+	if len(pd.annotations) == 0 {
+		return json.Marshal(string(text))
+	}
+
+	return json.Marshal(perfDataAnnotatedJSON{
+		Metric:      string(text),
+		Annotations: pd.annotations,
+	})
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface, accepting either
+// form produced by MarshalJSON. The metric is parsed using UnmarshalText. A
+// JSON null is ignored. An error is returned (and the receiver is left unmodified) if the input is
+// not in a supported form or if the metric fails to parse.
+func (pd *PerformanceData) UnmarshalJSON(data []byte) error {
+	var annotated perfDataAnnotatedJSON
+
+	trimmed := bytes.TrimSpace(data)
+
+	// By convention, unmarshaling a JSON null is a no-op.
+	if string(trimmed) == "null" {
+		return nil
+	}
+
+	if len(trimmed) > 0 && trimmed[0] == '"' {
+		if err := json.Unmarshal(trimmed, &annotated.Metric); err != nil {
+			return fmt.Errorf("failed to unmarshal performance data: %w", err)
+		}
+	} else if err := json.Unmarshal(trimmed, &annotated); err != nil {
+		return fmt.Errorf("failed to unmarshal performance data: %w", err)
+	}
+
+	var parsed PerformanceData
+	if err := parsed.UnmarshalText([]byte(annotated.Metric)); err != nil {
+		return err
+	}
+
+	if len(annotated.Annotations) > 0 {
+		parsed.annotations = annotated.Annotations
+	}
+
+	*pd = parsed
+
+	return nil
+}
+
+// ToMap returns the PerformanceData value as a map intended for generic
+// serialization (e.g., structured logging attributes or dynamic JSON). The
+// map contains the following keys:
+//
+//   - "label" (string): the Label field
+//   - "value" (float64 or nil): the Value field
+//   - "uom" (string): the UnitOfMeasurement field
+//   - "warn" (string): the Warn field
+//   - "crit" (string): the Crit field
+//   - "min" (float64 or nil): the Min field
+//   - "max" (float64 or nil): the Max field
+//   - "values" ([]interface{} of float64 or nil): the Values field; only
+//     present if the Values field is not empty
+//
+// Numeric fields are nil if empty or the literal "U" (undetermined) value.
+// Numeric fields which are otherwise not numeric (i.e., invalid) are
+// returned unmodified as a string.
+func (pd PerformanceData) ToMap() map[string]interface{} {
+	m := map[string]interface{}{
+		"label": pd.Label,
+		"value": perfDataNumericFieldToMapValue(pd.Value),
+		"uom":   pd.UnitOfMeasurement,
+		"warn":  pd.Warn,
+		"crit":  pd.Crit,
+		"min":   perfDataNumericFieldToMapValue(pd.Min),
+		"max":   perfDataNumericFieldToMapValue(pd.Max),
+	}
+
+	if len(pd.Values) > 0 {
+		values := make([]interface{}, len(pd.Values))
+		for i, v := range pd.Values {
+			values[i] = perfDataNumericFieldToMapValue(v)
+		}
+		m["values"] = values
+	}
+
+	return m
+}
+
+// perfDataNumericFieldToMapValue converts the given numeric performance data
+// field value for use by ToMap: nil for an empty or undetermined value, a
+// float64 if numeric and the original string otherwise.
+func perfDataNumericFieldToMapValue(input string) interface{} {
+	input = strings.TrimSpace(input)
+
+	if input == "" || input == "U" {
+		return nil
+	}
+
+	f, err := strconv.ParseFloat(input, 64)
+	if err != nil {
+		return input
+	}
+
+	return f
+}
+
+// RoundValue returns a copy of the PerformanceData value with the Value field
+// (and each entry of the Values field, if set) rounded to the given number
+// of decimal places (e.g., "0.266" rounded to 2 decimals is "0.27" and "5"
+// is "5.00"). Other fields, including Min and Max, are not modified. The
+// literal "U" value is returned unchanged.
+//
+// An error is returned if the number of decimal places is negative or if a
+// value is not numeric.
+func (pd PerformanceData) RoundValue(decimals int) (PerformanceData, error) {
+	if decimals < 0 {
+		return PerformanceData{}, fmt.Errorf(
+			"invalid number of decimal places %d: %w",
+			decimals,
+			ErrInvalidPerformanceDataFormat,
+		)
+	}
+
+	round := func(value string) (string, error) {
+		value = strings.TrimSpace(value)
+		if value == "U" {
+			return value, nil
+		}
+
+		f, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return "", fmt.Errorf(
+				"failed to round non-numeric value %q of metric %q: %w",
+				value,
+				pd.Label,
+				ErrInvalidPerformanceDataFormat,
+			)
+		}
+
+		return strconv.FormatFloat(f, 'f', decimals, 64), nil
+	}
+
+	rounded := pd.Clone()
+
+	var err error
+	rounded.Value, err = round(pd.Value)
+	if err != nil {
+		return PerformanceData{}, err
+	}
+
+	for i := range rounded.Values {
+		rounded.Values[i], err = round(rounded.Values[i])
+		if err != nil {
+			return PerformanceData{}, err
+		}
+	}
+
+	return rounded, nil
+}
+
+// Scale returns a copy of the PerformanceData value re-based to a new Unit
+// of Measurement (e.g., bytes to megabytes using a factor of 1/1048576). The
+// Value, Values, Min and Max fields are multiplied by the given factor and
+// the UnitOfMeasurement field is replaced with newUoM. Warn and Crit
+// thresholds are scaled and rendered in canonical form (see
+// Threshold.String). Empty fields, the literal "U" (undetermined) value and
+// infinity tokens in the Min and Max fields are not modified.
+//
+// An error is returned if the factor is not a positive finite number (a
+// negative factor would invert threshold ranges) or if a non-empty field
+// cannot be parsed.
+func (pd PerformanceData) Scale(factor float64, newUoM string) (PerformanceData, error) {
+	if factor <= 0 || math.IsInf(factor, 0) || math.IsNaN(factor) {
+		return PerformanceData{}, fmt.Errorf(
+			"invalid scaling factor %v for metric %q; expected positive finite number: %w",
+			factor,
+			pd.Label,
+			ErrInvalidPerformanceDataFormat,
+		)
+	}
+
+	scaleValue := func(field string, value string) (string, error) {
+		trimmed := strings.TrimSpace(value)
+		if trimmed == "" || trimmed == "U" {
+			return value, nil
+		}
+
+		if _, isInfinity := normalizeInfinityBound(trimmed); isInfinity {
+			return value, nil
+		}
+
+		f, err := strconv.ParseFloat(trimmed, 64)
+		if err != nil {
+			return "", fmt.Errorf(
+				"failed to scale non-numeric %s field value %q of metric %q: %w",
+				field,
+				value,
+				pd.Label,
+				ErrInvalidPerformanceDataFormat,
+			)
+		}
+
+		return strconv.FormatFloat(f*factor, 'f', -1, 64), nil
+	}
+
+	scaleThreshold := func(field string, value string) (string, error) {
+		t, err := parseOptionalThreshold(value)
+		if err != nil {
+			return "", fmt.Errorf("failed to scale %s field of metric %q: %w", field, pd.Label, err)
+		}
+
+		if t.IsEmpty() {
+			return value, nil
+		}
+
+		// Infinite bounds remain infinite.
+		t.Start *= factor
+		t.End *= factor
+
+		return t.String(), nil
+	}
+
+	scaled := pd.Clone()
+	scaled.UnitOfMeasurement = newUoM
+
+	var err error
+	for _, field := range []struct {
+		name  string
+		value *string
+		scale func(string, string) (string, error)
+	}{
+		{name: "Value", value: &scaled.Value, scale: scaleValue},
+		{name: "Warn", value: &scaled.Warn, scale: scaleThreshold},
+		{name: "Crit", value: &scaled.Crit, scale: scaleThreshold},
+		{name: "Min", value: &scaled.Min, scale: scaleValue},
+		{name: "Max", value: &scaled.Max, scale: scaleValue},
+	} {
+		*field.value, err = field.scale(field.name, *field.value)
+		if err != nil {
+			return PerformanceData{}, err
+		}
+	}
+
+	for i := range scaled.Values {
+		scaled.Values[i], err = scaleValue("Values", scaled.Values[i])
+		if err != nil {
+			return PerformanceData{}, err
+		}
+	}
+
+	return scaled, nil
+}
+
+// Raw returns the original metric string that this PerformanceData value was
+// parsed from. An empty string is returned for manually constructed values.
+func (pd PerformanceData) Raw() string {
+	return pd.raw
+}
+
+// Clone returns a deep copy of the PerformanceData value. This is the safe
+// way to derive a modified metric from an existing one; changes made to the
+// returned value do not affect the original.
+func (pd PerformanceData) Clone() PerformanceData {
+	// Strings are immutable and are safe to share; reference type fields
+	// must be explicitly copied.
+	clone := pd
+
+	if pd.Values != nil {
+		clone.Values = make([]string, len(pd.Values))
+		copy(clone.Values, pd.Values)
+	}
+
+	if pd.annotations != nil {
+		clone.annotations = make(map[string]string, len(pd.annotations))
+		for k, v := range pd.annotations {
+			clone.annotations[k] = v
+		}
+	}
+
+	return clone
+}
+
+// WithAnnotation returns a copy of the PerformanceData value with the given
+// annotation (e.g., a human readable note for internal tooling) attached,
+// replacing any existing annotation using the same key. The original value
+// is not modified.
+//
+// Annotations are never included in plugin output (e.g., String or
+// MarshalText) and are not considered by Equal or Hash. They are included in
+// the output of MarshalJSON.
+func (pd PerformanceData) WithAnnotation(key string, value string) PerformanceData {
+	annotated := pd.Clone()
+
+	if annotated.annotations == nil {
+		annotated.annotations = make(map[string]string, 1)
+	}
+	annotated.annotations[key] = value
+
+	return annotated
+}
+
+// Annotation returns the value of the annotation with the given key and
+// true if present, otherwise an empty string and false. See WithAnnotation.
+func (pd PerformanceData) Annotation(key string) (string, bool) {
+	value, ok := pd.annotations[key]
+
+	return value, ok
+}
+
+// Sanitized returns a cleaned copy of the PerformanceData value suitable for
+// emitting in plugin output along with a description of each change made.
+// This allows tolerant emission of manually constructed values while
+// retaining an audit trail of fixes. The following changes are made:
+//
+//   - leading and trailing whitespace is removed from the Label and
+//     UnitOfMeasurement fields
+//   - single quotes are removed from the Label field
+//   - equals signs in the Label field are replaced with underscores
+//   - single and double quotes and semicolons are removed from the
+//     UnitOfMeasurement field
+//   - unescaped pipe characters in the Label and UnitOfMeasurement fields
+//     are escaped with a backslash (see ExtractPerfDataSection)
+//
+// No other fields are modified and no further validation is performed; the
+// result may still fail validation (e.g., an empty Label or non-numeric
+// Value). A nil slice is returned if no changes were made.
+func (pd PerformanceData) Sanitized() (PerformanceData, []string) {
+	sanitized := pd.Clone()
+	var warnings []string
+
+	fix := func(field string, value *string, description string, fn func(string) string) {
+		fixed := fn(*value)
+		if fixed == *value {
+			return
+		}
+
+		warnings = append(warnings, fmt.Sprintf(
+			"%s: %s (%q to %q)",
+			field,
+			description,
+			*value,
+			fixed,
+		))
+		*value = fixed
+	}
+
+	fix("Label", &sanitized.Label, "removed leading and trailing whitespace", strings.TrimSpace)
+	fix("Label", &sanitized.Label, "removed single quotes", func(s string) string {
+		return strings.ReplaceAll(s, "'", "")
+	})
+	fix("Label", &sanitized.Label, "replaced equals signs with underscores", func(s string) string {
+		return strings.ReplaceAll(s, "=", "_")
+	})
+	fix("Label", &sanitized.Label, "escaped pipe characters", escapeUnescapedPipes)
+
+	fix("UnitOfMeasurement", &sanitized.UnitOfMeasurement, "removed leading and trailing whitespace", strings.TrimSpace)
+	fix("UnitOfMeasurement", &sanitized.UnitOfMeasurement, "removed quotes", func(s string) string {
+		return strings.NewReplacer("'", "", `"`, "").Replace(s)
+	})
+	fix("UnitOfMeasurement", &sanitized.UnitOfMeasurement, "removed semicolons", func(s string) string {
+		return strings.ReplaceAll(s, ";", "")
+	})
+	fix("UnitOfMeasurement", &sanitized.UnitOfMeasurement, "escaped pipe characters", escapeUnescapedPipes)
+
+	return sanitized, warnings
+}
+
+// escapeUnescapedPipes escapes each pipe character in s which is not already
+// preceded by a backslash.
+func escapeUnescapedPipes(s string) string {
+	if !strings.Contains(s, "|") {
+		return s
+	}
+
+	var escaped strings.Builder
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '\\':
+			// Copy the escaped character as-is.
+			escaped.WriteByte(s[i])
+			if i+1 < len(s) {
+				i++
+				escaped.WriteByte(s[i])
+			}
+		case '|':
+			escaped.WriteString(`\|`)
+		default:
+			escaped.WriteByte(s[i])
+		}
+	}
+
+	return escaped.String()
+}
+
+// Equal reports whether pd and other represent the same performance data
+// metric. The numeric Value, Values, Min and Max fields are compared using
+// their float values if both values parse successfully (e.g., "10" and
+// "10.0" are equal), otherwise as strings. All other fields are compared as
+// strings.
+//
+// Internal bookkeeping details (e.g., the raw string a value was parsed
+// from) are not considered.
+func (pd PerformanceData) Equal(other PerformanceData) bool {
+	return pd.Label == other.Label &&
+		perfDataNumericFieldsEqual(pd.Value, other.Value) &&
+		pd.UnitOfMeasurement == other.UnitOfMeasurement &&
+		pd.Warn == other.Warn &&
+		pd.Crit == other.Crit &&
+		perfDataNumericFieldsEqual(pd.Min, other.Min) &&
+		perfDataNumericFieldsEqual(pd.Max, other.Max) &&
+		perfDataNumericListsEqual(pd.Values, other.Values)
+}
+
+// EqualWithin reports whether pd and other represent the same performance
+// data metric in the same manner as Equal, except that the numeric Value,
+// Values, Min and Max fields are considered equal if their float values
+// differ by no more than tol (e.g., "20.41" and "20.42" are equal for a
+// tolerance of 0.05). This is intended for change detection which ignores
+// noise from sensors whose readings fluctuate in the last digit.
+//
+// Non-numeric fields, including the Warn and Crit thresholds, and numeric
+// fields which fail to parse (e.g., "U") are still compared exactly. A zero
+// (or negative) tolerance is equivalent to Equal.
+func (pd PerformanceData) EqualWithin(other PerformanceData, tol float64) bool {
+	if len(pd.Values) != len(other.Values) {
+		return false
+	}
+
+	for i := range pd.Values {
+		if !perfDataNumericFieldsWithin(pd.Values[i], other.Values[i], tol) {
+			return false
+		}
+	}
+
+	return pd.Label == other.Label &&
+		perfDataNumericFieldsWithin(pd.Value, other.Value, tol) &&
+		pd.UnitOfMeasurement == other.UnitOfMeasurement &&
+		pd.Warn == other.Warn &&
+		pd.Crit == other.Crit &&
+		perfDataNumericFieldsWithin(pd.Min, other.Min, tol) &&
+		perfDataNumericFieldsWithin(pd.Max, other.Max, tol)
+}
+
+// Hash returns a stable, non-cryptographic (FNV-1a) hash of the
+// PerformanceData value intended for use as a key by deduplication and
+// change-detection caches. Fields are canonicalized before hashing: the
+// Label is lowercased and the numeric Value, Values, Min and Max fields are
+// normalized (if numeric) so that cosmetic differences such as "10" vs
+// "10.0" hash identically.
+//
+// Values considered equal by Equal always produce the same hash. Values
+// which are not equal may (rarely) produce the same hash; in particular,
+// labels differing only by case produce the same hash.
+func (pd PerformanceData) Hash() uint64 {
+	h := fnv.New64a()
+
+	write := func(field string) {
+		// Writes to a hash.Hash never return an error.
+		_, _ = h.Write([]byte(field))
+		_, _ = h.Write([]byte{0})
+	}
+
+	write(strings.ToLower(pd.Label))
+	write(canonicalPerfDataNumericField(pd.Value))
+	write(pd.UnitOfMeasurement)
+	write(pd.Warn)
+	write(pd.Crit)
+	write(canonicalPerfDataNumericField(pd.Min))
+	write(canonicalPerfDataNumericField(pd.Max))
+
+	write(strconv.Itoa(len(pd.Values)))
+	for _, v := range pd.Values {
+		write(canonicalPerfDataNumericField(v))
+	}
+
+	return h.Sum64()
+}
+
+// HasWarn reports whether the Warn field is configured (non-empty). Use
+// PresentFields to determine whether the field was present (possibly empty)
+// in parsed input.
+func (pd PerformanceData) HasWarn() bool {
+	return strings.TrimSpace(pd.Warn) != ""
+}
+
+// HasCrit reports whether the Crit field is configured (non-empty). Use
+// PresentFields to determine whether the field was present (possibly empty)
+// in parsed input.
+func (pd PerformanceData) HasCrit() bool {
+	return strings.TrimSpace(pd.Crit) != ""
+}
+
+// HasMin reports whether the Min field is configured (non-empty). Use
+// PresentFields to determine whether the field was present (possibly empty)
+// in parsed input.
+func (pd PerformanceData) HasMin() bool {
+	return strings.TrimSpace(pd.Min) != ""
+}
+
+// HasMax reports whether the Max field is configured (non-empty). Use
+// PresentFields to determine whether the field was present (possibly empty)
+// in parsed input.
+func (pd PerformanceData) HasMax() bool {
+	return strings.TrimSpace(pd.Max) != ""
+}
+
+// PresentFields returns the names ("warn", "crit", "min", "max") of the
+// optional fields which were present in the parsed input, including fields
+// which were present but empty. For example, the fields present for the
+// metric "x=1;;5;" are "warn", "crit" and "min" even though only the Crit
+// field is configured.
+//
+// For manually constructed (not parsed) values the names of configured
+// (non-empty) optional fields are returned.
+func (pd PerformanceData) PresentFields() []string {
+	fields := []struct {
+		name       string
+		mask       perfDataFieldMask
+		configured bool
+	}{
+		{name: "warn", mask: perfDataFieldWarn, configured: pd.HasWarn()},
+		{name: "crit", mask: perfDataFieldCrit, configured: pd.HasCrit()},
+		{name: "min", mask: perfDataFieldMin, configured: pd.HasMin()},
+		{name: "max", mask: perfDataFieldMax, configured: pd.HasMax()},
+	}
+
+	present := make([]string, 0, len(fields))
+	for _, field := range fields {
+		if pd.present&field.mask != 0 || field.configured {
+			present = append(present, field.name)
+		}
+	}
+
+	return present
+}
+
+// EqualValues reports whether pd and other represent the same measurement,
+// comparing only the Label, Value (and Values) and UnitOfMeasurement fields
+// in the same manner as Equal. Unlike Equal, the Warn, Crit, Min and Max
+// fields are ignored; this is intended for detecting changes in measured
+// values regardless of changes to thresholds or bounds.
+func (pd PerformanceData) EqualValues(other PerformanceData) bool {
+	return pd.Label == other.Label &&
+		perfDataNumericFieldsEqual(pd.Value, other.Value) &&
+		pd.UnitOfMeasurement == other.UnitOfMeasurement &&
+		perfDataNumericListsEqual(pd.Values, other.Values)
+}
+
+// ValidateWithOptions performs the same validation as Validate along with
+// any additional (stricter) validation requested via the given options. An
+// error is returned for any validation failures.
+func (pd PerformanceData) ValidateWithOptions(opts PerfDataParseOptions) error {
+	if err := pd.Validate(); err != nil {
+		return err
+	}
+
+	return pd.validateOptions(opts)
+}
+
+// validateOptions applies optional validation checks requested via the given
+// options. An error is returned for any validation failures.
+func (pd PerformanceData) validateOptions(opts PerfDataParseOptions) error {
+	if opts.StrictUoM {
+		if err := validatePerfDataUoMFieldStrict(pd.UnitOfMeasurement); err != nil {
+			return err
+		}
+	}
+
+	if opts.StrictPercentage {
+		if err := pd.validatePercentRange(); err != nil {
+			return err
+		}
+	}
+
+	if opts.RejectUndeterminedWithThresholds && pd.IsValueUndetermined() && (pd.HasWarn() || pd.HasCrit()) {
+		return fmt.Errorf(
+			"metric %q has undetermined value with warn threshold %q and crit threshold %q: %w",
+			pd.Label,
+			pd.Warn,
+			pd.Crit,
+			ErrPerformanceDataUndeterminedWithThresholds,
+		)
+	}
+
+	return nil
+}
+
+// validatePercentRange asserts that the values of a metric using the "%"
+// Unit of Measurement are within the range 0 to 100 or the range given by
+// the Min and Max fields if specified. Metrics using other units of
+// measurement and the literal "U" (undetermined) value are not evaluated.
+func (pd PerformanceData) validatePercentRange() error {
+	if strings.TrimSpace(pd.UnitOfMeasurement) != "%" || pd.IsValueUndetermined() {
+		return nil
+	}
+
+	lower, upper := 0.0, 100.0
+
+	if strings.TrimSpace(pd.Min) != "" {
+		min, err := pd.MinAsFloat()
+		if err != nil {
+			return err
+		}
+		lower = min
+	}
+
+	if strings.TrimSpace(pd.Max) != "" {
+		max, err := pd.MaxAsFloat()
+		if err != nil {
+			return err
+		}
+		upper = max
+	}
+
+	values := pd.Values
+	if len(values) == 0 {
+		values = []string{pd.Value}
+	}
+
+	for _, v := range values {
+		value, err := strconv.ParseFloat(strings.TrimSpace(v), 64)
+		if err != nil {
+			return fmt.Errorf(
+				"failed to parse value %q of metric %q: %w",
+				v,
+				pd.Label,
+				ErrInvalidPerformanceDataFormat,
+			)
+		}
+
+		if value < lower || value > upper {
+			return fmt.Errorf(
+				"value %q of metric %q outside of range %v to %v: %w",
+				v,
+				pd.Label,
+				lower,
+				upper,
+				ErrPerformanceDataPercentOutOfRange,
+			)
+		}
+	}
+
+	return nil
+}
+
+// String provides a PerformanceData metric in format ready for use in plugin
+// output.
+func (pd PerformanceData) String() string {
+	return fmt.Sprintf(
+		// The expected format of a performance data metric:
+		//
+		// 'label'=value[UOM];[warn];[crit];[min];[max]
+		//
+		// References:
+		//
+		// https://nagios-plugins.org/doc/guidelines.html
+		// https://assets.nagios.com/downloads/nagioscore/docs/nagioscore/3/en/perfdata.html
+		// https://assets.nagios.com/downloads/nagioscore/docs/nagioscore/3/en/pluginapi.html
+		// https://www.monitoring-plugins.org/doc/guidelines.html
+		// https://icinga.com/docs/icinga-2/latest/doc/05-service-monitoring/#performance-data-metrics
+		" '%s'=%s%s;%s;%s;%s;%s",
+		pd.Label,
+		pd.ValueString(),
+		pd.UnitOfMeasurement,
+		pd.Warn,
+		pd.Crit,
+		pd.Min,
+		pd.Max,
+	)
+}
+
+// StringWithSeparator provides a PerformanceData metric in the same format as
+// String (without the leading space), using the given separator between the
+// optional fields instead of a semicolon. For example, using "|" as the
+// separator:
+//
+//	'label'=value[UOM]|[warn]|[crit]|[min]|[max]
+//
+// NOTE: This is a nonstandard format intended for interoperability with
+// tooling which does not consume Nagios performance data directly. Output
+// generated using a separator other than a semicolon is not valid Nagios
+// performance data and cannot be parsed by ParsePerfData.
+//
+// An error is returned if the separator is empty, contains whitespace, an
+// equals sign or a single quote (which are significant in performance data
+// metrics) or if the separator is present within any field value.
+func (pd PerformanceData) StringWithSeparator(sep string) (string, error) {
+	switch {
+	case sep == "":
+		return "", fmt.Errorf(
+			"empty separator provided: %w",
+			ErrInvalidPerformanceDataFormat,
+		)
+
+	case strings.ContainsAny(sep, "='") || strings.IndexFunc(sep, unicode.IsSpace) >= 0:
+		return "", fmt.Errorf(
+			"separator %q contains whitespace or reserved characters: %w",
+			sep,
+			ErrInvalidPerformanceDataFormat,
+		)
+	}
+
+	fields := []string{
+		pd.Label,
+		pd.ValueString(),
+		pd.UnitOfMeasurement,
+		pd.Warn,
+		pd.Crit,
+		pd.Min,
+		pd.Max,
+	}
+
+	for _, field := range fields {
+		if strings.Contains(field, sep) {
+			return "", fmt.Errorf(
+				"separator %q conflicts with content of field value %q: %w",
+				sep,
+				field,
+				ErrInvalidPerformanceDataFormat,
+			)
+		}
+	}
+
+	labelAndValue := fmt.Sprintf("'%s'=%s%s", pd.Label, pd.ValueString(), pd.UnitOfMeasurement)
+
+	return strings.Join(
+		[]string{labelAndValue, pd.Warn, pd.Crit, pd.Min, pd.Max},
+		sep,
+	), nil
+}
+
+// ValueString returns the Value field as it is emitted by String and
+// CompactString. The Value field is not normalized; the value string is
+// preserved byte-for-byte as parsed (e.g., "0.260" is not shortened to
+// "0.26") unless a parsing option which modifies the value (e.g.,
+// AllowCommaDecimal) was used. This allows preserving significant figures
+// emitted by a plugin.
+func (pd PerformanceData) ValueString() string {
+	return pd.Value
+}
+
+// ParseValueWith decodes the Value field using the given decoder function
+// and returns the named sub-fields provided by it. This is an extension point
+// for plugins which pack structured data into a single value using a bespoke
+// (non-standard) encoding; the performance data parser itself remains
+// standard-compliant and performs no such decoding.
+//
+// An error is returned if the decoder is nil or if the decoder returns an
+// error.
+func (pd PerformanceData) ParseValueWith(fn func(string) (map[string]float64, error)) (map[string]float64, error) {
+	if fn == nil {
+		return nil, fmt.Errorf("failed to decode value of metric %q: nil decoder", pd.Label)
+	}
+
+	fields, err := fn(pd.Value)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode value %q of metric %q: %w", pd.Value, pd.Label, err)
+	}
+
+	return fields, nil
+}
+
+// CompactString provides a PerformanceData metric in format ready for use in
+// plugin output, omitting trailing empty optional fields and their semicolon
+// separators. Fields are emitted up to and including the last non-empty
+// field among Warn, Crit, Min and Max; empty fields preceding it are emitted
+// as empty values to preserve field positions. For example:
+//
+//	'label'=value[UOM]
+//	'label'=value[UOM];warn
+//	'label'=value[UOM];;;min
+//
+// Unlike String, the output does not include a leading space.
+func (pd PerformanceData) CompactString() string {
+	var output strings.Builder
+
+	fmt.Fprintf(&output, "'%s'=%s%s", pd.Label, pd.ValueString(), pd.UnitOfMeasurement)
+
+	optionalFields := []string{pd.Warn, pd.Crit, pd.Min, pd.Max}
+
+	last := len(optionalFields) - 1
+	for last >= 0 && optionalFields[last] == "" {
+		last--
+	}
+
+	for _, field := range optionalFields[:last+1] {
+		fmt.Fprintf(&output, ";%s", field)
+	}
+
+	return output.String()
+}
+
+// FormatPerfDataLine formats the given collection of PerformanceData values
+// as a single line of performance data metrics (in the given order), each
+// separated from another by a single space. This is the format used when
+// appending performance data to plugin output (after the pipe separator).
+func FormatPerfDataLine(pd []PerformanceData) string {
+	metrics := make([]string, 0, len(pd))
+	for i := range pd {
+		metrics = append(metrics, strings.TrimPrefix(pd[i].String(), " "))
+	}
+
+	return strings.Join(metrics, " ")
+}
+
+// OutputSize returns the number of bytes used by the PerformanceData metric
+// when emitted by FormatPerfDataLine (i.e., the length of String without the
+// leading space). This allows checking output against size limits (e.g., as
+// imposed by NRPE) before emitting it.
+func (pd PerformanceData) OutputSize() int {
+	return len(pd.String()) - len(" ")
+}
+
+// TotalOutputSize returns the number of bytes used by the given collection
+// of PerformanceData values when emitted by FormatPerfDataLine, including
+// the space separating each metric. The result is equal to
+// len(FormatPerfDataLine(pd)).
+func TotalOutputSize(pd []PerformanceData) int {
+	if len(pd) == 0 {
+		return 0
+	}
+
+	// One separator between each pair of metrics.
+	size := len(pd) - 1
+	for i := range pd {
+		size += pd[i].OutputSize()
+	}
+
+	return size
+}
+
+// ParsePerfDataRoundTrip asserts that the given raw performance data string
+// survives a "round trip" unchanged: the input is parsed, re-emitted using
+// FormatPerfDataLine and parsed again with the results of both parsing
+// attempts compared using Equal.
+//
+// An error is returned if the initial parsing attempt fails. An error
+// wrapping ErrPerformanceDataRoundTripMismatch is returned if the re-emitted
+// performance data fails to parse or does not match the initial parsing
+// results. This is primarily intended for use by fuzz tests.
+func ParsePerfDataRoundTrip(raw string) error {
+	parsed, err := ParsePerfData(raw)
+	if err != nil {
+		return err
+	}
+
+	emitted := FormatPerfDataLine(parsed)
+
+	reparsed, err := ParsePerfData(emitted)
+	if err != nil {
+		return fmt.Errorf(
+			"%w: failed to parse emitted performance data %q: %v",
+			ErrPerformanceDataRoundTripMismatch,
+			emitted,
+			err,
+		)
+	}
+
+	if len(parsed) != len(reparsed) {
+		return fmt.Errorf(
+			"%w: parsed %d metrics from input, %d metrics from emitted performance data %q",
+			ErrPerformanceDataRoundTripMismatch,
+			len(parsed),
+			len(reparsed),
+			emitted,
+		)
+	}
+
+	for i := range parsed {
+		if !parsed[i].Equal(reparsed[i]) {
+			return fmt.Errorf(
+				"%w: metric %+v does not match re-parsed metric %+v",
+				ErrPerformanceDataRoundTripMismatch,
+				parsed[i],
+				reparsed[i],
+			)
+		}
+	}
+
+	return nil
+}
+
+// SpecifiedFields returns the names of the PerformanceData fields which are
+// specified, in canonical order (Label, Value, UnitOfMeasurement, Warn, Crit,
+// Min, Max). The Label and Value fields are required and are always
+// considered specified; the remaining optional fields are included only if
+// non-empty.
+//
+// This is intended to help callers distinguish between metrics which specify
+// (for example) only a Warn threshold and those which specify both Warn and
+// Crit thresholds.
+func (pd PerformanceData) SpecifiedFields() []string {
+	fields := []string{"Label", "Value"}
+
+	optionalFields := []struct {
+		name  string
+		value string
+	}{
+		{name: "UnitOfMeasurement", value: pd.UnitOfMeasurement},
+		{name: "Warn", value: pd.Warn},
+		{name: "Crit", value: pd.Crit},
+		{name: "Min", value: pd.Min},
+		{name: "Max", value: pd.Max},
+	}
+
+	for _, field := range optionalFields {
+		if field.value != "" {
+			fields = append(fields, field.name)
+		}
+	}
+
+	return fields
+}
+
+// splitPerfDataFields splits a single performance data metric string into
+// its semicolon separated fields. If the AllowSemicolonInQuotedLabel option
+// is enabled, semicolons within a leading single quoted label are not
+// treated as separators.
+func splitPerfDataFields(perfdataString string, opts PerfDataParseOptions) []string {
+	if !opts.AllowSemicolonInQuotedLabel || !strings.HasPrefix(perfdataString, "'") {
+		return strings.Split(perfdataString, ";")
+	}
+
+	closing := strings.Index(perfdataString[1:], "'")
+	if closing < 0 {
+		return strings.Split(perfdataString, ";")
+	}
+
+	// Index just past the closing single quote.
+	labelEnd := closing + 2
+	quotedLabel := perfdataString[:labelEnd]
+
+	if strings.Contains(quotedLabel, ";") {
+		opts.warnf("accepted semicolon in quoted label %s", quotedLabel)
+	}
+
+	fields := strings.Split(perfdataString[labelEnd:], ";")
+	fields[0] = quotedLabel + fields[0]
+
+	return fields
+}
+
+// parsePerfData parses an input string representing a performance data
+// emitted by a Nagios plugin metric such as "load1=0.260;5.000;10.000;0;" (no
+// quotes) into a PerformanceData value using the given parsing options.
+func parsePerfData(perfdataString string, opts PerfDataParseOptions) (PerformanceData, error) {
+
+	// Split based on semicolons.
+	//
+	// This turns an input string such as:
+	//
+	// load1=0.260;5.000;10.000;0;
+	//
+	// into:
+	//
+	// load1=0.260 (label of "load1", separator of "=", value of "0.260")
+	// 5.000 (Warn; warning threshold)
+	// 10.000 (Crit; critical threshold)
+	// 0 (Min)
+	//
+	// It is possible that no semicolons are provided, in which case it is
+	// assumed that we are working with a single performance data metric.
+	perfdataFields := splitPerfDataFields(perfdataString, opts)
+
+	// After splitting the input string on using a semicolon as separator
+	// there must be a minimum of one field (i.e., no semicolons present) and
+	// no more than the maximum/expected number based on the total fields of a
+	// performance data metric string.
+	switch numFields := len(perfdataFields); {
+	case numFields < perfDataMinSemicolonSeparatedFields:
+		return PerformanceData{}, fmt.Errorf(
+			"input appears to be empty; after processing %d fields found; expected minimum of %d: %w",
+			numFields,
+			perfDataMinSemicolonSeparatedFields,
+			ErrInvalidPerformanceDataFormat,
+		)
+
+	case numFields > perfDataMaxSemicolonSeparatedFields:
+		return PerformanceData{}, fmt.Errorf(
+			"input contains %d semicolon separated fields; expected no more than %d: %w",
+			numFields,
+			perfDataMaxSemicolonSeparatedFields,
+			ErrInvalidPerformanceDataFormat,
+		)
+	}
+
+	// DEBUG
+	// fmt.Printf(
+	// 	"%d semicolon separated fields from perfdataString: %q\n",
+	// 	len(perfdataFields),
+	// 	perfdataFields,
+	// )
+
+	label, rawValue, err := extractLabelAndRawValue(perfdataFields[0], opts)
+	if err != nil {
+		return PerformanceData{}, fmt.Errorf("failed to extract label and raw value: %w", err)
+	}
+
+	var values []string
+	var value, uom string
+	switch {
+	case opts.AllowValueList && strings.Contains(rawValue, ","):
+		values, uom, err = extractValueListAndUoM(rawValue, opts)
+		if err != nil {
+			return PerformanceData{}, fmt.Errorf("failed to extract value list and uom: %w", err)
+		}
+		value = values[0]
+		opts.warnf("accepted list of %d values", len(values))
+
+	default:
+		value, uom, err = extractValueAndUoM(rawValue, opts)
+		if err != nil {
+			return PerformanceData{}, fmt.Errorf("failed to extract value and uom: %w", err)
+		}
+	}
+
+	rawWarn, rawCrit, rawMin, rawMax := extractRawWarnCritMinMaxRawFieldVals(perfdataFields)
+
+	if opts.StripThresholdUnits {
+		if stripped := stripThresholdUnit(rawWarn, uom); stripped != rawWarn {
+			opts.warnf("removed unit of measurement %q from warn threshold %q", uom, rawWarn)
+			rawWarn = stripped
+		}
+
+		if stripped := stripThresholdUnit(rawCrit, uom); stripped != rawCrit {
+			opts.warnf("removed unit of measurement %q from crit threshold %q", uom, rawCrit)
+			rawCrit = stripped
+		}
+	}
 
 	warn, err := parsePerfDataWarnField(rawWarn)
 	if err != nil {
@@ -358,14 +2423,48 @@ func parsePerfData(perfdataString string) (PerformanceData, error) {
 		return PerformanceData{}, fmt.Errorf("failed to parse crit field: %w", err)
 	}
 
-	min, err := parsePerfDataMinField(rawMin)
-	if err != nil {
-		return PerformanceData{}, fmt.Errorf("failed to parse min field: %w", err)
+	min, isInfinity := normalizeInfinityBound(rawMin)
+	switch {
+	case isInfinity && opts.AllowInfinityBounds:
+		opts.warnf("accepted infinity token %q in min field", rawMin)
+	case isInfinity:
+		return PerformanceData{}, fmt.Errorf(
+			"failed to parse min field: infinity token %q requires the AllowInfinityBounds option: %w",
+			rawMin,
+			ErrInvalidPerformanceDataFormat,
+		)
+	default:
+		min, err = parsePerfDataMinField(rawMin)
+		if err != nil {
+			return PerformanceData{}, fmt.Errorf("failed to parse min field: %w", err)
+		}
 	}
 
-	max, err := parsePerfDataMaxField(rawMax)
-	if err != nil {
-		return PerformanceData{}, fmt.Errorf("failed to parse max field: %w", err)
+	max, isInfinity := normalizeInfinityBound(rawMax)
+	switch {
+	case isInfinity && opts.AllowInfinityBounds:
+		opts.warnf("accepted infinity token %q in max field", rawMax)
+	case isInfinity:
+		return PerformanceData{}, fmt.Errorf(
+			"failed to parse max field: infinity token %q requires the AllowInfinityBounds option: %w",
+			rawMax,
+			ErrInvalidPerformanceDataFormat,
+		)
+	default:
+		max, err = parsePerfDataMaxField(rawMax)
+		if err != nil {
+			return PerformanceData{}, fmt.Errorf("failed to parse max field: %w", err)
+		}
+	}
+
+	if opts.StripPositiveSign && strings.HasPrefix(min, "+") {
+		opts.warnf("removed positive sign from min field %q", min)
+		min = strings.TrimPrefix(min, "+")
+	}
+
+	if opts.StripPositiveSign && strings.HasPrefix(max, "+") {
+		opts.warnf("removed positive sign from max field %q", max)
+		max = strings.TrimPrefix(max, "+")
 	}
 
 	perfdata := PerformanceData{
@@ -376,6 +2475,13 @@ func parsePerfData(perfdataString string) (PerformanceData, error) {
 		Crit:              crit,
 		Min:               min,
 		Max:               max,
+		Values:            values,
+		raw:               perfdataString,
+		present:           perfDataFieldsPresent(len(perfdataFields)),
+	}
+
+	if err := perfdata.validateOptions(opts); err != nil {
+		return PerformanceData{}, err
 	}
 
 	return perfdata, nil
@@ -383,9 +2489,10 @@ func parsePerfData(perfdataString string) (PerformanceData, error) {
 }
 
 // extractLabelAndRawValue processes a given input string and extracts a Label
-// and a "raw" Value. The extracted "raw" Value requires further processing by
-// another helper function to extract the Value and Unit of Measurement. An
-// error is returned if parsing/validation fails.
+// and a "raw" Value using the given parsing options. The extracted "raw" Value
+// requires further processing by another helper function to extract the
+// Value and Unit of Measurement. An error is returned if parsing/validation
+// fails.
 //
 // NOTE:
 //
@@ -393,7 +2500,81 @@ func parsePerfData(perfdataString string) (PerformanceData, error) {
 // (which calls this helper function) is responsible for splitting the "raw"
 // performance data string first on spaces (individual performance data
 // metric), then on semicolons (fields in a performance data metric).
-func extractLabelAndRawValue(input string) (string, string, error) {
+func extractLabelAndRawValue(input string, opts PerfDataParseOptions) (string, string, error) {
+
+	if opts.AllowValuelessMetrics && input != "" && !strings.Contains(input, "=") {
+		// Treat the input as a bare label and substitute the undetermined
+		// value sentinel for the missing value.
+		opts.warnf("missing value for label %q; using undetermined value", input)
+		input += "=U"
+	}
+
+	label, rawValue, err := splitLabelAndRawValue(input)
+	if err != nil {
+		return "", "", err
+	}
+
+	// We require a Value, so we go ahead and assert that we have something
+	// before attempting any further processing.
+	if rawValue == "" {
+		return "", "", fmt.Errorf(
+			"metric value is not present in input string %q: %w",
+			input,
+			ErrInvalidPerformanceDataFormat,
+		)
+	}
+
+	if opts.DecodePercentEncodedLabels && strings.Contains(label, "%") {
+		decoded, err := url.PathUnescape(label)
+		if err != nil {
+			return "", "", fmt.Errorf(
+				"failed to decode percent-encoded label %q of input string %q: %v: %w",
+				label,
+				input,
+				err,
+				ErrInvalidPerformanceDataFormat,
+			)
+		}
+
+		if err := validatePerfDataLabelField(decoded); err != nil {
+			return "", "", fmt.Errorf(
+				"failed to validate decoded label %q of input string %q: %w",
+				decoded,
+				input,
+				err,
+			)
+		}
+
+		if decoded != label {
+			opts.warnf("decoded percent-encoded label %q to %q", label, decoded)
+			label = decoded
+		}
+	}
+
+	if opts.RequireUTF8Labels && !utf8.ValidString(label) {
+		return "", "", fmt.Errorf(
+			"label %q of input string %q is not valid UTF-8: %w",
+			label,
+			input,
+			ErrPerformanceDataInvalidUTF8,
+		)
+	}
+
+	if opts.NormalizeLabelCase {
+		if lower := strings.ToLower(label); lower != label {
+			opts.warnf("lowercased label %q", label)
+			label = lower
+		}
+	}
+
+	return label, rawValue, nil
+}
+
+// splitLabelAndRawValue splits a given input string on the first equals sign
+// into a validated Label and an unvalidated (possibly empty) "raw" Value. An
+// error is returned if the input string does not contain an equals sign or
+// if the Label fails validation.
+func splitLabelAndRawValue(input string) (string, string, error) {
 
 	if input == "" {
 		return "", "", fmt.Errorf(
@@ -430,21 +2611,21 @@ func extractLabelAndRawValue(input string) (string, string, error) {
 
 	rawValue := strings.TrimSpace(labelAndRawValue[1])
 
-	// We require a Value, so we go ahead and assert that we have something
-	// before attempting any further processing.
-	if rawValue == "" {
-		return "", "", fmt.Errorf(
-			"metric value is not present in input string %q: %w",
-			input,
-			ErrInvalidPerformanceDataFormat,
-		)
-	}
-
 	return label, rawValue, nil
 }
 
+// SplitValueAndUoM splits a given "raw" value token (e.g., "49ms") into its
+// Value and Unit of Measurement fields using the same logic applied by
+// ParsePerfData, including handling of the literal "U" value. The input
+// should not include a label or any semicolon separated fields. An error is
+// returned if parsing/validation fails.
+func SplitValueAndUoM(s string) (value string, uom string, err error) {
+	return extractValueAndUoM(strings.TrimSpace(s), PerfDataParseOptions{})
+}
+
 // extractValueAndUoM processes a given input string and extracts a Value and
-// Unit of Measurement. An error is returned if parsing/validation fails.
+// Unit of Measurement using the given parsing options. An error is returned
+// if parsing/validation fails.
 //
 // NOTE:
 //
@@ -452,7 +2633,7 @@ func extractLabelAndRawValue(input string) (string, string, error) {
 // (which calls this helper function) is responsible for splitting the "raw"
 // performance data string first on spaces (individual performance data
 // metric), then on semicolons (fields in a performance data metric).
-func extractValueAndUoM(input string) (string, string, error) {
+func extractValueAndUoM(input string, opts PerfDataParseOptions) (string, string, error) {
 
 	if input == "" {
 		return "", "", fmt.Errorf(
@@ -461,16 +2642,56 @@ func extractValueAndUoM(input string) (string, string, error) {
 		)
 	}
 
-	// Value may be a literal "U" (without quotes). If this is the case, there
-	// will not be a Unit of Measurement and we can skip further input
-	// parsing.
+	// Value may be a literal "U" (without quotes) or a caller specified
+	// token with the same meaning. If this is the case, there will not be a
+	// Unit of Measurement and we can skip further input parsing.
 	if input == "U" {
-		return input, "", nil
+		return "U", "", nil
+	}
+
+	if inList(input, opts.UndeterminedValueTokens, false) {
+		opts.warnf("mapped undetermined value token %q to %q", input, "U")
+		return "U", "", nil
+	}
+
+	if opts.AllowNaN && strings.EqualFold(input, "NaN") {
+		opts.warnf("mapped %q to %q", input, "U")
+		return "U", "", nil
+	}
+
+	if opts.EuropeanNumberFormat {
+		normalized, err := normalizeEuropeanNumber(input)
+		if err != nil {
+			return "", "", err
+		}
+		if normalized != input {
+			opts.warnf("converted European format number %q to %q", input, normalized)
+		}
+		input = normalized
+	}
+
+	if opts.AllowCommaDecimal {
+		normalized, err := normalizeCommaDecimal(input)
+		if err != nil {
+			return "", "", err
+		}
+		if normalized != input {
+			opts.warnf("converted comma decimal separator in %q to %q", input, normalized)
+		}
+		input = normalized
 	}
 
-	re := regexp.MustCompile(perfDataValueAndUoMFieldsRegex)
+	re := perfDataValueAndUoMFieldsRe
 
 	matches := re.FindStringSubmatch(input)
+	if len(matches) == 0 && opts.AllowLeadingUoM {
+		re = perfDataUoMAndValueFieldsRe
+		matches = re.FindStringSubmatch(input)
+		if len(matches) != 0 {
+			opts.warnf("accepted leading unit of measurement in %q", input)
+		}
+	}
+
 	if len(matches) == 0 {
 		return "", "", fmt.Errorf(
 			"failed to extract Value and UoM fields from input string %q: %w",
@@ -512,9 +2733,253 @@ func extractValueAndUoM(input string) (string, string, error) {
 		// fmt.Println(uom)
 	}
 
+	if opts.CanonicalizeUoM {
+		if canonical := CanonicalizeUoM(uom); canonical != uom {
+			opts.warnf("canonicalized unit of measurement %q to %q", uom, canonical)
+			uom = canonical
+		}
+	}
+
+	if opts.IcingaCompatUoM && uom != "" && !inList(uom, KnownUnitsOfMeasurement, false) {
+		opts.warnf("discarded unknown unit of measurement %q", uom)
+		uom = ""
+	}
+
+	if opts.StripPositiveSign && strings.HasPrefix(value, "+") {
+		opts.warnf("removed positive sign from value %q", value)
+		value = strings.TrimPrefix(value, "+")
+	}
+
 	return value, uom, nil
 }
 
+// perfDataNumericFieldsEqual reports whether two numeric performance data
+// field values are equal. The values are compared as floats if both parse
+// successfully, otherwise as strings.
+func perfDataNumericFieldsEqual(a string, b string) bool {
+	if a == b {
+		return true
+	}
+
+	aFloat, aErr := strconv.ParseFloat(a, 64)
+	bFloat, bErr := strconv.ParseFloat(b, 64)
+	if aErr != nil || bErr != nil {
+		return false
+	}
+
+	return aFloat == bFloat
+}
+
+// perfDataNumericFieldsWithin reports whether two numeric performance data
+// field values are equal as determined by perfDataNumericFieldsEqual or, if
+// both parse successfully as floats, differ by no more than tol.
+func perfDataNumericFieldsWithin(a string, b string, tol float64) bool {
+	if perfDataNumericFieldsEqual(a, b) {
+		return true
+	}
+
+	aFloat, aErr := strconv.ParseFloat(a, 64)
+	bFloat, bErr := strconv.ParseFloat(b, 64)
+	if aErr != nil || bErr != nil {
+		return false
+	}
+
+	return math.Abs(aFloat-bFloat) <= tol
+}
+
+// canonicalPerfDataNumericField returns the canonical form of the given
+// numeric performance data field value such that values considered equal by
+// perfDataNumericFieldsEqual share the same canonical form. Non-numeric
+// values are returned unmodified.
+func canonicalPerfDataNumericField(input string) string {
+	f, err := strconv.ParseFloat(input, 64)
+	if err != nil {
+		return input
+	}
+
+	// Negative zero is equal to zero.
+	if f == 0 {
+		f = 0
+	}
+
+	return strconv.FormatFloat(f, 'g', -1, 64)
+}
+
+// perfDataNumericListsEqual reports whether two collections of numeric
+// performance data field values are equal. Each value is compared using
+// perfDataNumericFieldsEqual.
+func perfDataNumericListsEqual(a []string, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+
+	for i := range a {
+		if !perfDataNumericFieldsEqual(a[i], b[i]) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// normalizeCommaDecimal replaces a single comma used as a decimal separator
+// in the leading numeric portion of the given input string with a period.
+// The input string is returned unmodified if no comma is present in the
+// numeric portion. An error is returned if multiple commas are present or if
+// a comma and period are both present.
+func normalizeCommaDecimal(input string) (string, error) {
+	numEnd := strings.IndexFunc(input, func(r rune) bool {
+		return r != ',' && !strings.ContainsRune(perfDataNumericCharacters, r)
+	})
+	if numEnd < 0 {
+		numEnd = len(input)
+	}
+
+	numeric := input[:numEnd]
+
+	switch commas := strings.Count(numeric, ","); {
+	case commas == 0:
+		return input, nil
+
+	case commas > 1:
+		return "", fmt.Errorf(
+			"numeric portion %q of input string %q contains %d commas; expected no more than 1: %w",
+			numeric,
+			input,
+			commas,
+			ErrInvalidPerformanceDataFormat,
+		)
+
+	case strings.Contains(numeric, "."):
+		return "", fmt.Errorf(
+			"numeric portion %q of input string %q contains both comma and period: %w",
+			numeric,
+			input,
+			ErrInvalidPerformanceDataFormat,
+		)
+	}
+
+	return strings.Replace(numeric, ",", ".", 1) + input[numEnd:], nil
+}
+
+// normalizeEuropeanNumber converts the leading numeric portion of the given
+// input string from European number format (periods as thousands separators
+// and a comma as decimal separator) to the format expected by the Value
+// field. An error is returned if the numeric portion is malformed or
+// ambiguous. See the EuropeanNumberFormat parsing option for details.
+func normalizeEuropeanNumber(input string) (string, error) {
+	numEnd := strings.IndexFunc(input, func(r rune) bool {
+		return r != ',' && !strings.ContainsRune(perfDataNumericCharacters, r)
+	})
+	if numEnd < 0 {
+		numEnd = len(input)
+	}
+
+	numeric := input[:numEnd]
+
+	unsigned := strings.TrimLeft(numeric, "+-")
+	sign := numeric[:len(numeric)-len(unsigned)]
+
+	invalid := func(reason string) error {
+		return fmt.Errorf(
+			"numeric portion %q of input string %q is not a valid European format number: %s: %w",
+			numeric,
+			input,
+			reason,
+			ErrInvalidPerformanceDataFormat,
+		)
+	}
+
+	integer, fraction, hasComma := strings.Cut(unsigned, ",")
+
+	switch {
+	case strings.Contains(fraction, ","):
+		return "", invalid("multiple commas")
+
+	case hasComma && (fraction == "" || strings.Trim(fraction, "0123456789") != ""):
+		return "", invalid("decimal portion must contain only digits")
+
+	case !strings.Contains(integer, "."):
+		// No thousands separators.
+
+	case !isThousandsGrouped(integer):
+		if hasComma || strings.Count(integer, ".") > 1 {
+			return "", invalid("thousands groups must contain three digits")
+		}
+
+		// A single period which cannot be a thousands separator is a
+		// decimal separator; leave as-is.
+		return input, nil
+
+	case !hasComma && strings.Count(integer, ".") == 1:
+		return "", invalid("ambiguous period; may be a decimal or thousands separator")
+	}
+
+	normalized := sign + strings.ReplaceAll(integer, ".", "")
+	if hasComma {
+		normalized += "." + fraction
+	}
+
+	return normalized + input[numEnd:], nil
+}
+
+// isThousandsGrouped reports whether the given string consists of period
+// separated groups of digits where the first group contains one to three
+// digits (without a leading zero) and all subsequent groups contain exactly
+// three digits (e.g., "1.234" or "12.345.678").
+func isThousandsGrouped(s string) bool {
+	groups := strings.Split(s, ".")
+
+	first := groups[0]
+	if len(first) < 1 || len(first) > 3 || first[0] == '0' || strings.Trim(first, "0123456789") != "" {
+		return false
+	}
+
+	for _, group := range groups[1:] {
+		if len(group) != 3 || strings.Trim(group, "0123456789") != "" {
+			return false
+		}
+	}
+
+	return true
+}
+
+// extractValueListAndUoM processes a given input string containing a comma
+// separated list of values (e.g., "20,21,22C") and extracts the values and
+// Unit of Measurement. The Unit of Measurement is expected after the last
+// value; if specified for other values it must match. An error is returned
+// if parsing/validation fails.
+func extractValueListAndUoM(input string, opts PerfDataParseOptions) ([]string, string, error) {
+	rawValues := strings.Split(input, ",")
+	values := make([]string, 0, len(rawValues))
+
+	_, uom, err := extractValueAndUoM(rawValues[len(rawValues)-1], opts)
+	if err != nil {
+		return nil, "", err
+	}
+
+	for _, rawValue := range rawValues {
+		value, valueUoM, err := extractValueAndUoM(rawValue, opts)
+		if err != nil {
+			return nil, "", err
+		}
+
+		if valueUoM != "" && valueUoM != uom {
+			return nil, "", fmt.Errorf(
+				"value list %q uses mismatched units of measurement %q and %q: %w",
+				input,
+				valueUoM,
+				uom,
+				ErrInvalidPerformanceDataFormat,
+			)
+		}
+
+		values = append(values, value)
+	}
+
+	return values, uom, nil
+}
+
 // extractRawWarnCritMinMaxRawFieldVals processes a given collection of field
 // values (obtained by splitting a performance data input string into separate
 // fields) into Warn, Crit, Min and Max values. If values are not present for
@@ -543,6 +3008,27 @@ func extractRawWarnCritMinMaxRawFieldVals(perfdataFields []string) (string, stri
 	return warn, crit, min, max
 }
 
+// perfDataFieldsPresent returns a bitmask of the optional fields present in
+// a metric string which was split into the given number of semicolon
+// separated fields. Optional fields are positional; a field is present
+// (possibly empty) if its position exists.
+func perfDataFieldsPresent(numFields int) perfDataFieldMask {
+	var present perfDataFieldMask
+
+	for i, field := range []perfDataFieldMask{
+		perfDataFieldWarn,
+		perfDataFieldCrit,
+		perfDataFieldMin,
+		perfDataFieldMax,
+	} {
+		if numFields >= i+2 {
+			present |= field
+		}
+	}
+
+	return present
+}
+
 // parsePerfDataWarnField evaluates the given input string as a Performance
 // Data "Warn" field value. An error is returned if validation fails,
 // otherwise a sanitized version of the input string is returned.
@@ -571,6 +3057,50 @@ func parsePerfDataCritField(input string) (string, error) {
 	return input, nil
 }
 
+// stripThresholdUnit removes the given Unit of Measurement from the end of
+// each endpoint of the given Warn or Crit threshold (e.g., "10%:80%" becomes
+// "10:80" for the "%" unit). The threshold is returned unmodified if the unit
+// is empty or contains characters used by the range format.
+func stripThresholdUnit(threshold string, uom string) string {
+	if uom == "" || strings.ContainsAny(uom, perfDataThresholdRangeCharacters) {
+		return threshold
+	}
+
+	endpoints := strings.Split(strings.TrimSpace(threshold), ":")
+	for i := range endpoints {
+		endpoints[i] = strings.TrimSuffix(endpoints[i], uom)
+	}
+
+	stripped := strings.Join(endpoints, ":")
+	if stripped == strings.TrimSpace(threshold) {
+		return threshold
+	}
+
+	return stripped
+}
+
+// normalizeInfinityBound returns the canonical infinity token for the given
+// Min or Max field input string if it is one of the infinity tokens accepted
+// by the AllowInfinityBounds parsing option. If not, the input string is
+// returned unmodified along with false.
+func normalizeInfinityBound(input string) (string, bool) {
+	switch strings.ToLower(strings.TrimSpace(input)) {
+	case "inf", "+inf", "infinity", "+infinity":
+		return PerfDataInfinityToken, true
+	case "-inf", "-infinity":
+		return PerfDataNegativeInfinityToken, true
+	default:
+		return input, false
+	}
+}
+
+// isPerfDataInfinityToken reports whether the given Min or Max field input
+// string is one of the canonical infinity tokens set by the
+// AllowInfinityBounds parsing option.
+func isPerfDataInfinityToken(input string) bool {
+	return input == PerfDataInfinityToken || input == PerfDataNegativeInfinityToken
+}
+
 // parsePerfDataMinField evaluates the given input string as a Performance
 // Data "Min" field value. An error is returned if validation fails, otherwise
 // a sanitized version of the input string is returned.
@@ -617,23 +3147,21 @@ func parsePerfDataMaxField(input string) (string, error) {
 func validatePerfDataLabelField(input string) error {
 	input = strings.TrimSpace(input)
 
-	if input != "" &&
-		!strings.ContainsAny(input, perfDataLabelFieldDisallowedCharacters) {
-		return nil
+	if input == "" {
+		return fmt.Errorf(
+			"field Label fails validation: empty input string: %w",
+			ErrInvalidPerformanceDataFormat,
+		)
 	}
 
-	invalidCharErr := fmt.Errorf(
-		"input string %q contains disallowed character from set %q: %w",
-		input,
-		perfDataLabelFieldDisallowedCharacters,
-		ErrInvalidPerformanceDataFormat,
-	)
+	if err := findDisallowedCharacter(input, perfDataLabelFieldDisallowedCharacters); err != nil {
+		return fmt.Errorf(
+			"field Label fails validation: %w",
+			err,
+		)
+	}
 
-	// Assume the worst
-	return fmt.Errorf(
-		"field Label fails validation: %w",
-		invalidCharErr,
-	)
+	return nil
 }
 
 // validatePerfDataValueField asserts that a given input string from the Value
@@ -646,8 +3174,7 @@ func validatePerfDataLabelField(input string) error {
 func validatePerfDataValueField(input string) error {
 	input = strings.TrimSpace(input)
 
-	re := regexp.MustCompile(perfDataValueFieldRegex)
-	if re.MatchString(input) {
+	if perfDataValueFieldRe.MatchString(input) {
 		return nil
 	}
 
@@ -672,21 +3199,75 @@ func validatePerfDataUoMField(input string) error {
 		return nil
 	}
 
-	if !strings.ContainsAny(input, perfDataUoMFieldDisallowedCharacters) {
+	if err := findDisallowedCharacter(input, perfDataUoMFieldDisallowedCharacters); err != nil {
+		return fmt.Errorf(
+			"field UnitOfMeasurement fails validation: %w",
+			err,
+		)
+	}
+
+	return nil
+}
+
+// findDisallowedCharacter returns an error identifying the first character
+// (and its byte index) in the given input string which is present in the
+// given set of disallowed characters. Nil is returned if no disallowed
+// characters are found.
+func findDisallowedCharacter(input string, disallowed string) error {
+	idx := strings.IndexAny(input, disallowed)
+	if idx < 0 {
 		return nil
 	}
 
-	invalidCharErr := fmt.Errorf(
-		"input string %q contains disallowed character from set %q: %w",
+	r, _ := utf8.DecodeRuneInString(input[idx:])
+
+	return fmt.Errorf(
+		"input string %q contains disallowed character %q at index %d (disallowed set %q): %w",
 		input,
-		perfDataUoMFieldDisallowedCharacters,
+		r,
+		idx,
+		disallowed,
 		ErrInvalidPerformanceDataFormat,
 	)
+}
+
+// validatePerfDataUoMFieldStrict asserts that a given input string from the
+// UnitOfMeasurement field of a parsed Performance Data value is either empty
+// or one of the KnownUnitsOfMeasurement values. An error is returned if
+// validation fails.
+func validatePerfDataUoMFieldStrict(input string) error {
+	input = strings.TrimSpace(input)
+
+	if input == "" || inList(input, KnownUnitsOfMeasurement, false) {
+		return nil
+	}
+
+	return fmt.Errorf(
+		"field UnitOfMeasurement value %q not in set %q: %w",
+		input,
+		KnownUnitsOfMeasurement,
+		ErrPerformanceDataUnknownUoM,
+	)
+}
+
+// validatePerfDataThresholdCharacters asserts that a given Warn or Crit field
+// input string contains only characters used by the range format. An error
+// is returned identifying any other characters (e.g., a Unit of Measurement
+// suffix such as the "%" in "80%").
+func validatePerfDataThresholdCharacters(input string) error {
+	idx := strings.IndexFunc(input, func(r rune) bool {
+		return !strings.ContainsRune(perfDataThresholdRangeCharacters, r)
+	})
+
+	if idx < 0 {
+		return nil
+	}
 
-	// Assume the worst
 	return fmt.Errorf(
-		"field UnitOfMeasurement fails validation: %w",
-		invalidCharErr,
+		"threshold %q contains non-range characters %q; units of measurement must not be included in thresholds: %w",
+		input,
+		input[idx:],
+		ErrInvalidPerformanceDataFormat,
 	)
 }
 
@@ -697,6 +3278,11 @@ func validatePerfDataUoMField(input string) error {
 // Validation is successful if either is true:
 //   - an empty string is permitted
 //   - range format
+//
+// NOTE: Thresholds must use the same Unit of Measurement as the Value field
+// and must not include the unit themselves (e.g., "80" instead of "80%").
+// Input containing characters outside of the range format (such as a unit
+// suffix) is explicitly rejected.
 func validatePerfDataWarnField(input string) error {
 
 	input = strings.TrimSpace(input)
@@ -705,8 +3291,11 @@ func validatePerfDataWarnField(input string) error {
 		return nil
 	}
 
-	re := regexp.MustCompile(perfDataThresholdRangeSyntaxRegex)
-	if re.MatchString(input) {
+	if err := validatePerfDataThresholdCharacters(input); err != nil {
+		return fmt.Errorf("field Warn fails validation: %w", err)
+	}
+
+	if perfDataThresholdRangeSyntaxRe.MatchString(input) {
 		return nil
 	}
 
@@ -724,6 +3313,11 @@ func validatePerfDataWarnField(input string) error {
 // Validation is successful if either is true:
 //   - an empty string is permitted
 //   - range format
+//
+// NOTE: Thresholds must use the same Unit of Measurement as the Value field
+// and must not include the unit themselves (e.g., "80" instead of "80%").
+// Input containing characters outside of the range format (such as a unit
+// suffix) is explicitly rejected.
 func validatePerfDataCritField(input string) error {
 
 	input = strings.TrimSpace(input)
@@ -732,8 +3326,11 @@ func validatePerfDataCritField(input string) error {
 		return nil
 	}
 
-	re := regexp.MustCompile(perfDataThresholdRangeSyntaxRegex)
-	if re.MatchString(input) {
+	if err := validatePerfDataThresholdCharacters(input); err != nil {
+		return fmt.Errorf("field Crit fails validation: %w", err)
+	}
+
+	if perfDataThresholdRangeSyntaxRe.MatchString(input) {
 		return nil
 	}
 
@@ -748,9 +3345,11 @@ func validatePerfDataCritField(input string) error {
 // field of a parsed Performance Data value is in the correct format. An error
 // is returned if validation fails.
 //
-// Validation is successful if either is true:
+// Validation is successful if any is true:
 //   - an empty string is permitted
 //   - range format
+//   - PerfDataInfinityToken or PerfDataNegativeInfinityToken (as set by the
+//     AllowInfinityBounds parsing option)
 func validatePerfDataMinField(input string) error {
 
 	input = strings.TrimSpace(input)
@@ -759,8 +3358,17 @@ func validatePerfDataMinField(input string) error {
 		return nil
 	}
 
-	re := regexp.MustCompile(perfDataMinMaxFieldsRegex)
-	if re.MatchString(input) {
+	// Some plugins erroneously use the literal "U" value here. Report this
+	// specifically while remaining compatible with callers checking for the
+	// general format error.
+	if input == "U" {
+		return fmt.Errorf(
+			"field Min fails validation: %w",
+			chainErrors(ErrPerformanceDataUnexpectedSentinel, ErrInvalidPerformanceDataFormat),
+		)
+	}
+
+	if perfDataMinMaxFieldsRe.MatchString(input) || isPerfDataInfinityToken(input) {
 		return nil
 	}
 
@@ -775,9 +3383,11 @@ func validatePerfDataMinField(input string) error {
 // field of a parsed Performance Data value is in the correct format. An error
 // is returned if validation fails.
 //
-// Validation is successful if either is true:
+// Validation is successful if any is true:
 //   - an empty string is permitted
 //   - range format
+//   - PerfDataInfinityToken or PerfDataNegativeInfinityToken (as set by the
+//     AllowInfinityBounds parsing option)
 func validatePerfDataMaxField(input string) error {
 
 	input = strings.TrimSpace(input)
@@ -786,8 +3396,17 @@ func validatePerfDataMaxField(input string) error {
 		return nil
 	}
 
-	re := regexp.MustCompile(perfDataMinMaxFieldsRegex)
-	if re.MatchString(input) {
+	// Some plugins erroneously use the literal "U" value here. Report this
+	// specifically while remaining compatible with callers checking for the
+	// general format error.
+	if input == "U" {
+		return fmt.Errorf(
+			"field Max fails validation: %w",
+			chainErrors(ErrPerformanceDataUnexpectedSentinel, ErrInvalidPerformanceDataFormat),
+		)
+	}
+
+	if perfDataMinMaxFieldsRe.MatchString(input) || isPerfDataInfinityToken(input) {
 		return nil
 	}
 
@@ -0,0 +1,507 @@
+// Copyright 2023 Adam Chalkley
+//
+// https://github.com/atc0005/go-nagios
+//
+// Licensed under the MIT License. See LICENSE file in the project root for
+// full license information.
+
+package nagios
+
+import (
+	"errors"
+	"fmt"
+	"path"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// rrdLabelSignificantCharacters is the number of leading characters of a
+// performance data label which are significant when the metric is stored
+// using RRD.
+const rrdLabelSignificantCharacters int = 19
+
+// MergeStrategy indicates how duplicate performance data labels are handled
+// when merging collections of PerformanceData values.
+type MergeStrategy int
+
+const (
+	// MergeError indicates that an error is returned if a duplicate label
+	// is encountered.
+	MergeError MergeStrategy = iota
+
+	// MergeFirstWins indicates that the first metric using a label is
+	// retained and later metrics using the same label are discarded.
+	MergeFirstWins
+
+	// MergeLastWins indicates that the last metric using a label is retained
+	// and earlier metrics using the same label are replaced.
+	MergeLastWins
+)
+
+// MergePerfData merges the given collections of PerformanceData values into
+// a single collection using the specified strategy to handle duplicate
+// labels. Labels are compared using case-insensitive matching.
+//
+// Metrics in the returned collection are ordered by the first appearance of
+// each label; if the MergeLastWins strategy replaces a metric, the
+// replacement takes the position of the original. An empty (non-nil)
+// collection is returned if no metrics are provided.
+func MergePerfData(strategy MergeStrategy, sets ...[]PerformanceData) ([]PerformanceData, error) {
+	switch strategy {
+	case MergeError, MergeFirstWins, MergeLastWins:
+	default:
+		return nil, fmt.Errorf("unsupported merge strategy %d", strategy)
+	}
+
+	var total int
+	for _, set := range sets {
+		total += len(set)
+	}
+
+	merged := make([]PerformanceData, 0, total)
+	indexes := make(map[string]int, total)
+
+	for _, set := range sets {
+		for _, pd := range set {
+			key := strings.ToLower(pd.Label)
+
+			idx, exists := indexes[key]
+			switch {
+			case !exists:
+				indexes[key] = len(merged)
+				merged = append(merged, pd)
+
+			case strategy == MergeError:
+				return nil, fmt.Errorf(
+					"label %q conflicts with label %q: %w",
+					pd.Label,
+					merged[idx].Label,
+					ErrPerformanceDataDuplicateLabel,
+				)
+
+			case strategy == MergeLastWins:
+				merged[idx] = pd
+			}
+		}
+	}
+
+	return merged, nil
+}
+
+// PerfDataChange records the old and new versions of a changed performance
+// data metric.
+type PerfDataChange struct {
+	Old PerformanceData
+	New PerformanceData
+}
+
+// PerfDataDiff represents the differences between two collections of
+// PerformanceData values.
+type PerfDataDiff struct {
+	// Added is the collection of labels present only in the new collection,
+	// in order of appearance.
+	Added []string
+
+	// Removed is the collection of labels present only in the old
+	// collection, in order of appearance.
+	Removed []string
+
+	// Changed is the collection of metrics present in both collections
+	// which are not equal, keyed by label.
+	Changed map[string]PerfDataChange
+}
+
+// DiffPerfData compares the given old (a) and new (b) collections of
+// PerformanceData values and returns the labels which were added or removed
+// along with metrics which changed (keyed by label). Metrics are matched
+// using their label (case-sensitive) and compared using the Equal method;
+// changes to any field (including thresholds) are reported.
+//
+// If a label is used by multiple metrics within a collection the last
+// metric is used.
+func DiffPerfData(a, b []PerformanceData) PerfDataDiff {
+	diff := PerfDataDiff{
+		Changed: make(map[string]PerfDataChange),
+	}
+
+	oldMetrics := make(map[string]PerformanceData, len(a))
+	for _, pd := range a {
+		oldMetrics[pd.Label] = pd
+	}
+
+	newMetrics := make(map[string]PerformanceData, len(b))
+	for _, pd := range b {
+		newMetrics[pd.Label] = pd
+	}
+
+	for _, pd := range a {
+		if _, exists := newMetrics[pd.Label]; !exists && !inList(pd.Label, diff.Removed, false) {
+			diff.Removed = append(diff.Removed, pd.Label)
+		}
+	}
+
+	for _, pd := range b {
+		oldMetric, exists := oldMetrics[pd.Label]
+		switch {
+		case !exists:
+			if !inList(pd.Label, diff.Added, false) {
+				diff.Added = append(diff.Added, pd.Label)
+			}
+
+		case !oldMetric.Equal(newMetrics[pd.Label]):
+			diff.Changed[pd.Label] = PerfDataChange{
+				Old: oldMetric,
+				New: newMetrics[pd.Label],
+			}
+		}
+	}
+
+	return diff
+}
+
+// ValidatePerfDataSlice validates each PerformanceData value in the given
+// collection using the Validate method. A single error joining all
+// validation failures is returned; each failure is annotated with the index
+// and label of the invalid metric and wraps the original error so that
+// errors.Is can be used to detect specific validation failures. Nil is
+// returned if all metrics are valid or if the collection is empty.
+func ValidatePerfDataSlice(pd []PerformanceData) error {
+	var errs []error
+
+	for i := range pd {
+		if err := pd[i].Validate(); err != nil {
+			errs = append(errs, fmt.Errorf("metric %d (%q): %w", i, pd[i].Label, err))
+		}
+	}
+
+	return joinErrors(errs...)
+}
+
+// ConcurrentPerfData is a collection of PerformanceData values which is safe
+// for concurrent use by multiple goroutines. This is intended for plugins
+// which collect performance data from parallel sub-checks. The zero value is
+// an empty collection ready for use.
+//
+// A ConcurrentPerfData value must not be copied after first use.
+type ConcurrentPerfData struct {
+	mu       sync.Mutex
+	perfData []PerformanceData
+}
+
+// Add appends the given PerformanceData values to the collection. Values are
+// copied; later changes to the given values do not affect the collection.
+// Metrics are not validated.
+func (c *ConcurrentPerfData) Add(pd ...PerformanceData) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for i := range pd {
+		c.perfData = append(c.perfData, pd[i].Clone())
+	}
+}
+
+// Snapshot returns a copy of the PerformanceData values currently in the
+// collection in the order they were added. Changes to the returned values
+// do not affect the collection.
+func (c *ConcurrentPerfData) Snapshot() []PerformanceData {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	snapshot := make([]PerformanceData, 0, len(c.perfData))
+	for i := range c.perfData {
+		snapshot = append(snapshot, c.perfData[i].Clone())
+	}
+
+	return snapshot
+}
+
+// CheckRRDLabelCollisions returns a description of each pair of labels in
+// the given collection of PerformanceData values which share the same first
+// 19 characters. RRD based storage (e.g., as used by PNP4Nagios) effectively
+// only uses the first 19 characters of a label as a data source name, so
+// labels sharing that prefix (or identical labels) collide in storage. An
+// empty collection is returned if no collisions are found.
+//
+// This is intended as a lint-style check for plugin authors targeting RRD
+// backends; labels are otherwise permitted to be of arbitrary length.
+func CheckRRDLabelCollisions(pd []PerformanceData) []string {
+	collisions := make([]string, 0)
+
+	prefixes := make([]string, len(pd))
+	for i := range pd {
+		prefix := []rune(pd[i].Label)
+		if len(prefix) > rrdLabelSignificantCharacters {
+			prefix = prefix[:rrdLabelSignificantCharacters]
+		}
+		prefixes[i] = string(prefix)
+	}
+
+	for i := range pd {
+		for j := i + 1; j < len(pd); j++ {
+			if prefixes[i] != prefixes[j] {
+				continue
+			}
+
+			collisions = append(collisions, fmt.Sprintf(
+				"labels %q and %q share RRD significant prefix %q",
+				pd[i].Label,
+				pd[j].Label,
+				prefixes[i],
+			))
+		}
+	}
+
+	return collisions
+}
+
+// stateSeverity ranks plugin state exit codes from least to most severe for
+// use when determining the worst state across multiple metrics.
+var stateSeverity = map[int]int{
+	StateOKExitCode:       0,
+	StateUNKNOWNExitCode:  1,
+	StateWARNINGExitCode:  2,
+	StateCRITICALExitCode: 3,
+}
+
+// WorstState evaluates each of the given PerformanceData values using
+// AlertState and returns the most severe plugin state exit code along with
+// the label of the metric responsible for it. States are ordered from most
+// to least severe as follows:
+//
+//  1. StateCRITICALExitCode
+//  2. StateWARNINGExitCode
+//  3. StateUNKNOWNExitCode
+//  4. StateOKExitCode
+//
+// Metrics with a "U" (undetermined) Value contribute StateUNKNOWNExitCode.
+// If multiple metrics share the most severe state the label of the first is
+// returned. StateOKExitCode and an empty label are returned for an empty
+// collection.
+//
+// An error and StateUNKNOWNExitCode are returned (along with the label of
+// the metric) if a metric cannot be evaluated for any other reason (e.g., an
+// unparseable threshold).
+func WorstState(pd []PerformanceData) (int, string, error) {
+	worstState := StateOKExitCode
+	var worstLabel string
+
+	for i := range pd {
+		state, err := pd[i].AlertState()
+		switch {
+		case errors.Is(err, ErrPerformanceDataValueUndetermined):
+			state = StateUNKNOWNExitCode
+
+		case err != nil:
+			return StateUNKNOWNExitCode, pd[i].Label, fmt.Errorf(
+				"failed to evaluate metric %d (%q): %w",
+				i,
+				pd[i].Label,
+				err,
+			)
+		}
+
+		if stateSeverity[state] > stateSeverity[worstState] {
+			worstState = state
+			worstLabel = pd[i].Label
+		}
+	}
+
+	return worstState, worstLabel, nil
+}
+
+// PerfDataByLabel returns the labels of the given PerformanceData values (in
+// the original order) along with a map of those values keyed by label. This
+// is intended for use with templates where stable iteration order and lookup
+// by label are both needed. Labels are compared using exact matching. An
+// error wrapping ErrPerformanceDataDuplicateLabel is returned if multiple
+// metrics use the same label.
+func PerfDataByLabel(pd []PerformanceData) ([]string, map[string]PerformanceData, error) {
+	labels := make([]string, 0, len(pd))
+	byLabel := make(map[string]PerformanceData, len(pd))
+
+	for i := range pd {
+		if _, exists := byLabel[pd[i].Label]; exists {
+			return nil, nil, fmt.Errorf(
+				"label %q of metric %d already in use: %w",
+				pd[i].Label,
+				i,
+				ErrPerformanceDataDuplicateLabel,
+			)
+		}
+
+		labels = append(labels, pd[i].Label)
+		byLabel[pd[i].Label] = pd[i]
+	}
+
+	return labels, byLabel, nil
+}
+
+// FilterPerfData returns the PerformanceData values (in the original order)
+// whose labels match the given shell pattern (e.g., "cpu*"). Patterns use the
+// syntax supported by path.Match; as with path.Match the "*" and "?"
+// wildcards do not match the "/" character, so a pattern such as "/*" is
+// needed to match a label such as "/var" and "/var/*" to match "/var/log".
+//
+// A non-nil, empty slice is returned if no labels match. An error wrapping
+// path.ErrBadPattern is returned if the pattern is malformed.
+func FilterPerfData(pd []PerformanceData, pattern string) ([]PerformanceData, error) {
+	// Validate the pattern up front so that a malformed pattern is reported
+	// even when the collection is empty.
+	if _, err := path.Match(pattern, ""); err != nil {
+		return nil, fmt.Errorf("invalid label pattern %q: %w", pattern, err)
+	}
+
+	filtered := make([]PerformanceData, 0, len(pd))
+	for i := range pd {
+		matched, err := path.Match(pattern, pd[i].Label)
+		if err != nil {
+			return nil, fmt.Errorf("invalid label pattern %q: %w", pattern, err)
+		}
+
+		if matched {
+			filtered = append(filtered, pd[i])
+		}
+	}
+
+	return filtered, nil
+}
+
+// DropZeroValues returns the PerformanceData values (in the original order)
+// whose Value field is not zero as reported by IsZeroValue. Metrics with the
+// literal "U" (undetermined) value are not considered zero and are retained.
+// This is intended for omitting uninteresting metrics before emitting
+// performance data.
+func DropZeroValues(pd []PerformanceData) []PerformanceData {
+	filtered := make([]PerformanceData, 0, len(pd))
+	for i := range pd {
+		if !pd[i].IsZeroValue() {
+			filtered = append(filtered, pd[i])
+		}
+	}
+
+	return filtered
+}
+
+// GroupPerfDataByUoM returns the given PerformanceData values grouped by
+// Unit of Measurement, retaining the original order within each group. Map
+// keys are the canonical form of each unit (see CanonicalizeUoM) so that
+// case variants such as "KB" and "kb" are grouped together; the values
+// themselves are not modified. Metrics without a Unit of Measurement are
+// grouped under the empty string key. This is intended for graphing systems
+// which display a panel per unit type.
+func GroupPerfDataByUoM(pd []PerformanceData) map[string][]PerformanceData {
+	groups := make(map[string][]PerformanceData)
+
+	for i := range pd {
+		uom := CanonicalizeUoM(pd[i].UnitOfMeasurement)
+		groups[uom] = append(groups[uom], pd[i])
+	}
+
+	return groups
+}
+
+// SumPerfData returns the sum of the Value fields of the given
+// PerformanceData values using the specified Unit of Measurement (e.g.,
+// "B"). Units are compared using their canonical form (see CanonicalizeUoM).
+// If the filter is empty the values of all metrics are summed regardless of
+// unit. Note that no unit conversion is performed; for example, "KB" and
+// "MB" values are distinct units and are not summed together when filtering.
+//
+// Metrics with the literal "U" (undetermined) value are skipped. An error is
+// returned if the Value field of any other matching metric is not numeric.
+func SumPerfData(pd []PerformanceData, uomFilter string) (float64, error) {
+	uomFilter = CanonicalizeUoM(uomFilter)
+
+	var sum float64
+	for i := range pd {
+		if uomFilter != "" && CanonicalizeUoM(pd[i].UnitOfMeasurement) != uomFilter {
+			continue
+		}
+
+		if pd[i].IsValueUndetermined() {
+			continue
+		}
+
+		value, err := strconv.ParseFloat(strings.TrimSpace(pd[i].Value), 64)
+		if err != nil {
+			return 0, fmt.Errorf(
+				"failed to parse value %q of metric %q: %w",
+				pd[i].Value,
+				pd[i].Label,
+				ErrInvalidPerformanceDataFormat,
+			)
+		}
+
+		sum += value
+	}
+
+	return sum, nil
+}
+
+// TrimToSize returns the longest leading portion of the given collection of
+// PerformanceData values which fits within maxBytes when emitted by
+// FormatPerfDataLine (see TotalOutputSize), along with whether any metrics
+// were dropped. This is intended for avoiding truncation by size limited
+// transports (e.g., NRPE) which would otherwise corrupt the last metric.
+//
+// Metrics are dropped from the end of the collection first; trailing metrics
+// are considered lowest priority. To keep the most important metrics, order
+// the collection by priority (highest first) before calling this function.
+// An empty collection is returned if not even the first metric fits.
+func TrimToSize(pd []PerformanceData, maxBytes int) ([]PerformanceData, bool) {
+	var size int
+	keep := 0
+	for i := range pd {
+		next := size + pd[i].OutputSize()
+		if i > 0 {
+			// Space separating this metric from the previous one.
+			next++
+		}
+
+		if next > maxBytes {
+			break
+		}
+
+		size = next
+		keep++
+	}
+
+	trimmed := make([]PerformanceData, keep)
+	copy(trimmed, pd[:keep])
+
+	return trimmed, keep < len(pd)
+}
+
+// PerfDataDebugString returns a multi-line, field labeled dump of each given
+// PerformanceData value intended for log or debug output, such as when
+// diagnosing why parsing produced unexpected fields. Field values are quoted
+// so that empty values and whitespace are visible. For example:
+//
+//	metric 0:
+//	  Label:             "load1"
+//	  Value:             "0.260"
+//	  UnitOfMeasurement: ""
+//	  Warn:              "5"
+//	  ...
+//
+// This is NOT the performance data format expected by Nagios; use String or
+// FormatPerfDataLine for plugin output.
+func PerfDataDebugString(pd []PerformanceData) string {
+	var output strings.Builder
+
+	for i := range pd {
+		fmt.Fprintf(&output, "metric %d:\n", i)
+		fmt.Fprintf(&output, "  Label:             %q\n", pd[i].Label)
+		fmt.Fprintf(&output, "  Value:             %q\n", pd[i].Value)
+		fmt.Fprintf(&output, "  UnitOfMeasurement: %q\n", pd[i].UnitOfMeasurement)
+		fmt.Fprintf(&output, "  Warn:              %q\n", pd[i].Warn)
+		fmt.Fprintf(&output, "  Crit:              %q\n", pd[i].Crit)
+		fmt.Fprintf(&output, "  Min:               %q\n", pd[i].Min)
+		fmt.Fprintf(&output, "  Max:               %q\n", pd[i].Max)
+		fmt.Fprintf(&output, "  Values:            %q\n", pd[i].Values)
+		fmt.Fprintf(&output, "  Raw:               %q\n", pd[i].Raw())
+	}
+
+	return output.String()
+}
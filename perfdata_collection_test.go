@@ -0,0 +1,648 @@
+// Copyright 2023 Adam Chalkley
+//
+// https://github.com/atc0005/go-nagios
+//
+// Licensed under the MIT License. See LICENSE file in the project root for
+// full license information.
+
+package nagios_test
+
+import (
+	"errors"
+	"path"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/atc0005/go-nagios"
+	"github.com/google/go-cmp/cmp"
+)
+
+// TestMergePerfData asserts that collections of performance data are merged
+// as expected for each supported merge strategy.
+func TestMergePerfData(t *testing.T) {
+	t.Parallel()
+
+	first := []nagios.PerformanceData{
+		{Label: "cpu", Value: "10"},
+		{Label: "mem", Value: "20"},
+	}
+
+	second := []nagios.PerformanceData{
+		{Label: "CPU", Value: "11"},
+		{Label: "disk", Value: "30"},
+	}
+
+	tests := map[string]struct {
+		strategy nagios.MergeStrategy
+		sets     [][]nagios.PerformanceData
+		want     []nagios.PerformanceData
+		wantErr  error
+	}{
+		"error strategy with duplicate": {
+			strategy: nagios.MergeError,
+			sets:     [][]nagios.PerformanceData{first, second},
+			wantErr:  nagios.ErrPerformanceDataDuplicateLabel,
+		},
+		"error strategy without duplicate": {
+			strategy: nagios.MergeError,
+			sets:     [][]nagios.PerformanceData{first, second[1:]},
+			want: []nagios.PerformanceData{
+				{Label: "cpu", Value: "10"},
+				{Label: "mem", Value: "20"},
+				{Label: "disk", Value: "30"},
+			},
+		},
+		"first wins": {
+			strategy: nagios.MergeFirstWins,
+			sets:     [][]nagios.PerformanceData{first, second},
+			want: []nagios.PerformanceData{
+				{Label: "cpu", Value: "10"},
+				{Label: "mem", Value: "20"},
+				{Label: "disk", Value: "30"},
+			},
+		},
+		"last wins": {
+			strategy: nagios.MergeLastWins,
+			sets:     [][]nagios.PerformanceData{first, second},
+			want: []nagios.PerformanceData{
+				{Label: "CPU", Value: "11"},
+				{Label: "mem", Value: "20"},
+				{Label: "disk", Value: "30"},
+			},
+		},
+		"no sets": {
+			strategy: nagios.MergeFirstWins,
+			sets:     nil,
+			want:     []nagios.PerformanceData{},
+		},
+		"empty sets": {
+			strategy: nagios.MergeError,
+			sets:     [][]nagios.PerformanceData{{}, nil},
+			want:     []nagios.PerformanceData{},
+		},
+	}
+
+	for name, tt := range tests {
+		tt := tt
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := nagios.MergePerfData(tt.strategy, tt.sets...)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("\nwant error %v\ngot %v", tt.wantErr, err)
+			}
+
+			if d := cmp.Diff(tt.want, got); d != "" {
+				t.Errorf("(-want, +got)\n:%s", d)
+			}
+		})
+	}
+}
+
+// TestDiffPerfData asserts that differences between two collections of
+// performance data are reported as expected.
+func TestDiffPerfData(t *testing.T) {
+	t.Parallel()
+
+	before := []nagios.PerformanceData{
+		{Label: "cpu", Value: "10", Warn: "80", Crit: "90"},
+		{Label: "mem", Value: "20", Warn: "80", Crit: "90"},
+		{Label: "swap", Value: "0"},
+		{Label: "time", Value: "49", UnitOfMeasurement: "ms"},
+	}
+
+	after := []nagios.PerformanceData{
+		{Label: "cpu", Value: "15", Warn: "80", Crit: "90"},
+		{Label: "mem", Value: "20", Warn: "70", Crit: "90"},
+		{Label: "disk", Value: "30"},
+		{Label: "time", Value: "49.0", UnitOfMeasurement: "ms"},
+	}
+
+	want := nagios.PerfDataDiff{
+		Added:   []string{"disk"},
+		Removed: []string{"swap"},
+		Changed: map[string]nagios.PerfDataChange{
+			"cpu": {Old: before[0], New: after[0]},
+			"mem": {Old: before[1], New: after[1]},
+		},
+	}
+
+	got := nagios.DiffPerfData(before, after)
+	if d := cmp.Diff(want, got); d != "" {
+		t.Errorf("(-want, +got)\n:%s", d)
+	}
+
+	noChanges := nagios.DiffPerfData(before, before)
+	if len(noChanges.Added) != 0 || len(noChanges.Removed) != 0 || len(noChanges.Changed) != 0 {
+		t.Errorf("want no differences for identical collections, got %+v", noChanges)
+	}
+}
+
+// TestValidatePerfDataSlice asserts that all validation failures in a
+// collection of performance data are reported with index and label context.
+func TestValidatePerfDataSlice(t *testing.T) {
+	t.Parallel()
+
+	t.Run("empty collection", func(t *testing.T) {
+		t.Parallel()
+
+		if err := nagios.ValidatePerfDataSlice(nil); err != nil {
+			t.Errorf("want nil error for empty collection, got %v", err)
+		}
+	})
+
+	t.Run("valid collection", func(t *testing.T) {
+		t.Parallel()
+
+		perfData := []nagios.PerformanceData{
+			{Label: "cpu", Value: "10"},
+			{Label: "time", Value: "49", UnitOfMeasurement: "ms"},
+		}
+
+		if err := nagios.ValidatePerfDataSlice(perfData); err != nil {
+			t.Errorf("want nil error for valid collection, got %v", err)
+		}
+	})
+
+	t.Run("multiple invalid metrics", func(t *testing.T) {
+		t.Parallel()
+
+		perfData := []nagios.PerformanceData{
+			{Label: "cpu", Value: "10"},
+			{Label: "mem"},
+			{Label: "disk", Value: "30", Warn: "80%"},
+		}
+
+		err := nagios.ValidatePerfDataSlice(perfData)
+		if err == nil {
+			t.Fatal("want error for invalid collection, got nil")
+		}
+
+		if !errors.Is(err, nagios.ErrPerformanceDataMissingValue) {
+			t.Errorf("want error wrapping %v, got %v", nagios.ErrPerformanceDataMissingValue, err)
+		}
+
+		if !errors.Is(err, nagios.ErrInvalidPerformanceDataFormat) {
+			t.Errorf("want error wrapping %v, got %v", nagios.ErrInvalidPerformanceDataFormat, err)
+		}
+
+		for _, want := range []string{`metric 1 ("mem")`, `metric 2 ("disk")`} {
+			if !strings.Contains(err.Error(), want) {
+				t.Errorf("want error containing %q, got %v", want, err)
+			}
+		}
+
+		if strings.Contains(err.Error(), `metric 0`) {
+			t.Errorf("want no error for valid metric 0, got %v", err)
+		}
+	})
+}
+
+// TestConcurrentPerfData asserts that performance data may be added from
+// multiple goroutines and that snapshots are defensive copies. Run with the
+// race detector enabled to detect unsynchronized access.
+func TestConcurrentPerfData(t *testing.T) {
+	t.Parallel()
+
+	const (
+		workers          = 10
+		metricsPerWorker = 100
+	)
+
+	var collector nagios.ConcurrentPerfData
+	var wg sync.WaitGroup
+
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func(worker int) {
+			defer wg.Done()
+
+			for i := 0; i < metricsPerWorker; i++ {
+				collector.Add(nagios.PerformanceData{
+					Label: "worker" + strconv.Itoa(worker) + "_metric" + strconv.Itoa(i),
+					Value: strconv.Itoa(i),
+				})
+
+				// Take snapshots concurrently with writes.
+				_ = collector.Snapshot()
+			}
+		}(w)
+	}
+
+	wg.Wait()
+
+	snapshot := collector.Snapshot()
+	if got, want := len(snapshot), workers*metricsPerWorker; got != want {
+		t.Fatalf("\nwant %d metrics\ngot %d", want, got)
+	}
+
+	snapshot[0].Label = "modified"
+	if collector.Snapshot()[0].Label == "modified" {
+		t.Error("want Snapshot to return a defensive copy")
+	}
+}
+
+// TestCheckRRDLabelCollisions asserts that labels sharing the same first 19
+// characters are reported.
+func TestCheckRRDLabelCollisions(t *testing.T) {
+	t.Parallel()
+
+	perfData := []nagios.PerformanceData{
+		{Label: "interface_eth0_bytes_received", Value: "1"},
+		{Label: "interface_eth0_bytes_sent", Value: "2"},
+		{Label: "interface_eth1_bytes_sent", Value: "3"},
+		{Label: "load1", Value: "4"},
+		{Label: "load15", Value: "5"},
+	}
+
+	want := []string{
+		`labels "interface_eth0_bytes_received" and "interface_eth0_bytes_sent" share RRD significant prefix "interface_eth0_byte"`,
+	}
+
+	got := nagios.CheckRRDLabelCollisions(perfData)
+	if d := cmp.Diff(want, got); d != "" {
+		t.Errorf("(-want, +got)\n:%s", d)
+	}
+
+	if got := nagios.CheckRRDLabelCollisions(perfData[2:]); len(got) != 0 {
+		t.Errorf("want no collisions, got %q", got)
+	}
+}
+
+// TestWorstState asserts that the most severe state across a collection of
+// performance data metrics is returned along with the responsible label.
+func TestWorstState(t *testing.T) {
+	t.Parallel()
+
+	ok := nagios.PerformanceData{Label: "ok", Value: "1", Warn: "5", Crit: "10"}
+	warning := nagios.PerformanceData{Label: "warning", Value: "6", Warn: "5", Crit: "10"}
+	critical := nagios.PerformanceData{Label: "critical", Value: "11", Warn: "5", Crit: "10"}
+	unknown := nagios.PerformanceData{Label: "unknown", Value: "U", Warn: "5", Crit: "10"}
+	invalid := nagios.PerformanceData{Label: "invalid", Value: "1", Warn: "@@"}
+
+	tests := map[string]struct {
+		pd        []nagios.PerformanceData
+		wantState int
+		wantLabel string
+		wantErr   bool
+	}{
+		"empty collection":      {pd: nil, wantState: nagios.StateOKExitCode, wantLabel: ""},
+		"all ok":                {pd: []nagios.PerformanceData{ok, ok}, wantState: nagios.StateOKExitCode, wantLabel: ""},
+		"unknown worse than ok": {pd: []nagios.PerformanceData{ok, unknown}, wantState: nagios.StateUNKNOWNExitCode, wantLabel: "unknown"},
+		"warning worse than unknown": {
+			pd:        []nagios.PerformanceData{unknown, warning, ok},
+			wantState: nagios.StateWARNINGExitCode,
+			wantLabel: "warning",
+		},
+		"critical worse than all": {
+			pd:        []nagios.PerformanceData{warning, critical, unknown, ok},
+			wantState: nagios.StateCRITICALExitCode,
+			wantLabel: "critical",
+		},
+		"first of equally severe metrics": {
+			pd: []nagios.PerformanceData{
+				warning,
+				{Label: "warning2", Value: "7", Warn: "5"},
+			},
+			wantState: nagios.StateWARNINGExitCode,
+			wantLabel: "warning",
+		},
+		"invalid metric": {
+			pd:        []nagios.PerformanceData{critical, invalid},
+			wantState: nagios.StateUNKNOWNExitCode,
+			wantLabel: "invalid",
+			wantErr:   true,
+		},
+	}
+
+	for name, tt := range tests {
+		tt := tt
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			gotState, gotLabel, err := nagios.WorstState(tt.pd)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("nagios.WorstState() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if gotState != tt.wantState || gotLabel != tt.wantLabel {
+				t.Errorf(
+					"\nwant %s (%q)\ngot %s (%q)",
+					nagios.ExitCodeToStateLabel(tt.wantState), tt.wantLabel,
+					nagios.ExitCodeToStateLabel(gotState), gotLabel,
+				)
+			}
+		})
+	}
+}
+
+// TestPerfDataByLabel asserts that label order is preserved, that metrics are
+// retrievable by label and that duplicate labels are rejected.
+func TestPerfDataByLabel(t *testing.T) {
+	t.Parallel()
+
+	perfData := []nagios.PerformanceData{
+		{Label: "load5", Value: "0.320"},
+		{Label: "load1", Value: "0.260"},
+		{Label: "load15", Value: "0.300"},
+	}
+
+	labels, byLabel, err := nagios.PerfDataByLabel(perfData)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if d := cmp.Diff([]string{"load5", "load1", "load15"}, labels); d != "" {
+		t.Errorf("(-want, +got)\n:%s", d)
+	}
+
+	for i, label := range labels {
+		if d := cmp.Diff(perfData[i], byLabel[label]); d != "" {
+			t.Errorf("(-want, +got)\n:%s", d)
+		}
+	}
+
+	perfData = append(perfData, nagios.PerformanceData{Label: "load1", Value: "1"})
+	if _, _, err := nagios.PerfDataByLabel(perfData); !errors.Is(err, nagios.ErrPerformanceDataDuplicateLabel) {
+		t.Errorf("\nwant error %v\ngot %v", nagios.ErrPerformanceDataDuplicateLabel, err)
+	}
+}
+
+// TestFilterPerfData asserts that performance data is filtered by label
+// using shell patterns.
+func TestFilterPerfData(t *testing.T) {
+	t.Parallel()
+
+	perfData := []nagios.PerformanceData{
+		{Label: "cpu0", Value: "10"},
+		{Label: "mem", Value: "20"},
+		{Label: "cpu1", Value: "30"},
+		{Label: "/var", Value: "40"},
+		{Label: "/var/log", Value: "50"},
+	}
+
+	tests := map[string]struct {
+		pattern string
+		want    []string
+	}{
+		"match all":             {pattern: "*", want: []string{"cpu0", "mem", "cpu1"}},
+		"prefix glob":           {pattern: "cpu*", want: []string{"cpu0", "cpu1"}},
+		"single character":      {pattern: "cpu?", want: []string{"cpu0", "cpu1"}},
+		"character class":       {pattern: "cpu[1-9]", want: []string{"cpu1"}},
+		"path component":        {pattern: "/*", want: []string{"/var"}},
+		"nested path component": {pattern: "/var/*", want: []string{"/var/log"}},
+		"exact match":           {pattern: "mem", want: []string{"mem"}},
+		"no match":              {pattern: "disk*", want: []string{}},
+	}
+
+	for name, tt := range tests {
+		tt := tt
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			filtered, err := nagios.FilterPerfData(perfData, tt.pattern)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if filtered == nil {
+				t.Fatal("want non-nil result, got nil")
+			}
+
+			got := make([]string, 0, len(filtered))
+			for i := range filtered {
+				got = append(got, filtered[i].Label)
+			}
+
+			if d := cmp.Diff(tt.want, got); d != "" {
+				t.Errorf("(-want, +got)\n:%s", d)
+			}
+		})
+	}
+
+	for _, pd := range [][]nagios.PerformanceData{perfData, nil} {
+		if _, err := nagios.FilterPerfData(pd, "cpu["); !errors.Is(err, path.ErrBadPattern) {
+			t.Errorf("\nwant error %v\ngot %v", path.ErrBadPattern, err)
+		}
+	}
+}
+
+// TestDropZeroValues asserts that metrics with zero values are removed while
+// undetermined and non-zero values are retained in order.
+func TestDropZeroValues(t *testing.T) {
+	t.Parallel()
+
+	perfData := []nagios.PerformanceData{
+		{Label: "errors", Value: "0"},
+		{Label: "load", Value: "0.260"},
+		{Label: "ratio", Value: "U"},
+		{Label: "drops", Value: "0.0", UnitOfMeasurement: "c"},
+		{Label: "temp", Value: "-5"},
+	}
+
+	want := []nagios.PerformanceData{
+		{Label: "load", Value: "0.260"},
+		{Label: "ratio", Value: "U"},
+		{Label: "temp", Value: "-5"},
+	}
+
+	if d := cmp.Diff(want, nagios.DropZeroValues(perfData)); d != "" {
+		t.Errorf("(-want, +got)\n:%s", d)
+	}
+
+	if got := nagios.DropZeroValues(perfData[:1]); got == nil || len(got) != 0 {
+		t.Errorf("want empty non-nil result, got %#v", got)
+	}
+}
+
+// TestGroupPerfDataByUoM asserts that performance data is grouped by the
+// canonical Unit of Measurement with unitless metrics under the empty key.
+func TestGroupPerfDataByUoM(t *testing.T) {
+	t.Parallel()
+
+	perfData := []nagios.PerformanceData{
+		{Label: "time", Value: "49", UnitOfMeasurement: "ms"},
+		{Label: "load1", Value: "0.260"},
+		{Label: "shm", Value: "2", UnitOfMeasurement: "KB"},
+		{Label: "tmp", Value: "4", UnitOfMeasurement: "kb"},
+		{Label: "load5", Value: "0.320"},
+		{Label: "temp", Value: "21.5", UnitOfMeasurement: "C"},
+	}
+
+	want := map[string][]nagios.PerformanceData{
+		"ms": {perfData[0]},
+		"":   {perfData[1], perfData[4]},
+		"KB": {perfData[2], perfData[3]},
+		"C":  {perfData[5]},
+	}
+
+	got := nagios.GroupPerfDataByUoM(perfData)
+	if d := cmp.Diff(want, got); d != "" {
+		t.Errorf("(-want, +got)\n:%s", d)
+	}
+
+	if got["KB"][1].UnitOfMeasurement != "kb" {
+		t.Errorf("want original unit %q retained, got %q", "kb", got["KB"][1].UnitOfMeasurement)
+	}
+
+	if got := nagios.GroupPerfDataByUoM(nil); len(got) != 0 {
+		t.Errorf("want no groups for empty input, got %v", got)
+	}
+}
+
+// TestSumPerfData asserts that values are summed for metrics matching the
+// given Unit of Measurement filter.
+func TestSumPerfData(t *testing.T) {
+	t.Parallel()
+
+	perfData := []nagios.PerformanceData{
+		{Label: "eth0_rx", Value: "1024", UnitOfMeasurement: "B"},
+		{Label: "eth1_rx", Value: "2048.5", UnitOfMeasurement: "B"},
+		{Label: "eth2_rx", Value: "U", UnitOfMeasurement: "B"},
+		{Label: "time", Value: "49", UnitOfMeasurement: "ms"},
+		{Label: "shm", Value: "2", UnitOfMeasurement: "KB"},
+		{Label: "tmp", Value: "3", UnitOfMeasurement: "kb"},
+		{Label: "users", Value: "7"},
+	}
+
+	tests := map[string]struct {
+		filter string
+		want   float64
+	}{
+		"bytes":                 {filter: "B", want: 3072.5},
+		"milliseconds":          {filter: "ms", want: 49},
+		"kilobytes case":        {filter: "kb", want: 5},
+		"no matches":            {filter: "%", want: 0},
+		"empty filter sums all": {filter: "", want: 3072.5 + 49 + 5 + 7},
+	}
+
+	for name, tt := range tests {
+		tt := tt
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := nagios.SumPerfData(perfData, tt.filter)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if got != tt.want {
+				t.Errorf("\nwant %v\ngot %v", tt.want, got)
+			}
+		})
+	}
+
+	invalid := append([]nagios.PerformanceData{{Label: "bad", Value: "abc", UnitOfMeasurement: "B"}}, perfData...)
+
+	if _, err := nagios.SumPerfData(invalid, "B"); !errors.Is(err, nagios.ErrInvalidPerformanceDataFormat) {
+		t.Errorf("\nwant error %v\ngot %v", nagios.ErrInvalidPerformanceDataFormat, err)
+	}
+
+	if _, err := nagios.SumPerfData(invalid, "ms"); err != nil {
+		t.Errorf("unexpected error for non-matching invalid value: %v", err)
+	}
+}
+
+// TestTrimToSize asserts that trailing metrics are dropped until the emitted
+// performance data fits within the given size.
+func TestTrimToSize(t *testing.T) {
+	t.Parallel()
+
+	perfData := []nagios.PerformanceData{
+		{Label: "load1", Value: "0.260"},
+		{Label: "load5", Value: "0.320"},
+		{Label: "load15", Value: "0.300"},
+	}
+
+	total := nagios.TotalOutputSize(perfData)
+	firstTwo := nagios.TotalOutputSize(perfData[:2])
+
+	tests := map[string]struct {
+		maxBytes    int
+		wantLabels  []string
+		wantDropped bool
+	}{
+		"fits exactly":      {maxBytes: total, wantLabels: []string{"load1", "load5", "load15"}},
+		"fits with room":    {maxBytes: 1024, wantLabels: []string{"load1", "load5", "load15"}},
+		"one byte short":    {maxBytes: total - 1, wantLabels: []string{"load1", "load5"}, wantDropped: true},
+		"first two exactly": {maxBytes: firstTwo, wantLabels: []string{"load1", "load5"}, wantDropped: true},
+		"nothing fits":      {maxBytes: 5, wantLabels: []string{}, wantDropped: true},
+		"zero budget":       {maxBytes: 0, wantLabels: []string{}, wantDropped: true},
+	}
+
+	for name, tt := range tests {
+		tt := tt
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			trimmed, dropped := nagios.TrimToSize(perfData, tt.maxBytes)
+			if dropped != tt.wantDropped {
+				t.Errorf("\nwant dropped %t\ngot %t", tt.wantDropped, dropped)
+			}
+
+			labels := make([]string, 0, len(trimmed))
+			for i := range trimmed {
+				labels = append(labels, trimmed[i].Label)
+			}
+
+			if d := cmp.Diff(tt.wantLabels, labels); d != "" {
+				t.Errorf("(-want, +got)\n:%s", d)
+			}
+
+			if size := len(nagios.FormatPerfDataLine(trimmed)); size > tt.maxBytes {
+				t.Errorf("trimmed output size %d exceeds %d", size, tt.maxBytes)
+			}
+		})
+	}
+
+	if trimmed, dropped := nagios.TrimToSize(nil, 10); len(trimmed) != 0 || dropped {
+		t.Errorf("want empty result without drops for empty input, got %v, %t", trimmed, dropped)
+	}
+}
+
+// TestPerfDataDebugString asserts that each field of each metric is labeled
+// in the debug output.
+func TestPerfDataDebugString(t *testing.T) {
+	t.Parallel()
+
+	perfData, err := nagios.ParsePerfDataWithOptions(
+		"temp=20,21,22C;25;30;0;100 load1=0.260",
+		nagios.PerfDataParseOptions{AllowValueList: true},
+	)
+	if err != nil {
+		t.Fatalf("failed to parse performance data: %v", err)
+	}
+
+	got := nagios.PerfDataDebugString(perfData)
+
+	for _, want := range []string{
+		"metric 0:\n",
+		`Label:             "temp"`,
+		`Value:             "20"`,
+		`UnitOfMeasurement: "C"`,
+		`Warn:              "25"`,
+		`Crit:              "30"`,
+		`Min:               "0"`,
+		`Max:               "100"`,
+		`Values:            ["20" "21" "22"]`,
+		`Raw:               "temp=20,21,22C;25;30;0;100"`,
+		"metric 1:\n",
+		`Label:             "load1"`,
+		`Warn:              ""`,
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("debug output missing %q:\n%s", want, got)
+		}
+	}
+
+	if got := nagios.PerfDataDebugString(nil); got != "" {
+		t.Errorf("want empty output for empty input, got %q", got)
+	}
+}
@@ -0,0 +1,337 @@
+// Copyright 2023 Adam Chalkley
+//
+// https://github.com/atc0005/go-nagios
+//
+// Licensed under the MIT License. See LICENSE file in the project root for
+// full license information.
+
+package nagios
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// metricNameRe is the compiled form of metricNameRegex.
+var metricNameRe = regexp.MustCompile(metricNameRegex)
+
+const (
+	// metricNameRegex represents the regex used to validate a Prometheus /
+	// OpenMetrics metric name.
+	metricNameRegex string = `^[a-zA-Z_:][a-zA-Z0-9_:]*$`
+
+	// openMetricsEOF is the marker used to indicate the end of an
+	// OpenMetrics exposition.
+	openMetricsEOF string = "# EOF"
+)
+
+// openMetricsUnit describes how a performance data Unit of Measurement is
+// represented in OpenMetrics output. Values are converted to the base unit
+// by multiplying by mul and dividing by div.
+type openMetricsUnit struct {
+	unit string
+	mul  float64
+	div  float64
+}
+
+// openMetricsUnits maps recognized performance data Units of Measurement to
+// OpenMetrics base units. Multiples of bytes use powers of 1024.
+var openMetricsUnits = map[string]openMetricsUnit{
+	"s":  {unit: "seconds", mul: 1, div: 1},
+	"ms": {unit: "seconds", mul: 1, div: 1e3},
+	"us": {unit: "seconds", mul: 1, div: 1e6},
+	"%":  {unit: "percent", mul: 1, div: 1},
+	"B":  {unit: "bytes", mul: 1, div: 1},
+	"KB": {unit: "bytes", mul: 1 << 10, div: 1},
+	"MB": {unit: "bytes", mul: 1 << 20, div: 1},
+	"GB": {unit: "bytes", mul: 1 << 30, div: 1},
+	"TB": {unit: "bytes", mul: 1 << 40, div: 1},
+}
+
+// PerfDataToOpenMetrics converts the given collection of PerformanceData
+// values to the [OpenMetrics] text exposition format. Each metric is emitted
+// as a separate metric family named using the given (optional) prefix and
+// the sanitized metric label.
+//
+// Recognized Units of Measurement are converted to base units (e.g., "ms" to
+// seconds, "KB" to bytes) and emitted as "# UNIT" metadata with the unit
+// appended to the metric family name as required by the specification.
+// Metrics using the "c" (continuous counter) Unit of Measurement are emitted
+// as counters, all others as gauges. If an entry for the original metric
+// label is present in the given help map it is emitted as "# HELP" metadata.
+//
+// Metrics with a "U" (undetermined) Value are skipped. An error is returned
+// if a metric value is not numeric, if a valid metric name cannot be
+// generated or if multiple metrics result in the same metric name.
+//
+// [OpenMetrics]: https://github.com/OpenObservability/OpenMetrics/blob/main/specification/OpenMetrics.md
+func PerfDataToOpenMetrics(pd []PerformanceData, prefix string, help map[string]string) (string, error) {
+	var output strings.Builder
+
+	seen := make(map[string]struct{}, len(pd))
+
+	for i := range pd {
+		if pd[i].Value == "U" {
+			continue
+		}
+
+		value, err := strconv.ParseFloat(pd[i].Value, 64)
+		if err != nil {
+			return "", fmt.Errorf(
+				"failed to convert value %q of metric %q: %w",
+				pd[i].Value,
+				pd[i].Label,
+				ErrInvalidPerformanceDataFormat,
+			)
+		}
+
+		name := sanitizeMetricName(pd[i].Label)
+		if prefix != "" {
+			name = sanitizeMetricName(prefix + "_" + name)
+		}
+
+		metricType := "gauge"
+		sampleName := name
+
+		unit, hasUnit := openMetricsUnits[pd[i].UnitOfMeasurement]
+		switch {
+		case hasUnit:
+			name += "_" + unit.unit
+			sampleName = name
+			value = value * unit.mul / unit.div
+
+		case pd[i].UnitOfMeasurement == "c":
+			metricType = "counter"
+			sampleName = name + "_total"
+		}
+
+		if !metricNameRe.MatchString(name) {
+			return "", fmt.Errorf(
+				"failed to generate valid metric name from label %q and prefix %q: %w",
+				pd[i].Label,
+				prefix,
+				ErrInvalidPerformanceDataFormat,
+			)
+		}
+
+		if _, exists := seen[name]; exists {
+			return "", fmt.Errorf(
+				"metric label %q results in duplicate metric name %q: %w",
+				pd[i].Label,
+				name,
+				ErrInvalidPerformanceDataFormat,
+			)
+		}
+		seen[name] = struct{}{}
+
+		fmt.Fprintf(&output, "# TYPE %s %s\n", name, metricType)
+
+		if hasUnit {
+			fmt.Fprintf(&output, "# UNIT %s %s\n", name, unit.unit)
+		}
+
+		if helpText, ok := help[pd[i].Label]; ok {
+			fmt.Fprintf(&output, "# HELP %s %s\n", name, escapeOpenMetricsHelp(helpText))
+		}
+
+		fmt.Fprintf(&output, "%s %s\n", sampleName, strconv.FormatFloat(value, 'g', -1, 64))
+	}
+
+	fmt.Fprintf(&output, "%s\n", openMetricsEOF)
+
+	return output.String(), nil
+}
+
+// ValidForPrometheus asserts that the performance data label can be
+// converted (using the same sanitization as PerfDataToOpenMetrics without a
+// prefix) to a valid Prometheus / OpenMetrics metric name. This allows
+// checking export compatibility without running the full exporter.
+//
+// This is stricter than the Nagios label rules: a label such as "1cpu" is a
+// valid Nagios label but results in a metric name beginning with a digit
+// which Prometheus (and some other time series databases) reject. An error
+// wrapping ErrPerformanceDataPrometheusIncompatible is returned if the
+// sanitized label begins with a digit or is empty.
+func (pd PerformanceData) ValidForPrometheus() error {
+	name := sanitizeMetricName(pd.Label)
+
+	switch {
+	case name == "":
+		return fmt.Errorf(
+			"label %q is empty after sanitization: %w",
+			pd.Label,
+			ErrPerformanceDataPrometheusIncompatible,
+		)
+
+	case !metricNameRe.MatchString(name):
+		return fmt.Errorf(
+			"label %q results in metric name %q which does not begin with a letter, underscore or colon: %w",
+			pd.Label,
+			name,
+			ErrPerformanceDataPrometheusIncompatible,
+		)
+	}
+
+	return nil
+}
+
+// perfDataCSVHeader is the header row emitted by PerfDataToCSV.
+var perfDataCSVHeader = []string{"label", "value", "uom", "warn", "crit", "min", "max"}
+
+// PerfDataToCSV writes the given collection of PerformanceData values to w in
+// CSV format. A header row (label, value, uom, warn, crit, min, max) is
+// written first followed by one row per metric. Fields are quoted as needed
+// (e.g., a label containing a comma). An error is returned if writing fails.
+func PerfDataToCSV(w io.Writer, pd []PerformanceData) error {
+	csvWriter := csv.NewWriter(w)
+
+	if err := csvWriter.Write(perfDataCSVHeader); err != nil {
+		return fmt.Errorf("failed to write CSV header: %w", err)
+	}
+
+	for i := range pd {
+		record := []string{
+			pd[i].Label,
+			pd[i].Value,
+			pd[i].UnitOfMeasurement,
+			pd[i].Warn,
+			pd[i].Crit,
+			pd[i].Min,
+			pd[i].Max,
+		}
+
+		if err := csvWriter.Write(record); err != nil {
+			return fmt.Errorf("failed to write CSV record for metric %q: %w", pd[i].Label, err)
+		}
+	}
+
+	csvWriter.Flush()
+
+	if err := csvWriter.Error(); err != nil {
+		return fmt.Errorf("failed to flush CSV output: %w", err)
+	}
+
+	return nil
+}
+
+// PerfDataToGraphite converts the given collection of PerformanceData values
+// to the Graphite plaintext protocol format, one line per metric:
+//
+//	prefix.label value timestamp
+//
+// Labels are sanitized for use as a single Graphite path component (see
+// sanitizeGraphitePathComponent). The (optional) prefix is used as-is and
+// may contain multiple dot separated path components. The timestamp is
+// emitted as Unix seconds.
+//
+// Metrics with a "U" (undetermined) Value are skipped. An error is returned
+// if a metric value is not numeric or if a label is empty after
+// sanitization.
+func PerfDataToGraphite(prefix string, pd []PerformanceData, ts time.Time) (string, error) {
+	var output strings.Builder
+
+	prefix = strings.Trim(prefix, ".")
+	timestamp := ts.Unix()
+
+	for i := range pd {
+		if pd[i].IsValueUndetermined() {
+			continue
+		}
+
+		value, err := strconv.ParseFloat(strings.TrimSpace(pd[i].Value), 64)
+		if err != nil {
+			return "", fmt.Errorf(
+				"failed to convert value %q of metric %q: %w",
+				pd[i].Value,
+				pd[i].Label,
+				ErrInvalidPerformanceDataFormat,
+			)
+		}
+
+		name := sanitizeGraphitePathComponent(pd[i].Label)
+		if name == "" {
+			return "", fmt.Errorf(
+				"failed to generate valid metric path from label %q: %w",
+				pd[i].Label,
+				ErrInvalidPerformanceDataFormat,
+			)
+		}
+
+		if prefix != "" {
+			name = prefix + "." + name
+		}
+
+		fmt.Fprintf(
+			&output,
+			"%s %s %d\n",
+			name,
+			strconv.FormatFloat(value, 'f', -1, 64),
+			timestamp,
+		)
+	}
+
+	return output.String(), nil
+}
+
+// sanitizeGraphitePathComponent converts the given performance data label
+// into a form suitable for use as a single Graphite metric path component.
+// Characters other than letters, digits, hyphens and underscores (including
+// spaces and periods) are replaced with underscores, consecutive underscores
+// are collapsed and leading/trailing underscores are removed. The result may
+// be empty.
+func sanitizeGraphitePathComponent(label string) string {
+	var sanitized strings.Builder
+
+	lastWasUnderscore := false
+	for _, r := range label {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '-':
+			sanitized.WriteRune(r)
+			lastWasUnderscore = false
+
+		case !lastWasUnderscore:
+			sanitized.WriteRune('_')
+			lastWasUnderscore = true
+		}
+	}
+
+	return strings.Trim(sanitized.String(), "_")
+}
+
+// sanitizeMetricName converts the given performance data label into a form
+// suitable for use as a Prometheus / OpenMetrics metric name. Characters
+// outside of the permitted set are replaced with underscores, consecutive
+// underscores are collapsed and leading/trailing underscores are removed.
+//
+// NOTE: The result may still begin with a digit (invalid) or be empty; the
+// caller is responsible for handling those cases.
+func sanitizeMetricName(label string) string {
+	var sanitized strings.Builder
+
+	lastWasUnderscore := false
+	for _, r := range label {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == ':':
+			sanitized.WriteRune(r)
+			lastWasUnderscore = false
+
+		case !lastWasUnderscore:
+			sanitized.WriteRune('_')
+			lastWasUnderscore = true
+		}
+	}
+
+	return strings.Trim(sanitized.String(), "_")
+}
+
+// escapeOpenMetricsHelp escapes the given text for use as the value of a
+// "# HELP" metadata line.
+func escapeOpenMetricsHelp(text string) string {
+	replacer := strings.NewReplacer(`\`, `\\`, "\n", `\n`, `"`, `\"`)
+	return replacer.Replace(text)
+}
@@ -0,0 +1,290 @@
+// Copyright 2023 Adam Chalkley
+//
+// https://github.com/atc0005/go-nagios
+//
+// Licensed under the MIT License. See LICENSE file in the project root for
+// full license information.
+
+package nagios_test
+
+import (
+	"encoding/csv"
+	"errors"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/atc0005/go-nagios"
+	"github.com/google/go-cmp/cmp"
+)
+
+// TestPerfDataToOpenMetrics asserts that performance data is converted to
+// the expected OpenMetrics text exposition format.
+func TestPerfDataToOpenMetrics(t *testing.T) {
+	t.Parallel()
+
+	perfData := []nagios.PerformanceData{
+		{Label: "time", Value: "49", UnitOfMeasurement: "ms"},
+		{Label: "/dev/shm", Value: "2", UnitOfMeasurement: "KB"},
+		{Label: "load 1", Value: "0.260"},
+		{Label: "packets", Value: "1024", UnitOfMeasurement: "c"},
+		{Label: "unknown_value", Value: "U"},
+	}
+
+	help := map[string]string{
+		"time":    "Plugin runtime",
+		"load 1":  "Load average\nover 1 minute",
+		"missing": "not used",
+	}
+
+	got, err := nagios.PerfDataToOpenMetrics(perfData, "nagios", help)
+	if err != nil {
+		t.Fatalf("failed to convert performance data: %v", err)
+	}
+
+	want := strings.Join([]string{
+		"# TYPE nagios_time_seconds gauge",
+		"# UNIT nagios_time_seconds seconds",
+		"# HELP nagios_time_seconds Plugin runtime",
+		"nagios_time_seconds 0.049",
+		"# TYPE nagios_dev_shm_bytes gauge",
+		"# UNIT nagios_dev_shm_bytes bytes",
+		"nagios_dev_shm_bytes 2048",
+		"# TYPE nagios_load_1 gauge",
+		`# HELP nagios_load_1 Load average\nover 1 minute`,
+		"nagios_load_1 0.26",
+		"# TYPE nagios_packets counter",
+		"nagios_packets_total 1024",
+		"# EOF",
+		"",
+	}, "\n")
+
+	if d := cmp.Diff(want, got); d != "" {
+		t.Errorf("(-want, +got)\n:%s", d)
+	}
+
+	assertValidOpenMetrics(t, got)
+}
+
+// TestPerfDataToOpenMetricsFailsForInvalidInput asserts that invalid
+// performance data is rejected when converting to OpenMetrics format.
+func TestPerfDataToOpenMetricsFailsForInvalidInput(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		perfData []nagios.PerformanceData
+		prefix   string
+	}{
+		"non-numeric value": {
+			perfData: []nagios.PerformanceData{{Label: "time", Value: "xyz"}},
+		},
+		"label starting with digit without prefix": {
+			perfData: []nagios.PerformanceData{{Label: "1cpu", Value: "1"}},
+		},
+		"label empty after sanitization": {
+			perfData: []nagios.PerformanceData{{Label: "///", Value: "1"}},
+		},
+		"duplicate metric names after sanitization": {
+			perfData: []nagios.PerformanceData{
+				{Label: "load 1", Value: "1"},
+				{Label: "load_1", Value: "1"},
+			},
+			prefix: "nagios",
+		},
+	}
+
+	for name, tt := range tests {
+		tt := tt
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			if _, err := nagios.PerfDataToOpenMetrics(tt.perfData, tt.prefix, nil); err == nil {
+				t.Error("want error for invalid input, got nil")
+			}
+		})
+	}
+}
+
+// TestPerformanceDataValidForPrometheus asserts that labels which cannot be
+// converted to valid Prometheus metric names are rejected.
+func TestPerformanceDataValidForPrometheus(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		label   string
+		wantErr bool
+	}{
+		"leading digit":         {label: "1cpu", wantErr: true},
+		"trailing digit":        {label: "cpu1", wantErr: false},
+		"path":                  {label: "/dev/shm", wantErr: false},
+		"leading digit in path": {label: "/1cpu", wantErr: true},
+		"with spaces":           {label: "load 1", wantErr: false},
+		"empty after sanitize":  {label: "///", wantErr: true},
+	}
+
+	for name, tt := range tests {
+		tt := tt
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			pd := nagios.PerformanceData{Label: tt.label, Value: "1"}
+
+			err := pd.ValidForPrometheus()
+			switch {
+			case tt.wantErr && !errors.Is(err, nagios.ErrPerformanceDataPrometheusIncompatible):
+				t.Errorf("\nwant error %v\ngot %v", nagios.ErrPerformanceDataPrometheusIncompatible, err)
+			case !tt.wantErr && err != nil:
+				t.Errorf("unexpected error for label %q: %v", tt.label, err)
+			}
+		})
+	}
+}
+
+// TestPerfDataToCSV asserts that performance data is written in CSV format
+// with fields quoted as needed and recoverable using a CSV reader.
+func TestPerfDataToCSV(t *testing.T) {
+	t.Parallel()
+
+	perfData := []nagios.PerformanceData{
+		{Label: "time", Value: "49", UnitOfMeasurement: "ms", Warn: "100", Crit: "200", Min: "0"},
+		{Label: "disk,root", Value: "80", UnitOfMeasurement: "%", Max: "100"},
+	}
+
+	var output strings.Builder
+	if err := nagios.PerfDataToCSV(&output, perfData); err != nil {
+		t.Fatalf("failed to write CSV output: %v", err)
+	}
+
+	want := strings.Join([]string{
+		"label,value,uom,warn,crit,min,max",
+		"time,49,ms,100,200,0,",
+		`"disk,root",80,%,,,,100`,
+		"",
+	}, "\n")
+
+	if d := cmp.Diff(want, output.String()); d != "" {
+		t.Errorf("(-want, +got)\n:%s", d)
+	}
+
+	records, err := csv.NewReader(strings.NewReader(output.String())).ReadAll()
+	if err != nil {
+		t.Fatalf("failed to read CSV output: %v", err)
+	}
+
+	wantRecords := [][]string{
+		{"label", "value", "uom", "warn", "crit", "min", "max"},
+		{"time", "49", "ms", "100", "200", "0", ""},
+		{"disk,root", "80", "%", "", "", "", "100"},
+	}
+
+	if d := cmp.Diff(wantRecords, records); d != "" {
+		t.Errorf("(-want, +got)\n:%s", d)
+	}
+}
+
+// TestPerfDataToGraphite asserts that performance data is converted to the
+// Graphite plaintext protocol format with sanitized labels.
+func TestPerfDataToGraphite(t *testing.T) {
+	t.Parallel()
+
+	perfData := []nagios.PerformanceData{
+		{Label: "time", Value: "49", UnitOfMeasurement: "ms"},
+		{Label: "/dev/shm", Value: "2", UnitOfMeasurement: "KB"},
+		{Label: "load 1", Value: "0.260"},
+		{Label: "eth0.rx  bytes", Value: "+1024", UnitOfMeasurement: "c"},
+		{Label: "unknown_value", Value: "U"},
+	}
+
+	ts := time.Date(2023, time.March, 1, 12, 30, 0, 0, time.UTC)
+
+	got, err := nagios.PerfDataToGraphite("nagios.host01.", perfData, ts)
+	if err != nil {
+		t.Fatalf("failed to convert performance data: %v", err)
+	}
+
+	want := strings.Join([]string{
+		"nagios.host01.time 49 1677673800",
+		"nagios.host01.dev_shm 2 1677673800",
+		"nagios.host01.load_1 0.26 1677673800",
+		"nagios.host01.eth0_rx_bytes 1024 1677673800",
+		"",
+	}, "\n")
+
+	if d := cmp.Diff(want, got); d != "" {
+		t.Errorf("(-want, +got)\n:%s", d)
+	}
+
+	got, err = nagios.PerfDataToGraphite("", perfData[:1], ts)
+	if err != nil {
+		t.Fatalf("failed to convert performance data: %v", err)
+	}
+
+	if want := "time 49 1677673800\n"; got != want {
+		t.Errorf("\nwant %q\ngot %q", want, got)
+	}
+
+	invalid := map[string][]nagios.PerformanceData{
+		"non-numeric value":              {{Label: "time", Value: "xyz"}},
+		"label empty after sanitization": {{Label: "///", Value: "1"}},
+	}
+
+	for name, pd := range invalid {
+		if _, err := nagios.PerfDataToGraphite("nagios", pd, ts); err == nil {
+			t.Errorf("%s: want error for invalid input, got nil", name)
+		}
+	}
+}
+
+// assertValidOpenMetrics performs basic validation of the given OpenMetrics
+// text exposition: metadata lines are well-formed and precede the samples of
+// their metric family, each metric family is unique and the exposition is
+// terminated by an EOF marker.
+func assertValidOpenMetrics(t *testing.T, exposition string) {
+	t.Helper()
+
+	metadataLine := regexp.MustCompile(`^# (TYPE|UNIT|HELP) ([a-zA-Z_:][a-zA-Z0-9_:]*) (.*)$`)
+	sampleLine := regexp.MustCompile(`^([a-zA-Z_:][a-zA-Z0-9_:]*) (\S+)$`)
+
+	if !strings.HasSuffix(exposition, "# EOF\n") {
+		t.Fatalf("exposition not terminated by EOF marker")
+	}
+
+	lines := strings.Split(strings.TrimSuffix(exposition, "# EOF\n"), "\n")
+	lines = lines[:len(lines)-1]
+
+	families := make(map[string]struct{})
+	var family string
+	var sampleSeen bool
+	for _, line := range lines {
+		if m := metadataLine.FindStringSubmatch(line); m != nil {
+			if m[1] == "TYPE" {
+				if _, exists := families[m[2]]; exists {
+					t.Fatalf("duplicate metric family %q", m[2])
+				}
+				families[m[2]] = struct{}{}
+				family = m[2]
+				sampleSeen = false
+				continue
+			}
+
+			if m[2] != family || sampleSeen {
+				t.Fatalf("metadata line %q out of order", line)
+			}
+
+			if m[1] == "UNIT" && !strings.HasSuffix(family, "_"+m[3]) {
+				t.Fatalf("metric family %q missing unit suffix %q", family, m[3])
+			}
+
+			continue
+		}
+
+		m := sampleLine.FindStringSubmatch(line)
+		if m == nil || !strings.HasPrefix(m[1], family) {
+			t.Fatalf("invalid sample line %q", line)
+		}
+		sampleSeen = true
+	}
+}
@@ -0,0 +1,223 @@
+// Copyright 2023 Adam Chalkley
+//
+// https://github.com/atc0005/go-nagios
+//
+// Licensed under the MIT License. See LICENSE file in the project root for
+// full license information.
+
+package nagios
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Nagios macros recognized by ParseServicePerfDataFile in a service
+// performance data file template.
+const (
+	// ServicePerfDataMacroTimeT is the macro expanded to the time of the
+	// check as a Unix timestamp.
+	ServicePerfDataMacroTimeT string = "$TIMET$"
+
+	// ServicePerfDataMacroHostName is the macro expanded to the short name
+	// of the host.
+	ServicePerfDataMacroHostName string = "$HOSTNAME$"
+
+	// ServicePerfDataMacroServiceDesc is the macro expanded to the
+	// description of the service.
+	ServicePerfDataMacroServiceDesc string = "$SERVICEDESC$"
+
+	// ServicePerfDataMacroServicePerfData is the macro expanded to the
+	// performance data emitted by the service check.
+	ServicePerfDataMacroServicePerfData string = "$SERVICEPERFDATA$"
+)
+
+// DefaultServicePerfDataFileTemplate is the default value of the
+// service_perfdata_file_template Nagios core configuration setting.
+const DefaultServicePerfDataFileTemplate string = "[SERVICEPERFDATA]\t$TIMET$\t$HOSTNAME$\t$SERVICEDESC$\t" +
+	"$SERVICEEXECUTIONTIME$\t$SERVICELATENCY$\t$SERVICEOUTPUT$\t$SERVICEPERFDATA$"
+
+// PerfDataRecord is a single entry from a Nagios service performance data
+// file.
+type PerfDataRecord struct {
+	// Timestamp is the time of the check ($TIMET$). The zero value is used
+	// if the template does not include the macro.
+	Timestamp time.Time
+
+	// HostName is the short name of the host ($HOSTNAME$).
+	HostName string
+
+	// ServiceDescription is the description of the service
+	// ($SERVICEDESC$).
+	ServiceDescription string
+
+	// PerfData is the parsed performance data ($SERVICEPERFDATA$). This is
+	// nil if the service check did not emit performance data.
+	PerfData []PerformanceData
+}
+
+// ParseServicePerfDataFile reads lines written by Nagios core to a service
+// performance data file (see the service_perfdata_file and
+// service_perfdata_file_template configuration settings) from r and parses
+// each using the given tab delimited template (e.g.,
+// DefaultServicePerfDataFileTemplate).
+//
+// Each tab delimited template field is either literal text, which must be
+// matched exactly, or text containing a single macro (e.g.,
+// "HOSTNAME::$HOSTNAME$" as used by PNP4Nagios). The $TIMET$, $HOSTNAME$,
+// $SERVICEDESC$ and $SERVICEPERFDATA$ macros are extracted; fields with any
+// other macro are ignored. The template must include the $SERVICEPERFDATA$
+// macro. Performance data is parsed using ParsePerfData. Blank lines are
+// skipped.
+//
+// As with ParsePerfDataReader, parsing continues after a malformed line; if
+// any lines fail to parse, the records for all valid lines are returned
+// along with an error aggregating the failures annotated with their
+// (1-based) line numbers. Lines not matching the template wrap
+// ErrServicePerfDataFileTemplateMismatch.
+func ParseServicePerfDataFile(r io.Reader, template string) ([]PerfDataRecord, error) {
+	templateFields := strings.Split(template, "\t")
+
+	if !strings.Contains(template, ServicePerfDataMacroServicePerfData) {
+		return nil, fmt.Errorf(
+			"template %q does not include the %s macro: %w",
+			template,
+			ServicePerfDataMacroServicePerfData,
+			ErrServicePerfDataFileTemplateMismatch,
+		)
+	}
+
+	var records []PerfDataRecord
+	var errs []error
+
+	scanner := bufio.NewScanner(r)
+
+	var lineNum int
+	for scanner.Scan() {
+		lineNum++
+
+		line := strings.TrimRight(scanner.Text(), "\r")
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+
+		record, err := parseServicePerfDataLine(line, templateFields)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("line %d: %w", lineNum, err))
+			continue
+		}
+
+		records = append(records, record)
+	}
+
+	if err := scanner.Err(); err != nil {
+		errs = append(errs, fmt.Errorf("failed to read input after line %d: %w", lineNum, err))
+	}
+
+	return records, joinErrors(errs...)
+}
+
+// parseServicePerfDataLine parses a single line of a service performance
+// data file using the given tab delimited template fields.
+func parseServicePerfDataLine(line string, templateFields []string) (PerfDataRecord, error) {
+	var record PerfDataRecord
+
+	fields := strings.Split(line, "\t")
+	if len(fields) != len(templateFields) {
+		return record, fmt.Errorf(
+			"found %d tab delimited fields, template has %d: %w",
+			len(fields),
+			len(templateFields),
+			ErrServicePerfDataFileTemplateMismatch,
+		)
+	}
+
+	for i, templateField := range templateFields {
+		macro, value, err := matchServicePerfDataField(fields[i], templateField)
+		if err != nil {
+			return record, err
+		}
+
+		switch macro {
+		case ServicePerfDataMacroTimeT:
+			sec, err := strconv.ParseInt(value, 10, 64)
+			if err != nil {
+				return record, fmt.Errorf(
+					"failed to parse timestamp %q: %v: %w",
+					value,
+					err,
+					ErrServicePerfDataFileTemplateMismatch,
+				)
+			}
+			record.Timestamp = time.Unix(sec, 0)
+
+		case ServicePerfDataMacroHostName:
+			record.HostName = value
+
+		case ServicePerfDataMacroServiceDesc:
+			record.ServiceDescription = value
+
+		case ServicePerfDataMacroServicePerfData:
+			if strings.TrimSpace(value) == "" {
+				continue
+			}
+
+			perfData, err := ParsePerfData(value)
+			if err != nil {
+				return record, fmt.Errorf(
+					"failed to parse performance data for service %q on host %q: %w",
+					record.ServiceDescription,
+					record.HostName,
+					err,
+				)
+			}
+			record.PerfData = perfData
+		}
+	}
+
+	return record, nil
+}
+
+// matchServicePerfDataField matches a single field of a service performance
+// data file line against the corresponding template field. The macro found
+// in the template field (if any) and the text the macro expanded to are
+// returned. An error is returned if literal text in the template field does
+// not match the line field.
+func matchServicePerfDataField(field string, templateField string) (macro string, value string, err error) {
+	start := strings.Index(templateField, "$")
+	end := -1
+	if start >= 0 {
+		if i := strings.Index(templateField[start+1:], "$"); i >= 0 {
+			end = start + 1 + i
+		}
+	}
+
+	if end < 0 {
+		if field != templateField {
+			return "", "", fmt.Errorf(
+				"field %q does not match template field %q: %w",
+				field,
+				templateField,
+				ErrServicePerfDataFileTemplateMismatch,
+			)
+		}
+
+		return "", "", nil
+	}
+
+	prefix, suffix := templateField[:start], templateField[end+1:]
+	if !strings.HasPrefix(field, prefix) || !strings.HasSuffix(field[len(prefix):], suffix) {
+		return "", "", fmt.Errorf(
+			"field %q does not match template field %q: %w",
+			field,
+			templateField,
+			ErrServicePerfDataFileTemplateMismatch,
+		)
+	}
+
+	return templateField[start : end+1], field[len(prefix) : len(field)-len(suffix)], nil
+}
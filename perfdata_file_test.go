@@ -0,0 +1,145 @@
+// Copyright 2023 Adam Chalkley
+//
+// https://github.com/atc0005/go-nagios
+//
+// Licensed under the MIT License. See LICENSE file in the project root for
+// full license information.
+
+package nagios_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/atc0005/go-nagios"
+)
+
+// TestParseServicePerfDataFile asserts that lines of a service performance
+// data file written using the default Nagios core template are parsed into
+// records and that malformed lines are reported by line number without
+// preventing other lines from parsing.
+func TestParseServicePerfDataFile(t *testing.T) {
+	t.Parallel()
+
+	input := strings.Join([]string{
+		"[SERVICEPERFDATA]\t1700000000\tweb01\tCurrent Load\t0.012\t0.105\tOK - load average: 0.26, 0.32, 0.30\t" +
+			"load1=0.260;5.000;10.000;0; load5=0.320;4.000;6.000;0;",
+		"",
+		"[SERVICEPERFDATA]\t1700000060\tweb01\tPING\t4.011\t0.097\tPING OK - Packet loss = 0%\t" +
+			"rta=0.080000ms;100.000000;500.000000;0.000000 pl=0%;20;60;0\r",
+		"[SERVICEPERFDATA]\t1700000120\tdb01\tSSH\t0.021\t0.110\tSSH OK - OpenSSH_9.6\t",
+		"[SERVICEPERFDATA]\t1700000180\tdb01\tDisk\t0.021\t0.110\tDISK OK",
+		"[HOSTPERFDATA]\t1700000240\tdb01\tDisk\t0.021\t0.110\tDISK OK\t/=2643MB",
+		"[SERVICEPERFDATA]\tyesterday\tdb01\tDisk\t0.021\t0.110\tDISK OK\t/=2643MB",
+		"[SERVICEPERFDATA]\t1700000360\tdb01\tDisk\t0.021\t0.110\tDISK OK\t/=MB",
+	}, "\n")
+
+	records, err := nagios.ParseServicePerfDataFile(strings.NewReader(input), nagios.DefaultServicePerfDataFileTemplate)
+
+	if !errors.Is(err, nagios.ErrServicePerfDataFileTemplateMismatch) {
+		t.Errorf("\nwant error %v\ngot %v", nagios.ErrServicePerfDataFileTemplateMismatch, err)
+	}
+
+	if !errors.Is(err, nagios.ErrInvalidPerformanceDataFormat) {
+		t.Errorf("\nwant error %v\ngot %v", nagios.ErrInvalidPerformanceDataFormat, err)
+	}
+
+	for _, lineNum := range []string{"line 5:", "line 6:", "line 7:", "line 8:"} {
+		if err == nil || !strings.Contains(err.Error(), lineNum) {
+			t.Errorf("want error to identify %s got %v", lineNum, err)
+		}
+	}
+
+	want := []nagios.PerfDataRecord{
+		{
+			Timestamp:          time.Unix(1700000000, 0),
+			HostName:           "web01",
+			ServiceDescription: "Current Load",
+			PerfData: []nagios.PerformanceData{
+				{Label: "load1", Value: "0.260", Warn: "5.000", Crit: "10.000", Min: "0"},
+				{Label: "load5", Value: "0.320", Warn: "4.000", Crit: "6.000", Min: "0"},
+			},
+		},
+		{
+			Timestamp:          time.Unix(1700000060, 0),
+			HostName:           "web01",
+			ServiceDescription: "PING",
+			PerfData: []nagios.PerformanceData{
+				{Label: "rta", Value: "0.080000", UnitOfMeasurement: "ms", Warn: "100.000000", Crit: "500.000000", Min: "0.000000"},
+				{Label: "pl", Value: "0", UnitOfMeasurement: "%", Warn: "20", Crit: "60", Min: "0"},
+			},
+		},
+		{
+			Timestamp:          time.Unix(1700000120, 0),
+			HostName:           "db01",
+			ServiceDescription: "SSH",
+		},
+	}
+
+	if len(records) != len(want) {
+		t.Fatalf("\nwant %d records\ngot %d: %v", len(want), len(records), records)
+	}
+
+	for i := range want {
+		if !records[i].Timestamp.Equal(want[i].Timestamp) {
+			t.Errorf("record %d:\nwant timestamp %v\ngot %v", i, want[i].Timestamp, records[i].Timestamp)
+		}
+
+		if records[i].HostName != want[i].HostName || records[i].ServiceDescription != want[i].ServiceDescription {
+			t.Errorf(
+				"record %d:\nwant host %q, service %q\ngot host %q, service %q",
+				i, want[i].HostName, want[i].ServiceDescription,
+				records[i].HostName, records[i].ServiceDescription,
+			)
+		}
+
+		if want[i].PerfData == nil {
+			if records[i].PerfData != nil {
+				t.Errorf("record %d: want nil perfdata, got %v", i, records[i].PerfData)
+			}
+			continue
+		}
+
+		testParsePerfDataCollection(t, records[i].PerfData, want[i].PerfData)
+	}
+}
+
+// TestParseServicePerfDataFileCustomTemplate asserts that a template with
+// prefixed macro fields, such as the one used by PNP4Nagios, is supported
+// and that a template without the $SERVICEPERFDATA$ macro is rejected.
+func TestParseServicePerfDataFileCustomTemplate(t *testing.T) {
+	t.Parallel()
+
+	template := "DATATYPE::SERVICEPERFDATA\tTIMET::$TIMET$\tHOSTNAME::$HOSTNAME$\t" +
+		"SERVICEDESC::$SERVICEDESC$\tSERVICEPERFDATA::$SERVICEPERFDATA$\t" +
+		"SERVICECHECKCOMMAND::$SERVICECHECKCOMMAND$"
+
+	input := "DATATYPE::SERVICEPERFDATA\tTIMET::1700000000\tHOSTNAME::web01\t" +
+		"SERVICEDESC::HTTP\tSERVICEPERFDATA::time=0.004s;;;0.000000 size=312B;;;0\t" +
+		"SERVICECHECKCOMMAND::check_http!-u /\n"
+
+	records, err := nagios.ParseServicePerfDataFile(strings.NewReader(input), template)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(records) != 1 {
+		t.Fatalf("\nwant 1 record\ngot %d", len(records))
+	}
+
+	if got := records[0]; got.HostName != "web01" || got.ServiceDescription != "HTTP" || got.Timestamp.Unix() != 1700000000 {
+		t.Errorf("\nwant host %q, service %q, timestamp %d\ngot %+v", "web01", "HTTP", 1700000000, got)
+	}
+
+	testParsePerfDataCollection(t, records[0].PerfData, []nagios.PerformanceData{
+		{Label: "time", Value: "0.004", UnitOfMeasurement: "s", Min: "0.000000"},
+		{Label: "size", Value: "312", UnitOfMeasurement: "B", Min: "0"},
+	})
+
+	_, err = nagios.ParseServicePerfDataFile(strings.NewReader(input), "$HOSTNAME$\t$SERVICEDESC$")
+	if !errors.Is(err, nagios.ErrServicePerfDataFileTemplateMismatch) {
+		t.Errorf("\nwant error %v\ngot %v", nagios.ErrServicePerfDataFileTemplateMismatch, err)
+	}
+}
@@ -0,0 +1,147 @@
+// Copyright 2023 Adam Chalkley
+//
+// https://github.com/atc0005/go-nagios
+//
+// Licensed under the MIT License. See LICENSE file in the project root for
+// full license information.
+
+package nagios
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// LintCode identifies the type of advisory reported by LintPerfData.
+type LintCode string
+
+// Advisory types reported by LintPerfData.
+const (
+	// LintCodeLabelSeparator indicates that a label uses spaces or dashes
+	// instead of underscores to separate words.
+	LintCodeLabelSeparator LintCode = "label-separator"
+
+	// LintCodeNonstandardUoM indicates that a Unit of Measurement is not one
+	// of the KnownUnitsOfMeasurement values.
+	LintCodeNonstandardUoM LintCode = "nonstandard-uom"
+
+	// LintCodePercentBounds indicates that a percentage metric does not
+	// specify Min and Max field values of 0 and 100.
+	LintCodePercentBounds LintCode = "percent-bounds"
+
+	// LintCodeLabelLength indicates that a label is longer than the 19
+	// characters significant to RRD based storage.
+	LintCodeLabelLength LintCode = "label-length"
+
+	// LintCodeThresholdOrder indicates that the Warn and Crit thresholds
+	// appear to be in the wrong order (see ThresholdsConsistent).
+	LintCodeThresholdOrder LintCode = "threshold-order"
+)
+
+// LintWarning is a non-fatal advisory reported by LintPerfData regarding a
+// deviation from plugin development guidelines or best practices.
+type LintWarning struct {
+	// Code identifies the type of advisory.
+	Code LintCode
+
+	// Label is the label of the metric the advisory applies to.
+	Label string
+
+	// Message describes the advisory.
+	Message string
+}
+
+// String returns the advisory in "label: code: message" format.
+func (lw LintWarning) String() string {
+	return fmt.Sprintf("%s: %s: %s", lw.Label, lw.Code, lw.Message)
+}
+
+// LintPerfData returns non-fatal advisories for the given collection of
+// PerformanceData values, in metric order. Unlike Validate, which reports
+// whether performance data is usable, these advisories are intended to guide
+// plugin authors towards best practices:
+//
+//   - labels should use underscores rather than spaces or dashes
+//   - units of measurement should be one of KnownUnitsOfMeasurement
+//   - percentage metrics should specify Min and Max values of 0 and 100
+//     (optional per the guidelines, but helpful to graphing tools)
+//   - labels should be no longer than the 19 characters significant to RRD
+//     based storage (see also CheckRRDLabelCollisions)
+//   - Warn and Crit thresholds should be consistent (see
+//     ThresholdsConsistent)
+//
+// Fields which fail to parse are not reported; use Validate to detect
+// invalid performance data. A nil slice is returned if there are no
+// advisories.
+func LintPerfData(pd []PerformanceData) []LintWarning {
+	var warnings []LintWarning
+
+	for i := range pd {
+		add := func(code LintCode, format string, a ...interface{}) {
+			warnings = append(warnings, LintWarning{
+				Code:    code,
+				Label:   pd[i].Label,
+				Message: fmt.Sprintf(format, a...),
+			})
+		}
+
+		if strings.ContainsAny(pd[i].Label, " -") {
+			add(
+				LintCodeLabelSeparator,
+				"label %q uses spaces or dashes; underscores are preferred",
+				pd[i].Label,
+			)
+		}
+
+		uom := strings.TrimSpace(pd[i].UnitOfMeasurement)
+		if uom != "" && !inList(uom, KnownUnitsOfMeasurement, false) {
+			add(
+				LintCodeNonstandardUoM,
+				"unit of measurement %q not in set %q",
+				uom,
+				KnownUnitsOfMeasurement,
+			)
+		}
+
+		if uom == "%" && !isPercentBounds(pd[i].Min, pd[i].Max) {
+			add(
+				LintCodePercentBounds,
+				"percentage metric bounds %q:%q differ from 0:100",
+				pd[i].Min,
+				pd[i].Max,
+			)
+		}
+
+		if length := utf8.RuneCountInString(pd[i].Label); length > rrdLabelSignificantCharacters {
+			add(
+				LintCodeLabelLength,
+				"label length %d exceeds the %d characters significant to RRD based storage",
+				length,
+				rrdLabelSignificantCharacters,
+			)
+		}
+
+		if err := pd[i].ThresholdsConsistent(); errors.Is(err, ErrPerformanceDataInconsistentThresholds) {
+			add(
+				LintCodeThresholdOrder,
+				"warn threshold %q and crit threshold %q look suspicious; crit range is contained within warn range",
+				pd[i].Warn,
+				pd[i].Crit,
+			)
+		}
+	}
+
+	return warnings
+}
+
+// isPercentBounds reports whether the given Min and Max field values are
+// numerically equal to 0 and 100.
+func isPercentBounds(min string, max string) bool {
+	minVal, minErr := strconv.ParseFloat(strings.TrimSpace(min), 64)
+	maxVal, maxErr := strconv.ParseFloat(strings.TrimSpace(max), 64)
+
+	return minErr == nil && maxErr == nil && minVal == 0 && maxVal == 100
+}
@@ -0,0 +1,117 @@
+// Copyright 2023 Adam Chalkley
+//
+// https://github.com/atc0005/go-nagios
+//
+// Licensed under the MIT License. See LICENSE file in the project root for
+// full license information.
+
+package nagios_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/atc0005/go-nagios"
+	"github.com/google/go-cmp/cmp"
+)
+
+// TestLintPerfData asserts that each advisory type is reported for
+// performance data deviating from plugin guidelines and that no advisories
+// are reported for performance data following them.
+func TestLintPerfData(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		perfData  []nagios.PerformanceData
+		wantCodes []nagios.LintCode
+	}{
+		"clean": {
+			perfData: []nagios.PerformanceData{
+				{Label: "load_1", Value: "0.260", Warn: "5", Crit: "10"},
+				{Label: "cpu", Value: "45", UnitOfMeasurement: "%", Min: "0", Max: "100.0"},
+			},
+		},
+		"label with spaces": {
+			perfData:  []nagios.PerformanceData{{Label: "load 1", Value: "0.260"}},
+			wantCodes: []nagios.LintCode{nagios.LintCodeLabelSeparator},
+		},
+		"label with dashes": {
+			perfData:  []nagios.PerformanceData{{Label: "load-1", Value: "0.260"}},
+			wantCodes: []nagios.LintCode{nagios.LintCodeLabelSeparator},
+		},
+		"nonstandard uom": {
+			perfData:  []nagios.PerformanceData{{Label: "temp", Value: "21.5", UnitOfMeasurement: "C"}},
+			wantCodes: []nagios.LintCode{nagios.LintCodeNonstandardUoM},
+		},
+		"percentage without bounds": {
+			perfData:  []nagios.PerformanceData{{Label: "cpu", Value: "45", UnitOfMeasurement: "%"}},
+			wantCodes: []nagios.LintCode{nagios.LintCodePercentBounds},
+		},
+		"percentage with other bounds": {
+			perfData:  []nagios.PerformanceData{{Label: "cpu", Value: "45", UnitOfMeasurement: "%", Min: "0", Max: "400"}},
+			wantCodes: []nagios.LintCode{nagios.LintCodePercentBounds},
+		},
+		"long label": {
+			perfData:  []nagios.PerformanceData{{Label: "this_label_is_too_long", Value: "1"}},
+			wantCodes: []nagios.LintCode{nagios.LintCodeLabelLength},
+		},
+		"suspicious threshold order": {
+			perfData:  []nagios.PerformanceData{{Label: "load", Value: "1", Warn: "10", Crit: "5"}},
+			wantCodes: []nagios.LintCode{nagios.LintCodeThresholdOrder},
+		},
+		"unparseable threshold not reported": {
+			perfData: []nagios.PerformanceData{{Label: "load", Value: "1", Warn: "@@", Crit: "5"}},
+		},
+		"multiple advisories in metric order": {
+			perfData: []nagios.PerformanceData{
+				{Label: "disk usage-root-partition", Value: "45", UnitOfMeasurement: "%"},
+				{Label: "temp", Value: "21.5", UnitOfMeasurement: "C", Warn: "30", Crit: "25"},
+			},
+			wantCodes: []nagios.LintCode{
+				nagios.LintCodeLabelSeparator,
+				nagios.LintCodePercentBounds,
+				nagios.LintCodeLabelLength,
+				nagios.LintCodeNonstandardUoM,
+				nagios.LintCodeThresholdOrder,
+			},
+		},
+	}
+
+	for name, tt := range tests {
+		tt := tt
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			warnings := nagios.LintPerfData(tt.perfData)
+
+			var gotCodes []nagios.LintCode
+			for _, w := range warnings {
+				gotCodes = append(gotCodes, w.Code)
+
+				if w.Message == "" {
+					t.Errorf("empty message for advisory %q", w.Code)
+				}
+			}
+
+			if d := cmp.Diff(tt.wantCodes, gotCodes); d != "" {
+				t.Errorf("(-want, +got)\n:%s", d)
+			}
+		})
+	}
+}
+
+// TestLintWarningString asserts that an advisory is rendered as expected.
+func TestLintWarningString(t *testing.T) {
+	t.Parallel()
+
+	warnings := nagios.LintPerfData([]nagios.PerformanceData{{Label: "temp", Value: "21.5", UnitOfMeasurement: "C"}})
+	if len(warnings) != 1 {
+		t.Fatalf("want 1 advisory, got %d: %v", len(warnings), warnings)
+	}
+
+	want := `temp: nonstandard-uom: unit of measurement "C" not in set`
+	if got := warnings[0].String(); !strings.HasPrefix(got, want) {
+		t.Errorf("\nwant prefix %q\ngot %q", want, got)
+	}
+}
@@ -10,6 +10,11 @@
 package nagios_test
 
 import (
+	"encoding/json"
+	"errors"
+	"math"
+	"strconv"
+	"strings"
 	"testing"
 
 	"github.com/atc0005/go-nagios"
@@ -44,9 +49,27 @@ func TestParsePerfDataFailsForInvalidInput(t *testing.T) {
 			input: "",
 		},
 
+		"double quotes only": {
+			input: `""`,
+		},
+
 		"missing label field": {
 			input: `=1;5.000;10.000;0; load5=0.320;4.000;6.000;0; load15=0.300;3.000;4.000;0;`,
 		},
+
+		// Previously mis-parsed by capturing only the leading numeric
+		// portion of the value (e.g., "1" from "1,5").
+		"value field with comma decimal separator": {
+			input: `x=1,5`,
+		},
+
+		"value field with digits following unit of measurement": {
+			input: `x=5ms9`,
+		},
+
+		"value field with exponent notation": {
+			input: `x=1e3`,
+		},
 	}
 
 	for name, tt := range tests {
@@ -704,3 +727,3768 @@ func testParsePerfDataCollection(
 		}
 	}
 }
+
+// TestParsePerfDataWithOptionsAllowCommaDecimal asserts that a single comma
+// used as a decimal separator is accepted only when the AllowCommaDecimal
+// parsing option is enabled.
+func TestParsePerfDataWithOptionsAllowCommaDecimal(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		input   string
+		opts    nagios.PerfDataParseOptions
+		result  []nagios.PerformanceData
+		wantErr bool
+	}{
+		"comma decimal with UoM and option enabled": {
+			input: `temp=23,5C;30;40;;`,
+			opts:  nagios.PerfDataParseOptions{AllowCommaDecimal: true},
+			result: []nagios.PerformanceData{
+				{
+					Label:             "temp",
+					Value:             "23.5",
+					UnitOfMeasurement: "C",
+					Warn:              "30",
+					Crit:              "40",
+				},
+			},
+			wantErr: false,
+		},
+		"comma decimal with UoM and option disabled": {
+			input:   `temp=23,5C;30;40;;`,
+			opts:    nagios.PerfDataParseOptions{},
+			result:  nil,
+			wantErr: true,
+		},
+		"single comma treated as decimal separator": {
+			input: `count=1,234`,
+			opts:  nagios.PerfDataParseOptions{AllowCommaDecimal: true},
+			result: []nagios.PerformanceData{
+				{
+					Label: "count",
+					Value: "1.234",
+				},
+			},
+			wantErr: false,
+		},
+		"multiple commas rejected": {
+			input:   `count=1,234,5`,
+			opts:    nagios.PerfDataParseOptions{AllowCommaDecimal: true},
+			result:  nil,
+			wantErr: true,
+		},
+		"comma and period rejected": {
+			input:   `count=1.234,5`,
+			opts:    nagios.PerfDataParseOptions{AllowCommaDecimal: true},
+			result:  nil,
+			wantErr: true,
+		},
+		"period decimal unchanged with option enabled": {
+			input: `load1=0.260;5.000;10.000;0;`,
+			opts:  nagios.PerfDataParseOptions{AllowCommaDecimal: true},
+			result: []nagios.PerformanceData{
+				{
+					Label: "load1",
+					Value: "0.260",
+					Warn:  "5.000",
+					Crit:  "10.000",
+					Min:   "0",
+				},
+			},
+			wantErr: false,
+		},
+	}
+
+	for name, tt := range tests {
+		tt := tt
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			perfDataResults, err := nagios.ParsePerfDataWithOptions(tt.input, tt.opts)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("nagios.ParsePerfDataWithOptions() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			testParsePerfDataCollection(t, perfDataResults, tt.result)
+		})
+	}
+}
+
+// TestParsePerfDataSeq asserts that lazily parsing performance data yields
+// the same results as ParsePerfData, that iteration stops early when
+// requested and that parsing errors are passed to the caller.
+func TestParsePerfDataSeq(t *testing.T) {
+	t.Parallel()
+
+	const input string = `load1=0.260;5.000;10.000;0; load5=0.320;4.000;6.000;0; load15=0.300;3.000;4.000;0;`
+
+	t.Run("all metrics yielded", func(t *testing.T) {
+		t.Parallel()
+
+		want, err := nagios.ParsePerfData(input)
+		if err != nil {
+			t.Fatalf("failed to parse input: %v", err)
+		}
+
+		got := make([]nagios.PerformanceData, 0, len(want))
+		nagios.ParsePerfDataSeq(input, func(pd nagios.PerformanceData, err error) bool {
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			got = append(got, pd)
+			return true
+		})
+
+		testParsePerfDataCollection(t, got, want)
+	})
+
+	t.Run("early stop", func(t *testing.T) {
+		t.Parallel()
+
+		var calls int
+		nagios.ParsePerfDataSeq(input, func(pd nagios.PerformanceData, err error) bool {
+			calls++
+			return false
+		})
+
+		if calls != 1 {
+			t.Errorf("\nwant 1 call to yield\ngot %d calls", calls)
+		}
+	})
+
+	t.Run("invalid metric yields error", func(t *testing.T) {
+		t.Parallel()
+
+		var errs int
+		var labels []string
+		nagios.ParsePerfDataSeq(`load1=xyz load5=0.320`, func(pd nagios.PerformanceData, err error) bool {
+			if err != nil {
+				errs++
+				return true
+			}
+			labels = append(labels, pd.Label)
+			return true
+		})
+
+		if errs != 1 {
+			t.Errorf("\nwant 1 error\ngot %d errors", errs)
+		}
+		if len(labels) != 1 || labels[0] != "load5" {
+			t.Errorf("\nwant [load5] labels\ngot %v labels", labels)
+		}
+	})
+
+	t.Run("empty input yields error", func(t *testing.T) {
+		t.Parallel()
+
+		var gotErr error
+		nagios.ParsePerfDataSeq("", func(pd nagios.PerformanceData, err error) bool {
+			gotErr = err
+			return true
+		})
+
+		if !errors.Is(gotErr, nagios.ErrInvalidPerformanceDataFormat) {
+			t.Errorf("\nwant %v\ngot %v", nagios.ErrInvalidPerformanceDataFormat, gotErr)
+		}
+	})
+}
+
+// TestPerformanceDataSpecifiedFields asserts that the names of specified
+// PerformanceData fields are returned in canonical order.
+func TestPerformanceDataSpecifiedFields(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		input string
+		want  []string
+	}{
+		"label and value only": {
+			input: `users=4`,
+			want:  []string{"Label", "Value"},
+		},
+		"warn only": {
+			input: `users=4;10`,
+			want:  []string{"Label", "Value", "Warn"},
+		},
+		"warn and crit": {
+			input: `users=4;10;20;;`,
+			want:  []string{"Label", "Value", "Warn", "Crit"},
+		},
+		"crit and max without warn": {
+			input: `'/'=7826MB;;30211;;31802`,
+			want:  []string{"Label", "Value", "UnitOfMeasurement", "Crit", "Max"},
+		},
+		"all fields": {
+			input: `'/'=7826MB;28621;30211;0;31802`,
+			want:  []string{"Label", "Value", "UnitOfMeasurement", "Warn", "Crit", "Min", "Max"},
+		},
+	}
+
+	for name, tt := range tests {
+		tt := tt
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			perfData, err := nagios.ParsePerfData(tt.input)
+			if err != nil {
+				t.Fatalf("failed to parse input %q: %v", tt.input, err)
+			}
+
+			got := perfData[0].SpecifiedFields()
+			if d := cmp.Diff(tt.want, got); d != "" {
+				t.Errorf("(-want, +got)\n:%s", d)
+			}
+		})
+	}
+}
+
+// TestParsePerfDataWithOptionsStrictUoM asserts that unknown units of
+// measurement are rejected only when the StrictUoM parsing option is
+// enabled.
+func TestParsePerfDataWithOptionsStrictUoM(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		input   string
+		opts    nagios.PerfDataParseOptions
+		wantErr error
+	}{
+		"known unit with strict option": {
+			input:   `'time'=49ms;;;;`,
+			opts:    nagios.PerfDataParseOptions{StrictUoM: true},
+			wantErr: nil,
+		},
+		"no unit with strict option": {
+			input:   `procs=7307;450;600;0;`,
+			opts:    nagios.PerfDataParseOptions{StrictUoM: true},
+			wantErr: nil,
+		},
+		"unknown unit with strict option": {
+			input:   `'expires_leaf'=62d;30;15;;`,
+			opts:    nagios.PerfDataParseOptions{StrictUoM: true},
+			wantErr: nagios.ErrPerformanceDataUnknownUoM,
+		},
+		"unknown unit without strict option": {
+			input:   `'expires_leaf'=62d;30;15;;`,
+			opts:    nagios.PerfDataParseOptions{},
+			wantErr: nil,
+		},
+	}
+
+	for name, tt := range tests {
+		tt := tt
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := nagios.ParsePerfDataWithOptions(tt.input, tt.opts)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("\nwant error %v\ngot error %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+// TestParsePerfDataWithOptionsIcingaCompatUoM asserts that unknown units of
+// measurement are discarded only if the IcingaCompatUoM option is enabled.
+func TestParsePerfDataWithOptionsIcingaCompatUoM(t *testing.T) {
+	t.Parallel()
+
+	icinga := nagios.PerfDataParseOptions{IcingaCompatUoM: true}
+
+	tests := map[string]struct {
+		input   string
+		opts    nagios.PerfDataParseOptions
+		wantUoM string
+	}{
+		"unknown unit discarded": {
+			input:   `'expires_leaf'=62d;30;15;;`,
+			opts:    icinga,
+			wantUoM: "",
+		},
+		"unknown unit discarded with strict option": {
+			input:   `'expires_leaf'=62d;30;15;;`,
+			opts:    nagios.PerfDataParseOptions{IcingaCompatUoM: true, StrictUoM: true},
+			wantUoM: "",
+		},
+		"known unit kept": {
+			input:   `'time'=49ms;;;;`,
+			opts:    icinga,
+			wantUoM: "ms",
+		},
+		"canonicalized unit kept": {
+			input:   `'used'=10kb;;;;`,
+			opts:    nagios.PerfDataParseOptions{IcingaCompatUoM: true, CanonicalizeUoM: true},
+			wantUoM: "KB",
+		},
+		"no unit": {
+			input:   `procs=7307;450;600;0;`,
+			opts:    icinga,
+			wantUoM: "",
+		},
+		"unknown unit kept by default": {
+			input:   `'expires_leaf'=62d;30;15;;`,
+			wantUoM: "d",
+		},
+	}
+
+	for name, tt := range tests {
+		tt := tt
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := nagios.ParsePerfDataWithOptions(tt.input, tt.opts)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if got[0].UnitOfMeasurement != tt.wantUoM {
+				t.Errorf("\nwant UoM %q\ngot %q", tt.wantUoM, got[0].UnitOfMeasurement)
+			}
+		})
+	}
+}
+
+// TestPerformanceDataValidateWithOptionsStrictUoM asserts that unknown units
+// of measurement in constructed PerformanceData values are rejected when the
+// StrictUoM option is enabled.
+func TestPerformanceDataValidateWithOptionsStrictUoM(t *testing.T) {
+	t.Parallel()
+
+	pd := nagios.PerformanceData{
+		Label:             "expires_leaf",
+		Value:             "62",
+		UnitOfMeasurement: "d",
+	}
+
+	if err := pd.ValidateWithOptions(nagios.PerfDataParseOptions{}); err != nil {
+		t.Errorf("\nwant no error without StrictUoM option\ngot %v", err)
+	}
+
+	err := pd.ValidateWithOptions(nagios.PerfDataParseOptions{StrictUoM: true})
+	if !errors.Is(err, nagios.ErrPerformanceDataUnknownUoM) {
+		t.Errorf("\nwant error %v\ngot error %v", nagios.ErrPerformanceDataUnknownUoM, err)
+	}
+}
+
+// TestSplitValueAndUoM asserts that a raw value token is split into Value
+// and Unit of Measurement fields as expected.
+func TestSplitValueAndUoM(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		input     string
+		wantValue string
+		wantUoM   string
+		wantErr   bool
+	}{
+		"value without unit": {
+			input:     "7307",
+			wantValue: "7307",
+		},
+		"value with unit": {
+			input:     "49ms",
+			wantValue: "49",
+			wantUoM:   "ms",
+		},
+		"negative float with percent unit": {
+			input:     "-0.5%",
+			wantValue: "-0.5",
+			wantUoM:   "%",
+		},
+		"undetermined value": {
+			input:     "U",
+			wantValue: "U",
+		},
+		"empty input": {
+			input:   "",
+			wantErr: true,
+		},
+		"non-numeric value": {
+			input:   "xyz",
+			wantErr: true,
+		},
+		"unit with quotes": {
+			input:   `10"B"`,
+			wantErr: true,
+		},
+	}
+
+	for name, tt := range tests {
+		tt := tt
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			value, uom, err := nagios.SplitValueAndUoM(tt.input)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("nagios.SplitValueAndUoM() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if value != tt.wantValue || uom != tt.wantUoM {
+				t.Errorf(
+					"\nwant value %q, uom %q\ngot value %q, uom %q",
+					tt.wantValue, tt.wantUoM, value, uom,
+				)
+			}
+		})
+	}
+}
+
+// TestPerformanceDataRaw asserts that the original metric string is retained
+// for parsed values and is empty for manually constructed values.
+func TestPerformanceDataRaw(t *testing.T) {
+	t.Parallel()
+
+	perfData, err := nagios.ParsePerfData(`load1=0.260;5.000;10.000;0; 'time'=49ms;;;;`)
+	if err != nil {
+		t.Fatalf("failed to parse input: %v", err)
+	}
+
+	want := []string{`load1=0.260;5.000;10.000;0;`, `'time'=49ms;;;;`}
+	for i := range perfData {
+		if got := perfData[i].Raw(); got != want[i] {
+			t.Errorf("\nwant raw %q\ngot raw %q", want[i], got)
+		}
+	}
+
+	constructed := nagios.PerformanceData{Label: "time", Value: "49", UnitOfMeasurement: "ms"}
+	if got := constructed.Raw(); got != "" {
+		t.Errorf("\nwant empty raw value for constructed metric\ngot %q", got)
+	}
+
+	if !constructed.Equal(perfData[1]) {
+		t.Errorf("want parsed and constructed metrics to be equal regardless of raw value")
+	}
+}
+
+// TestPerformanceDataEqual asserts that PerformanceData values are compared
+// as expected.
+func TestPerformanceDataEqual(t *testing.T) {
+	t.Parallel()
+
+	base := nagios.PerformanceData{
+		Label:             "load1",
+		Value:             "0.260",
+		UnitOfMeasurement: "",
+		Warn:              "5.000",
+		Crit:              "10.000",
+		Min:               "0",
+	}
+
+	tests := map[string]struct {
+		modify func(pd nagios.PerformanceData) nagios.PerformanceData
+		want   bool
+	}{
+		"identical": {
+			modify: func(pd nagios.PerformanceData) nagios.PerformanceData { return pd },
+			want:   true,
+		},
+		"equivalent numeric value": {
+			modify: func(pd nagios.PerformanceData) nagios.PerformanceData {
+				pd.Value = "0.26"
+				pd.Min = "0.0"
+				return pd
+			},
+			want: true,
+		},
+		"different value": {
+			modify: func(pd nagios.PerformanceData) nagios.PerformanceData {
+				pd.Value = "0.261"
+				return pd
+			},
+			want: false,
+		},
+		"different label": {
+			modify: func(pd nagios.PerformanceData) nagios.PerformanceData {
+				pd.Label = "load5"
+				return pd
+			},
+			want: false,
+		},
+		"different threshold": {
+			modify: func(pd nagios.PerformanceData) nagios.PerformanceData {
+				pd.Crit = "11.000"
+				return pd
+			},
+			want: false,
+		},
+	}
+
+	for name, tt := range tests {
+		tt := tt
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			if got := base.Equal(tt.modify(base)); got != tt.want {
+				t.Errorf("\nwant Equal() %t\ngot %t", tt.want, got)
+			}
+		})
+	}
+}
+
+// TestPerformanceDataEqualValues asserts that EqualValues compares only the
+// measured value fields while Equal compares all fields.
+func TestPerformanceDataEqualValues(t *testing.T) {
+	t.Parallel()
+
+	base := nagios.PerformanceData{
+		Label:             "load1",
+		Value:             "0.260",
+		UnitOfMeasurement: "",
+		Warn:              "5.000",
+		Crit:              "10.000",
+		Min:               "0",
+	}
+
+	tests := map[string]struct {
+		other           nagios.PerformanceData
+		wantEqualValues bool
+		wantEqual       bool
+	}{
+		"identical": {
+			other:           base,
+			wantEqualValues: true,
+			wantEqual:       true,
+		},
+		"different thresholds and bounds": {
+			other:           nagios.PerformanceData{Label: "load1", Value: "0.26", Warn: "6", Crit: "12", Max: "100"},
+			wantEqualValues: true,
+			wantEqual:       false,
+		},
+		"different value": {
+			other:           nagios.PerformanceData{Label: "load1", Value: "0.270", Warn: "5.000", Crit: "10.000", Min: "0"},
+			wantEqualValues: false,
+			wantEqual:       false,
+		},
+		"different uom": {
+			other:           nagios.PerformanceData{Label: "load1", Value: "0.260", UnitOfMeasurement: "%", Warn: "5.000", Crit: "10.000", Min: "0"},
+			wantEqualValues: false,
+			wantEqual:       false,
+		},
+		"different label": {
+			other:           nagios.PerformanceData{Label: "load5", Value: "0.260", Warn: "5.000", Crit: "10.000", Min: "0"},
+			wantEqualValues: false,
+			wantEqual:       false,
+		},
+	}
+
+	for name, tt := range tests {
+		tt := tt
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			if got := base.EqualValues(tt.other); got != tt.wantEqualValues {
+				t.Errorf("\nwant EqualValues() %t\ngot %t", tt.wantEqualValues, got)
+			}
+
+			if got := base.Equal(tt.other); got != tt.wantEqual {
+				t.Errorf("\nwant Equal() %t\ngot %t", tt.wantEqual, got)
+			}
+		})
+	}
+}
+
+// TestPerformanceDataEqualWithin asserts that numeric fields are compared
+// using the given tolerance while all other fields are compared exactly.
+func TestPerformanceDataEqualWithin(t *testing.T) {
+	t.Parallel()
+
+	base := nagios.PerformanceData{
+		Label:             "temp",
+		Value:             "20.5",
+		UnitOfMeasurement: "C",
+		Warn:              "30",
+		Crit:              "35",
+		Min:               "-40",
+		Max:               "80",
+	}
+
+	with := func(fn func(*nagios.PerformanceData)) nagios.PerformanceData {
+		pd := base
+		fn(&pd)
+		return pd
+	}
+
+	tests := map[string]struct {
+		other nagios.PerformanceData
+		tol   float64
+		want  bool
+	}{
+		"identical": {
+			other: base,
+			tol:   0.25,
+			want:  true,
+		},
+		"value just inside tolerance": {
+			other: with(func(pd *nagios.PerformanceData) { pd.Value = "20.7421875" }),
+			tol:   0.25,
+			want:  true,
+		},
+		"value at tolerance": {
+			other: with(func(pd *nagios.PerformanceData) { pd.Value = "20.25" }),
+			tol:   0.25,
+			want:  true,
+		},
+		"value just outside tolerance": {
+			other: with(func(pd *nagios.PerformanceData) { pd.Value = "20.7578125" }),
+			tol:   0.25,
+			want:  false,
+		},
+		"value differs with zero tolerance": {
+			other: with(func(pd *nagios.PerformanceData) { pd.Value = "20.5000001" }),
+			tol:   0,
+			want:  false,
+		},
+		"value formatting differs with zero tolerance": {
+			other: with(func(pd *nagios.PerformanceData) { pd.Value = "20.50" }),
+			tol:   0,
+			want:  true,
+		},
+		"min and max inside tolerance": {
+			other: with(func(pd *nagios.PerformanceData) { pd.Min = "-40.125"; pd.Max = "79.875" }),
+			tol:   0.25,
+			want:  true,
+		},
+		"max outside tolerance": {
+			other: with(func(pd *nagios.PerformanceData) { pd.Max = "81" }),
+			tol:   0.25,
+			want:  false,
+		},
+		"undetermined value compared exactly": {
+			other: with(func(pd *nagios.PerformanceData) { pd.Value = "U" }),
+			tol:   100,
+			want:  false,
+		},
+		"thresholds compared exactly": {
+			other: with(func(pd *nagios.PerformanceData) { pd.Warn = "30.1" }),
+			tol:   0.25,
+			want:  false,
+		},
+		"uom compared exactly": {
+			other: with(func(pd *nagios.PerformanceData) { pd.UnitOfMeasurement = "F" }),
+			tol:   0.25,
+			want:  false,
+		},
+		"values count differs": {
+			other: with(func(pd *nagios.PerformanceData) { pd.Values = []string{"20.625", "21"} }),
+			tol:   0.25,
+			want:  false,
+		},
+	}
+
+	for name, tt := range tests {
+		tt := tt
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			if got := base.EqualWithin(tt.other, tt.tol); got != tt.want {
+				t.Errorf("\nwant %t\ngot %t", tt.want, got)
+			}
+
+			if got := tt.other.EqualWithin(base, tt.tol); got != tt.want {
+				t.Errorf("\nwant symmetric result %t\ngot %t", tt.want, got)
+			}
+		})
+	}
+
+	a := nagios.PerformanceData{Label: "temp", Value: "20.5", Values: []string{"20.5", "21"}}
+	b := nagios.PerformanceData{Label: "temp", Value: "20.625", Values: []string{"20.625", "20.875"}}
+
+	if !a.EqualWithin(b, 0.25) {
+		t.Errorf("want value lists within tolerance to be equal")
+	}
+
+	if a.EqualWithin(b, 0.0625) {
+		t.Errorf("want value lists outside tolerance to differ")
+	}
+}
+
+// TestPerformanceDataClone asserts that modifying a cloned PerformanceData
+// value does not affect the original.
+func TestPerformanceDataClone(t *testing.T) {
+	t.Parallel()
+
+	perfData, err := nagios.ParsePerfData(`'/'=7826MB;28621;30211;0;31802`)
+	if err != nil {
+		t.Fatalf("failed to parse input: %v", err)
+	}
+
+	original := perfData[0]
+	clone := original.Clone()
+
+	if !clone.Equal(original) || clone.Raw() != original.Raw() {
+		t.Fatalf("\nwant clone %+v\ngot %+v", original, clone)
+	}
+
+	clone.Label = "/boot"
+	clone.Value = "40"
+
+	if original.Label != "/" || original.Value != "7826" {
+		t.Errorf("modifying clone affected original: %+v", original)
+	}
+
+	if clone.Equal(original) {
+		t.Errorf("want modified clone to differ from original")
+	}
+
+	withValues := nagios.PerformanceData{Label: "temp", Value: "20", Values: []string{"20", "21"}}
+	valuesClone := withValues.Clone()
+	valuesClone.Values[0] = "30"
+
+	if withValues.Values[0] != "20" {
+		t.Errorf("modifying clone Values affected original: %v", withValues.Values)
+	}
+}
+
+// TestFormatPerfDataLine asserts that a collection of performance data is
+// formatted as a single line of space separated metrics.
+func TestFormatPerfDataLine(t *testing.T) {
+	t.Parallel()
+
+	perfData := []nagios.PerformanceData{
+		{Label: "load1", Value: "0.260", Warn: "5.000", Crit: "10.000", Min: "0"},
+		{Label: "time", Value: "49", UnitOfMeasurement: "ms"},
+	}
+
+	want := `'load1'=0.260;5.000;10.000;0; 'time'=49ms;;;;`
+	if got := nagios.FormatPerfDataLine(perfData); got != want {
+		t.Errorf("\nwant %q\ngot %q", want, got)
+	}
+
+	if got := nagios.FormatPerfDataLine(nil); got != "" {
+		t.Errorf("\nwant empty string for empty collection\ngot %q", got)
+	}
+}
+
+// TestParsePerfDataRoundTrip asserts that valid performance data survives a
+// parse/emit/parse round trip and that invalid input is reported.
+func TestParsePerfDataRoundTrip(t *testing.T) {
+	t.Parallel()
+
+	valid := []string{
+		`load1=0.260;5.000;10.000;0; load5=0.320;4.000;6.000;0; load15=0.300;3.000;4.000;0;`,
+		`'/'=7826MB;28621;30211;0;31802 '/dev/shm'=0MB;3542;3739;0;3936 '/boot'=40MB;428;452;0;476`,
+		`'time'=49ms;@10:20;~:30;;`,
+		`value=U`,
+	}
+
+	for _, input := range valid {
+		if err := nagios.ParsePerfDataRoundTrip(input); err != nil {
+			t.Errorf("round trip of %q failed: %v", input, err)
+		}
+	}
+
+	err := nagios.ParsePerfDataRoundTrip(`load1=xyz`)
+	if err == nil || errors.Is(err, nagios.ErrPerformanceDataRoundTripMismatch) {
+		t.Errorf("want parsing error for invalid input, got %v", err)
+	}
+}
+
+// FuzzParsePerfData asserts that any performance data string which parses
+// successfully survives a parse/emit/parse round trip unchanged.
+func FuzzParsePerfData(f *testing.F) {
+	seeds := []string{
+		`load1=0.260;5.000;10.000;0; load5=0.320;4.000;6.000;0; load15=0.300;3.000;4.000;0;`,
+		`"so_negative=-0.260;-5.000;-10.000;-50;"`,
+		`'/'=7826MB;28621;30211;0;31802 '/dev/shm'=0MB;3542;3739;0;3936 '/boot'=40MB;428;452;0;476`,
+		`procs=7307;450;600;0;`,
+		`'time'=49ms;;;;`,
+		`'expires_leaf'=62d;30;15;;`,
+		`'time'=49ms;@10:20;~:30;;`,
+		`value=U`,
+	}
+
+	for _, seed := range seeds {
+		f.Add(seed)
+	}
+
+	f.Fuzz(func(t *testing.T, raw string) {
+		err := nagios.ParsePerfDataRoundTrip(raw)
+		if errors.Is(err, nagios.ErrPerformanceDataRoundTripMismatch) {
+			t.Errorf("round trip of %q failed: %v", raw, err)
+		}
+	})
+}
+
+// TestParsePerfDataWithOptionsUndeterminedValueTokens asserts that custom
+// "value unavailable" tokens are normalized to the literal U value only when
+// specified via parsing options.
+func TestParsePerfDataWithOptionsUndeterminedValueTokens(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		input   string
+		opts    nagios.PerfDataParseOptions
+		result  []nagios.PerformanceData
+		wantErr bool
+	}{
+		"N/A with token configured": {
+			input: `temp=N/A;30;40;;`,
+			opts:  nagios.PerfDataParseOptions{UndeterminedValueTokens: []string{"N/A", "-"}},
+			result: []nagios.PerformanceData{
+				{Label: "temp", Value: "U", Warn: "30", Crit: "40"},
+			},
+		},
+		"N/A without token configured": {
+			input:   `temp=N/A;30;40;;`,
+			opts:    nagios.PerfDataParseOptions{},
+			wantErr: true,
+		},
+		"literal U with other tokens configured": {
+			input: `temp=U`,
+			opts:  nagios.PerfDataParseOptions{UndeterminedValueTokens: []string{"N/A"}},
+			result: []nagios.PerformanceData{
+				{Label: "temp", Value: "U"},
+			},
+		},
+		"numeric value with tokens configured": {
+			input: `temp=23C`,
+			opts:  nagios.PerfDataParseOptions{UndeterminedValueTokens: []string{"N/A"}},
+			result: []nagios.PerformanceData{
+				{Label: "temp", Value: "23", UnitOfMeasurement: "C"},
+			},
+		},
+	}
+
+	for name, tt := range tests {
+		tt := tt
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			perfDataResults, err := nagios.ParsePerfDataWithOptions(tt.input, tt.opts)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("nagios.ParsePerfDataWithOptions() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			testParsePerfDataCollection(t, perfDataResults, tt.result)
+		})
+	}
+}
+
+// TestPerfDataLabels asserts that only the labels are extracted from a raw
+// performance data string.
+func TestPerfDataLabels(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		input   string
+		want    []string
+		wantErr bool
+	}{
+		"load averages": {
+			input: `load1=0.260;5.000;10.000;0; load5=0.320;4.000;6.000;0; load15=0.300;3.000;4.000;0;`,
+			want:  []string{"load1", "load5", "load15"},
+		},
+		"single quoted labels": {
+			input: `'/'=7826MB;28621;30211;0;31802 '/dev/shm'=0MB;3542;3739;0;3936 '/boot'=40MB;428;452;0;476`,
+			want:  []string{"/", "/dev/shm", "/boot"},
+		},
+		"invalid value ignored": {
+			input: `load1=xyz load5=`,
+			want:  []string{"load1", "load5"},
+		},
+		"missing equals sign": {
+			input:   `load1`,
+			wantErr: true,
+		},
+		"missing label": {
+			input:   `=1`,
+			wantErr: true,
+		},
+		"empty input": {
+			input:   ``,
+			wantErr: true,
+		},
+	}
+
+	for name, tt := range tests {
+		tt := tt
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := nagios.PerfDataLabels(tt.input)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("nagios.PerfDataLabels() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if d := cmp.Diff(tt.want, got); d != "" {
+				t.Errorf("(-want, +got)\n:%s", d)
+			}
+		})
+	}
+}
+
+// TestParsePerfDataWithOptionsAllowValueList asserts that nonstandard comma
+// separated value lists are accepted only when the AllowValueList parsing
+// option is enabled.
+func TestParsePerfDataWithOptionsAllowValueList(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		input   string
+		opts    nagios.PerfDataParseOptions
+		result  []nagios.PerformanceData
+		wantErr bool
+	}{
+		"multiple values with option enabled": {
+			input: `temp=20,21,22C;30;40;;`,
+			opts:  nagios.PerfDataParseOptions{AllowValueList: true},
+			result: []nagios.PerformanceData{
+				{
+					Label:             "temp",
+					Value:             "20",
+					Values:            []string{"20", "21", "22"},
+					UnitOfMeasurement: "C",
+					Warn:              "30",
+					Crit:              "40",
+				},
+			},
+		},
+		"single value with option enabled": {
+			input: `temp=20C;30;40;;`,
+			opts:  nagios.PerfDataParseOptions{AllowValueList: true},
+			result: []nagios.PerformanceData{
+				{
+					Label:             "temp",
+					Value:             "20",
+					UnitOfMeasurement: "C",
+					Warn:              "30",
+					Crit:              "40",
+				},
+			},
+		},
+		"multiple values with option disabled": {
+			input:   `temp=20,21,22C;30;40;;`,
+			opts:    nagios.PerfDataParseOptions{},
+			wantErr: true,
+		},
+		"mismatched units with option enabled": {
+			input:   `temp=20F,21,22C`,
+			opts:    nagios.PerfDataParseOptions{AllowValueList: true},
+			wantErr: true,
+		},
+		"empty list entry with option enabled": {
+			input:   `temp=20,,22C`,
+			opts:    nagios.PerfDataParseOptions{AllowValueList: true},
+			wantErr: true,
+		},
+	}
+
+	for name, tt := range tests {
+		tt := tt
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			perfDataResults, err := nagios.ParsePerfDataWithOptions(tt.input, tt.opts)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("nagios.ParsePerfDataWithOptions() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			testParsePerfDataCollection(t, perfDataResults, tt.result)
+		})
+	}
+}
+
+// TestPerformanceDataCompactString asserts that trailing empty optional
+// fields are omitted and that the output parses back to an identical
+// PerformanceData value.
+func TestPerformanceDataCompactString(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		pd   nagios.PerformanceData
+		want string
+	}{
+		"value only": {
+			pd:   nagios.PerformanceData{Label: "users", Value: "4"},
+			want: `'users'=4`,
+		},
+		"value with uom": {
+			pd:   nagios.PerformanceData{Label: "time", Value: "49", UnitOfMeasurement: "ms"},
+			want: `'time'=49ms`,
+		},
+		"warn only": {
+			pd:   nagios.PerformanceData{Label: "users", Value: "4", Warn: "10"},
+			want: `'users'=4;10`,
+		},
+		"min only": {
+			pd:   nagios.PerformanceData{Label: "users", Value: "4", Min: "0"},
+			want: `'users'=4;;;0`,
+		},
+		"crit and min": {
+			pd:   nagios.PerformanceData{Label: "users", Value: "4", Crit: "20", Min: "0"},
+			want: `'users'=4;;20;0`,
+		},
+		"all fields": {
+			pd: nagios.PerformanceData{
+				Label: "/", Value: "7826", UnitOfMeasurement: "MB",
+				Warn: "28621", Crit: "30211", Min: "0", Max: "31802",
+			},
+			want: `'/'=7826MB;28621;30211;0;31802`,
+		},
+	}
+
+	for name, tt := range tests {
+		tt := tt
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			got := tt.pd.CompactString()
+			if got != tt.want {
+				t.Fatalf("\nwant %q\ngot %q", tt.want, got)
+			}
+
+			parsed, err := nagios.ParsePerfData(got)
+			if err != nil {
+				t.Fatalf("failed to parse compact output %q: %v", got, err)
+			}
+
+			testParsePerfDataCollection(t, parsed, []nagios.PerformanceData{tt.pd})
+		})
+	}
+}
+
+// TestParsePerfDataRejectsUnitsInThresholds asserts that Warn and Crit
+// thresholds including a Unit of Measurement are rejected.
+func TestParsePerfDataRejectsUnitsInThresholds(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		input   string
+		wantErr bool
+	}{
+		"warn without unit":   {input: `usage=50%;80;90;0;100`, wantErr: false},
+		"warn with unit":      {input: `usage=50%;80%;90;0;100`, wantErr: true},
+		"crit with unit":      {input: `usage=50%;80;90%;0;100`, wantErr: true},
+		"range with unit":     {input: `usage=50%;10:80%;90;0;100`, wantErr: true},
+		"inverted range":      {input: `usage=50%;@10:20;90;0;100`, wantErr: false},
+		"negative inf range":  {input: `usage=50%;~:80;90;0;100`, wantErr: false},
+		"warn with text unit": {input: `time=5s;4s;10;;`, wantErr: true},
+	}
+
+	for name, tt := range tests {
+		tt := tt
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := nagios.ParsePerfData(tt.input)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("nagios.ParsePerfData() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if tt.wantErr && !strings.Contains(err.Error(), "units of measurement must not be included") {
+				t.Errorf("want error explaining units are not permitted, got %v", err)
+			}
+		})
+	}
+}
+
+// TestParsePerfDataWithOptionsTrimTrailingGarbage asserts that stray
+// trailing characters are removed only when the TrimTrailingGarbage parsing
+// option is enabled.
+func TestParsePerfDataWithOptionsTrimTrailingGarbage(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		input   string
+		opts    nagios.PerfDataParseOptions
+		result  []nagios.PerformanceData
+		wantErr bool
+	}{
+		"double quoted with Windows CRLF and option enabled": {
+			input: "\"load1=0.260;5.000;10.000;0; load5=0.320;4.000;6.000;0;\"\r\n",
+			opts:  nagios.PerfDataParseOptions{TrimTrailingGarbage: true},
+			result: []nagios.PerformanceData{
+				{Label: "load1", Value: "0.260", Warn: "5.000", Crit: "10.000", Min: "0"},
+				{Label: "load5", Value: "0.320", Warn: "4.000", Crit: "6.000", Min: "0"},
+			},
+		},
+		"double quoted with Windows CRLF and option disabled": {
+			input:   "\"load1=0.260;5.000;10.000;0; load5=0.320;4.000;6.000;0;\"\r\n",
+			opts:    nagios.PerfDataParseOptions{},
+			wantErr: true,
+		},
+		"trailing period with option enabled": {
+			input: `'time'=49ms.`,
+			opts:  nagios.PerfDataParseOptions{TrimTrailingGarbage: true},
+			result: []nagios.PerformanceData{
+				{Label: "time", Value: "49", UnitOfMeasurement: "ms"},
+			},
+		},
+		"trailing semicolons retained with option enabled": {
+			input: "'time'=49ms;;;;\r\n",
+			opts:  nagios.PerfDataParseOptions{TrimTrailingGarbage: true},
+			result: []nagios.PerformanceData{
+				{Label: "time", Value: "49", UnitOfMeasurement: "ms"},
+			},
+		},
+		"trailing period and CRLF after unit with option enabled": {
+			input: "'time'=49ms. \r\n",
+			opts:  nagios.PerfDataParseOptions{TrimTrailingGarbage: true},
+			result: []nagios.PerformanceData{
+				{Label: "time", Value: "49", UnitOfMeasurement: "ms"},
+			},
+		},
+		"numeric value with trailing period retained with option enabled": {
+			input: "'used'=100.\r\n",
+			opts:  nagios.PerfDataParseOptions{TrimTrailingGarbage: true},
+			result: []nagios.PerformanceData{
+				{Label: "used", Value: "100."},
+			},
+		},
+		"max with trailing period retained with option enabled": {
+			input: "'used'=50;;;0;100.",
+			opts:  nagios.PerfDataParseOptions{TrimTrailingGarbage: true},
+			result: []nagios.PerformanceData{
+				{Label: "used", Value: "50", Min: "0", Max: "100."},
+			},
+		},
+	}
+
+	for name, tt := range tests {
+		tt := tt
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			perfDataResults, err := nagios.ParsePerfDataWithOptions(tt.input, tt.opts)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("nagios.ParsePerfDataWithOptions() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			testParsePerfDataCollection(t, perfDataResults, tt.result)
+		})
+	}
+}
+
+// TestPerformanceDataValidateValueStates asserts that a real value and the
+// undetermined "U" value pass validation while a missing value fails with
+// the expected error. Min and Max values are accepted if they contain a
+// numeric character (e.g., "10ms"), as has always been the case.
+func TestPerformanceDataValidateValueStates(t *testing.T) {
+	t.Parallel()
+
+	base := nagios.PerformanceData{Label: "temp", Warn: "30", Crit: "40"}
+
+	realValue := base
+	realValue.Value = "23"
+
+	tests := map[string]struct {
+		pd               nagios.PerformanceData
+		wantErr          error
+		wantUndetermined bool
+	}{
+		"real value": {
+			pd:      realValue,
+			wantErr: nil,
+		},
+		"literal U value": {
+			pd:               nagios.PerformanceData{Label: "temp", Value: "U"},
+			wantErr:          nil,
+			wantUndetermined: true,
+		},
+		"undetermined via helper": {
+			pd:               base.AsValueUndetermined(),
+			wantErr:          nil,
+			wantUndetermined: true,
+		},
+		"undetermined via helper replaces real value": {
+			pd:               realValue.AsValueUndetermined(),
+			wantErr:          nil,
+			wantUndetermined: true,
+		},
+		"missing value": {
+			pd:      base,
+			wantErr: nagios.ErrPerformanceDataMissingValue,
+		},
+		"real value with numeric min and max": {
+			pd:      nagios.PerformanceData{Label: "temp", Value: "23", Min: "-10.5", Max: "+100"},
+			wantErr: nil,
+		},
+		"min with unit of measurement": {
+			pd:      nagios.PerformanceData{Label: "time", Value: "49", Min: "10ms"},
+			wantErr: nil,
+		},
+		"max with unit of measurement": {
+			pd:      nagios.PerformanceData{Label: "time", Value: "49", Max: "10ms"},
+			wantErr: nil,
+		},
+		"min with comma decimal separator": {
+			pd:      nagios.PerformanceData{Label: "load", Value: "2", Min: "1,5"},
+			wantErr: nil,
+		},
+		"max with comma decimal separator": {
+			pd:      nagios.PerformanceData{Label: "load", Value: "2", Max: "1,5"},
+			wantErr: nil,
+		},
+		"min without numeric character": {
+			pd:      nagios.PerformanceData{Label: "load", Value: "2", Min: "abc"},
+			wantErr: nagios.ErrInvalidPerformanceDataFormat,
+		},
+		"max without numeric character": {
+			pd:      nagios.PerformanceData{Label: "load", Value: "2", Max: "abc"},
+			wantErr: nagios.ErrInvalidPerformanceDataFormat,
+		},
+	}
+
+	for name, tt := range tests {
+		tt := tt
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			if err := tt.pd.Validate(); !errors.Is(err, tt.wantErr) {
+				t.Errorf("\nwant error %v\ngot %v", tt.wantErr, err)
+			}
+
+			if got := tt.pd.IsValueUndetermined(); got != tt.wantUndetermined {
+				t.Errorf("\nwant IsValueUndetermined() %t\ngot %t", tt.wantUndetermined, got)
+			}
+		})
+	}
+
+	if realValue.Value != "23" {
+		t.Errorf("AsValueUndetermined modified original value: %q", realValue.Value)
+	}
+}
+
+// TestParsePerfDataWithOptionsNormalizeLabelCase asserts that labels are
+// converted to lowercase only when the NormalizeLabelCase option is enabled.
+func TestParsePerfDataWithOptionsNormalizeLabelCase(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		input  string
+		opts   nagios.PerfDataParseOptions
+		result []nagios.PerformanceData
+	}{
+		"mixed case labels with option enabled": {
+			input: `CPU=10% 'Disk_Used'=20GB cpu=30%`,
+			opts:  nagios.PerfDataParseOptions{NormalizeLabelCase: true},
+			result: []nagios.PerformanceData{
+				{Label: "cpu", Value: "10", UnitOfMeasurement: "%"},
+				{Label: "disk_used", Value: "20", UnitOfMeasurement: "GB"},
+				{Label: "cpu", Value: "30", UnitOfMeasurement: "%"},
+			},
+		},
+		"mixed case labels with option disabled": {
+			input: `CPU=10% 'Disk_Used'=20GB cpu=30%`,
+			opts:  nagios.PerfDataParseOptions{},
+			result: []nagios.PerformanceData{
+				{Label: "CPU", Value: "10", UnitOfMeasurement: "%"},
+				{Label: "Disk_Used", Value: "20", UnitOfMeasurement: "GB"},
+				{Label: "cpu", Value: "30", UnitOfMeasurement: "%"},
+			},
+		},
+	}
+
+	for name, tt := range tests {
+		tt := tt
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			perfDataResults, err := nagios.ParsePerfDataWithOptions(tt.input, tt.opts)
+			if err != nil {
+				t.Fatalf("nagios.ParsePerfDataWithOptions() error = %v", err)
+			}
+			testParsePerfDataCollection(t, perfDataResults, tt.result)
+		})
+	}
+}
+
+// TestParsePerfDataWithOptionsRejectDuplicateLabels asserts that duplicate
+// labels are rejected only when the RejectDuplicateLabels option is enabled.
+func TestParsePerfDataWithOptionsRejectDuplicateLabels(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		input   string
+		opts    nagios.PerfDataParseOptions
+		result  []nagios.PerformanceData
+		wantErr error
+		wantMsg string
+	}{
+		"duplicate labels with option enabled": {
+			input:   "cpu=1 load=2 cpu=3",
+			opts:    nagios.PerfDataParseOptions{RejectDuplicateLabels: true},
+			wantErr: nagios.ErrPerformanceDataDuplicateLabel,
+			wantMsg: "metric 2 previously used by metric 0",
+		},
+		"duplicate labels with option disabled": {
+			input: "cpu=1 load=2 cpu=3",
+			opts:  nagios.PerfDataParseOptions{},
+			result: []nagios.PerformanceData{
+				{Label: "cpu", Value: "1"},
+				{Label: "load", Value: "2"},
+				{Label: "cpu", Value: "3"},
+			},
+		},
+		"case variants with option enabled": {
+			input: "CPU=1 cpu=3",
+			opts:  nagios.PerfDataParseOptions{RejectDuplicateLabels: true},
+			result: []nagios.PerformanceData{
+				{Label: "CPU", Value: "1"},
+				{Label: "cpu", Value: "3"},
+			},
+		},
+		"case variants with label normalization": {
+			input: "CPU=1 cpu=3",
+			opts: nagios.PerfDataParseOptions{
+				RejectDuplicateLabels: true,
+				NormalizeLabelCase:    true,
+			},
+			wantErr: nagios.ErrPerformanceDataDuplicateLabel,
+			wantMsg: "metric 1 previously used by metric 0",
+		},
+	}
+
+	for name, tt := range tests {
+		tt := tt
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			perfDataResults, err := nagios.ParsePerfDataWithOptions(tt.input, tt.opts)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("\nwant error %v\ngot %v", tt.wantErr, err)
+			}
+
+			if tt.wantErr != nil {
+				if !strings.Contains(err.Error(), tt.wantMsg) {
+					t.Errorf("\nwant error containing %q\ngot %v", tt.wantMsg, err)
+				}
+				return
+			}
+
+			testParsePerfDataCollection(t, perfDataResults, tt.result)
+		})
+	}
+}
+
+// TestPerformanceDataValidateReportsDisallowedCharacter asserts that Label
+// and UnitOfMeasurement validation failures identify the offending character
+// and its position.
+func TestPerformanceDataValidateReportsDisallowedCharacter(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		pd      nagios.PerformanceData
+		wantMsg string
+	}{
+		"double quote in uom": {
+			pd:      nagios.PerformanceData{Label: "time", Value: "49", UnitOfMeasurement: `m"s`},
+			wantMsg: `disallowed character '"' at index 1`,
+		},
+		"single quote in uom": {
+			pd:      nagios.PerformanceData{Label: "time", Value: "49", UnitOfMeasurement: `ms'`},
+			wantMsg: `disallowed character '\'' at index 2`,
+		},
+		"equals sign in label": {
+			pd:      nagios.PerformanceData{Label: "load=1", Value: "1"},
+			wantMsg: `disallowed character '=' at index 4`,
+		},
+		"single quote in label": {
+			pd:      nagios.PerformanceData{Label: "it's", Value: "1"},
+			wantMsg: `disallowed character '\'' at index 2`,
+		},
+	}
+
+	for name, tt := range tests {
+		tt := tt
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			err := tt.pd.Validate()
+			if !errors.Is(err, nagios.ErrInvalidPerformanceDataFormat) {
+				t.Fatalf("\nwant error %v\ngot %v", nagios.ErrInvalidPerformanceDataFormat, err)
+			}
+
+			if !strings.Contains(err.Error(), tt.wantMsg) {
+				t.Errorf("\nwant error containing %q\ngot %v", tt.wantMsg, err)
+			}
+		})
+	}
+}
+
+// TestParsePerfDataReader asserts that multiple lines of performance data
+// are parsed with blank lines and comments skipped and that malformed lines
+// are reported by line number without preventing other lines from parsing.
+func TestParsePerfDataReader(t *testing.T) {
+	t.Parallel()
+
+	input := strings.Join([]string{
+		"# check_load output",
+		"load1=0.260;5.000;10.000;0; load5=0.320;4.000;6.000;0;",
+		"",
+		"   ",
+		"time=49ms;;;;",
+		"# malformed line follows",
+		"time=;;;;",
+		"'cpu'=10%",
+	}, "\n")
+
+	results, err := nagios.ParsePerfDataReader(strings.NewReader(input))
+	if !errors.Is(err, nagios.ErrInvalidPerformanceDataFormat) {
+		t.Fatalf("\nwant error %v\ngot %v", nagios.ErrInvalidPerformanceDataFormat, err)
+	}
+
+	if !strings.Contains(err.Error(), "line 7:") {
+		t.Errorf("want error to identify line 7, got %v", err)
+	}
+
+	want := [][]nagios.PerformanceData{
+		{
+			{Label: "load1", Value: "0.260", Warn: "5.000", Crit: "10.000", Min: "0"},
+			{Label: "load5", Value: "0.320", Warn: "4.000", Crit: "6.000", Min: "0"},
+		},
+		{
+			{Label: "time", Value: "49", UnitOfMeasurement: "ms"},
+		},
+		{
+			{Label: "cpu", Value: "10", UnitOfMeasurement: "%"},
+		},
+	}
+
+	if len(results) != len(want) {
+		t.Fatalf("\nwant %d parsed lines\ngot %d", len(want), len(results))
+	}
+
+	for i := range want {
+		testParsePerfDataCollection(t, results[i], want[i])
+	}
+
+	results, err = nagios.ParsePerfDataReader(strings.NewReader("# only a comment\n\n"))
+	if err != nil || len(results) != 0 {
+		t.Errorf("want no results and no error for comment only input, got %v, %v", results, err)
+	}
+}
+
+// TestPerformanceDataValueStringPreservesPrecision asserts that the Value
+// field is preserved byte-for-byte through parsing and formatting (e.g.,
+// trailing zeros are not removed).
+func TestPerformanceDataValueStringPreservesPrecision(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		input string
+		want  string
+	}{
+		"trailing zero":          {input: "load1=0.260;5.000;10.000;0;", want: "0.260"},
+		"multiple trailing zero": {input: "ratio=0.10", want: "0.10"},
+		"leading zeros":          {input: "id=007", want: "007"},
+		"negative zero":          {input: "temp=-0.0C", want: "-0.0"},
+		"trailing period":        {input: "count=5.c", want: "5."},
+	}
+
+	for name, tt := range tests {
+		tt := tt
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			perfData, err := nagios.ParsePerfData(tt.input)
+			if err != nil {
+				t.Fatalf("failed to parse performance data: %v", err)
+			}
+
+			if got := perfData[0].ValueString(); got != tt.want {
+				t.Fatalf("\nwant %q\ngot %q", tt.want, got)
+			}
+
+			// Compare strings rather than using Equal (numeric comparison)
+			// to catch any change in precision.
+			reparsed, err := nagios.ParsePerfData(perfData[0].String())
+			if err != nil {
+				t.Fatalf("failed to parse formatted performance data: %v", err)
+			}
+
+			if got := reparsed[0].ValueString(); got != tt.want {
+				t.Errorf("\nwant %q after round trip\ngot %q", tt.want, got)
+			}
+
+			if !strings.Contains(perfData[0].String(), "="+tt.want) {
+				t.Errorf("\nwant formatted value %q\ngot %q", tt.want, perfData[0].String())
+			}
+		})
+	}
+}
+
+// TestPerformanceDataStringWithSeparator asserts that performance data
+// metrics are rendered using a custom separator and that conflicting
+// separators are rejected.
+func TestPerformanceDataStringWithSeparator(t *testing.T) {
+	t.Parallel()
+
+	pd := nagios.PerformanceData{
+		Label:             "time",
+		Value:             "49",
+		UnitOfMeasurement: "ms",
+		Warn:              "100",
+		Crit:              "200",
+		Min:               "0",
+	}
+
+	tests := map[string]struct {
+		pd      nagios.PerformanceData
+		sep     string
+		want    string
+		wantErr bool
+	}{
+		"pipe separator":             {pd: pd, sep: "|", want: "'time'=49ms|100|200|0|"},
+		"semicolon matches String":   {pd: pd, sep: ";", want: strings.TrimPrefix(pd.String(), " ")},
+		"multiple character":         {pd: pd, sep: "::", want: "'time'=49ms::100::200::0::"},
+		"percent sign separator":     {pd: pd, sep: "%", want: "'time'=49ms%100%200%0%"},
+		"empty separator":            {pd: pd, sep: "", wantErr: true},
+		"whitespace separator":       {pd: pd, sep: " ", wantErr: true},
+		"equals sign separator":      {pd: pd, sep: "=", wantErr: true},
+		"separator in field content": {pd: pd, sep: "0", wantErr: true},
+		"separator in uom":           {pd: nagios.PerformanceData{Label: "cpu", Value: "1", UnitOfMeasurement: "%"}, sep: "%", wantErr: true},
+	}
+
+	for name, tt := range tests {
+		tt := tt
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := tt.pd.StringWithSeparator(tt.sep)
+			switch {
+			case tt.wantErr:
+				if !errors.Is(err, nagios.ErrInvalidPerformanceDataFormat) {
+					t.Fatalf("\nwant error %v\ngot %v", nagios.ErrInvalidPerformanceDataFormat, err)
+				}
+				return
+			case err != nil:
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if got != tt.want {
+				t.Errorf("\nwant %q\ngot %q", tt.want, got)
+			}
+		})
+	}
+}
+
+// TestParsePerfDataRejectsUndeterminedMinMax asserts that the literal "U"
+// value in the Min or Max fields is rejected with a specific error.
+func TestParsePerfDataRejectsUndeterminedMinMax(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		input string
+	}{
+		"U in min field":   {input: "time=49ms;100;200;U;1000"},
+		"U in max field":   {input: "time=49ms;100;200;0;U"},
+		"U in min and max": {input: "time=U;;;U;U"},
+	}
+
+	for name, tt := range tests {
+		tt := tt
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := nagios.ParsePerfData(tt.input)
+			if !errors.Is(err, nagios.ErrPerformanceDataUnexpectedSentinel) {
+				t.Errorf("\nwant error %v\ngot %v", nagios.ErrPerformanceDataUnexpectedSentinel, err)
+			}
+
+			if !errors.Is(err, nagios.ErrInvalidPerformanceDataFormat) {
+				t.Errorf("\nwant error %v\ngot %v", nagios.ErrInvalidPerformanceDataFormat, err)
+			}
+		})
+	}
+
+	pd := nagios.PerformanceData{Label: "time", Value: "49", Max: "U"}
+	if err := pd.Validate(); !errors.Is(err, nagios.ErrPerformanceDataUnexpectedSentinel) {
+		t.Errorf("\nwant error %v\ngot %v", nagios.ErrPerformanceDataUnexpectedSentinel, err)
+	}
+}
+
+// TestParseSinglePerfData asserts that exactly one performance data metric
+// is accepted.
+func TestParseSinglePerfData(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		input   string
+		want    nagios.PerformanceData
+		wantErr bool
+	}{
+		"zero metrics": {
+			input:   "",
+			wantErr: true,
+		},
+		"whitespace only": {
+			input:   "   ",
+			wantErr: true,
+		},
+		"one metric": {
+			input: "'time'=49ms;100;200;0;",
+			want:  nagios.PerformanceData{Label: "time", Value: "49", UnitOfMeasurement: "ms", Warn: "100", Crit: "200", Min: "0"},
+		},
+		"one metric with surrounding whitespace": {
+			input: "  load1=0.260  ",
+			want:  nagios.PerformanceData{Label: "load1", Value: "0.260"},
+		},
+		"two metrics": {
+			input:   "load1=0.260 load5=0.320",
+			wantErr: true,
+		},
+		"one invalid metric": {
+			input:   "load1=",
+			wantErr: true,
+		},
+	}
+
+	for name, tt := range tests {
+		tt := tt
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := nagios.ParseSinglePerfData(tt.input)
+			switch {
+			case tt.wantErr:
+				if !errors.Is(err, nagios.ErrInvalidPerformanceDataFormat) {
+					t.Fatalf("\nwant error %v\ngot %v", nagios.ErrInvalidPerformanceDataFormat, err)
+				}
+				return
+			case err != nil:
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			testParsePerfDataCollection(t, []nagios.PerformanceData{got}, []nagios.PerformanceData{tt.want})
+		})
+	}
+}
+
+// TestPerformanceDataFieldPresence asserts that configured optional fields
+// are distinguished from fields which are present but empty or absent.
+func TestPerformanceDataFieldPresence(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		input       string
+		wantHas     [4]bool
+		wantPresent []string
+	}{
+		"crit only with empty warn and min": {
+			input:       "x=1;;5;",
+			wantHas:     [4]bool{false, true, false, false},
+			wantPresent: []string{"warn", "crit", "min"},
+		},
+		"no optional fields": {
+			input:       "x=1",
+			wantHas:     [4]bool{false, false, false, false},
+			wantPresent: []string{},
+		},
+		"all optional fields empty": {
+			input:       "x=1;;;;",
+			wantHas:     [4]bool{false, false, false, false},
+			wantPresent: []string{"warn", "crit", "min", "max"},
+		},
+		"all optional fields configured": {
+			input:       "x=1;2;3;0;10",
+			wantHas:     [4]bool{true, true, true, true},
+			wantPresent: []string{"warn", "crit", "min", "max"},
+		},
+	}
+
+	for name, tt := range tests {
+		tt := tt
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			pd, err := nagios.ParseSinglePerfData(tt.input)
+			if err != nil {
+				t.Fatalf("failed to parse performance data: %v", err)
+			}
+
+			gotHas := [4]bool{pd.HasWarn(), pd.HasCrit(), pd.HasMin(), pd.HasMax()}
+			if gotHas != tt.wantHas {
+				t.Errorf("\nwant HasWarn/HasCrit/HasMin/HasMax %v\ngot %v", tt.wantHas, gotHas)
+			}
+
+			if d := cmp.Diff(tt.wantPresent, pd.PresentFields()); d != "" {
+				t.Errorf("(-want, +got)\n:%s", d)
+			}
+		})
+	}
+
+	constructed := nagios.PerformanceData{Label: "x", Value: "1", Crit: "5"}
+	if d := cmp.Diff([]string{"crit"}, constructed.PresentFields()); d != "" {
+		t.Errorf("(-want, +got)\n:%s", d)
+	}
+}
+
+// TestParsePerfDataExplicitSign asserts that values with an explicit leading
+// plus or minus sign are parsed and that the plus sign is only removed when
+// the StripPositiveSign option is enabled.
+func TestParsePerfDataExplicitSign(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		input  string
+		opts   nagios.PerfDataParseOptions
+		result []nagios.PerformanceData
+	}{
+		"positive sign preserved": {
+			input:  "delta=+5",
+			result: []nagios.PerformanceData{{Label: "delta", Value: "+5"}},
+		},
+		"negative sign": {
+			input:  "delta=-5",
+			result: []nagios.PerformanceData{{Label: "delta", Value: "-5"}},
+		},
+		"positive sign with uom": {
+			input:  "drift=+5.2ms",
+			result: []nagios.PerformanceData{{Label: "drift", Value: "+5.2", UnitOfMeasurement: "ms"}},
+		},
+		"positive sign in min and max": {
+			input:  "delta=+5;;;+0;+10",
+			result: []nagios.PerformanceData{{Label: "delta", Value: "+5", Min: "+0", Max: "+10"}},
+		},
+		"positive sign stripped": {
+			input:  "drift=+5.2ms;;;+0;+10",
+			opts:   nagios.PerfDataParseOptions{StripPositiveSign: true},
+			result: []nagios.PerformanceData{{Label: "drift", Value: "5.2", UnitOfMeasurement: "ms", Min: "0", Max: "10"}},
+		},
+		"negative sign unaffected by strip option": {
+			input:  "delta=-5",
+			opts:   nagios.PerfDataParseOptions{StripPositiveSign: true},
+			result: []nagios.PerformanceData{{Label: "delta", Value: "-5"}},
+		},
+	}
+
+	for name, tt := range tests {
+		tt := tt
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			perfDataResults, err := nagios.ParsePerfDataWithOptions(tt.input, tt.opts)
+			if err != nil {
+				t.Fatalf("nagios.ParsePerfDataWithOptions() error = %v", err)
+			}
+
+			// Compare strings rather than using Equal (numeric comparison)
+			// to assert that the sign is preserved or removed.
+			for i := range tt.result {
+				want := tt.result[i]
+				got := perfDataResults[i]
+				if got.Value != want.Value || got.Min != want.Min || got.Max != want.Max ||
+					got.UnitOfMeasurement != want.UnitOfMeasurement {
+					t.Errorf("\nwant %+v\ngot %+v", want, got)
+				}
+			}
+
+			testParsePerfDataCollection(t, perfDataResults, tt.result)
+		})
+	}
+}
+
+// TestPerformanceDataMarshalText asserts that valid performance data is
+// marshaled and that invalid performance data results in an error instead of
+// the structurally invalid output produced by String.
+func TestPerformanceDataMarshalText(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		pd      nagios.PerformanceData
+		want    string
+		wantErr bool
+	}{
+		"valid metric": {
+			pd:   nagios.PerformanceData{Label: "time", Value: "49", UnitOfMeasurement: "ms", Warn: "100", Crit: "200", Min: "0"},
+			want: "'time'=49ms;100;200;0;",
+		},
+		"valid undetermined metric": {
+			pd:   nagios.PerformanceData{Label: "time", Value: "U"},
+			want: "'time'=U;;;;",
+		},
+		"label with spaces": {
+			pd:   nagios.PerformanceData{Label: "percent packet loss", Value: "0", UnitOfMeasurement: "%", Warn: "20", Crit: "60", Min: "0", Max: "100"},
+			want: "'percent packet loss'=0%;20;60;0;100",
+		},
+		"value list": {
+			pd:   nagios.PerformanceData{Label: "temp", Value: "20", Values: []string{"20", "21", "22"}, UnitOfMeasurement: "C"},
+			want: "'temp'=20C;;;;",
+		},
+		"label with line break": {
+			pd:      nagios.PerformanceData{Label: "a\nb", Value: "1"},
+			wantErr: true,
+		},
+		"uom with whitespace": {
+			pd:      nagios.PerformanceData{Label: "time", Value: "49", UnitOfMeasurement: "m s"},
+			wantErr: true,
+		},
+		"warn with surrounding whitespace": {
+			pd:      nagios.PerformanceData{Label: "time", Value: "49", Warn: " 100"},
+			wantErr: true,
+		},
+		"label with single quote": {
+			pd:      nagios.PerformanceData{Label: "it's", Value: "1"},
+			wantErr: true,
+		},
+		"label with semicolon": {
+			pd:      nagios.PerformanceData{Label: "a;b", Value: "1"},
+			wantErr: true,
+		},
+		"value with whitespace": {
+			pd:      nagios.PerformanceData{Label: "time", Value: "4 9"},
+			wantErr: true,
+		},
+		"max with semicolon": {
+			pd:      nagios.PerformanceData{Label: "time", Value: "49", Max: "1;2"},
+			wantErr: true,
+		},
+		"missing value": {
+			pd:      nagios.PerformanceData{Label: "time"},
+			wantErr: true,
+		},
+	}
+
+	for name, tt := range tests {
+		tt := tt
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := tt.pd.MarshalText()
+			switch {
+			case tt.wantErr:
+				if err == nil {
+					t.Fatalf("want error for invalid metric, got output %q (String() output %q)", got, tt.pd.String())
+				}
+				return
+			case err != nil:
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if string(got) != tt.want {
+				t.Errorf("\nwant %q\ngot %q", tt.want, got)
+			}
+		})
+	}
+}
+
+// TestPerformanceDataUnmarshalText asserts that a single performance data
+// metric is decoded (including a quoted label containing spaces), that
+// marshaled metrics round trip and that multiple metrics are rejected.
+func TestPerformanceDataUnmarshalText(t *testing.T) {
+	t.Parallel()
+
+	var pd nagios.PerformanceData
+	if err := pd.UnmarshalText([]byte("'time'=49ms;100;200;0;")); err != nil {
+		t.Fatalf("failed to unmarshal performance data: %v", err)
+	}
+
+	want := nagios.PerformanceData{Label: "time", Value: "49", UnitOfMeasurement: "ms", Warn: "100", Crit: "200", Min: "0"}
+	if d := cmp.Diff(want, pd); d != "" {
+		t.Errorf("(-want, +got)\n:%s", d)
+	}
+
+	marshaled, err := pd.MarshalText()
+	if err != nil {
+		t.Fatalf("failed to marshal performance data: %v", err)
+	}
+
+	var roundTrip nagios.PerformanceData
+	if err := roundTrip.UnmarshalText(marshaled); err != nil || !roundTrip.Equal(pd) {
+		t.Errorf("round trip failed: %v, %+v", err, roundTrip)
+	}
+
+	spaced := nagios.PerformanceData{Label: "percent packet loss", Value: "0", UnitOfMeasurement: "%", Warn: "20", Crit: "60", Min: "0", Max: "100"}
+
+	marshaled, err = spaced.MarshalText()
+	if err != nil {
+		t.Fatalf("failed to marshal performance data with spaced label: %v", err)
+	}
+
+	var spacedRoundTrip nagios.PerformanceData
+	if err := spacedRoundTrip.UnmarshalText(marshaled); err != nil {
+		t.Fatalf("failed to unmarshal performance data with spaced label %q: %v", marshaled, err)
+	}
+
+	if d := cmp.Diff(spaced, spacedRoundTrip); d != "" {
+		t.Errorf("spaced label round trip (-want, +got)\n:%s", d)
+	}
+
+	err = pd.UnmarshalText([]byte("'percent packet loss'=0% 'time'=49ms"))
+	if !errors.Is(err, nagios.ErrInvalidPerformanceDataFormat) {
+		t.Errorf("\nwant error %v\ngot %v", nagios.ErrInvalidPerformanceDataFormat, err)
+	}
+
+	err = pd.UnmarshalText([]byte("load1=0.260 load5=0.320"))
+	if !errors.Is(err, nagios.ErrInvalidPerformanceDataFormat) {
+		t.Errorf("\nwant error %v\ngot %v", nagios.ErrInvalidPerformanceDataFormat, err)
+	}
+
+	if d := cmp.Diff(want, pd); d != "" {
+		t.Errorf("receiver modified after failed unmarshal (-want, +got)\n:%s", d)
+	}
+}
+
+// TestPerformanceDataRoundValue asserts that values are rounded to the given
+// number of decimal places.
+func TestPerformanceDataRoundValue(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		pd       nagios.PerformanceData
+		decimals int
+		want     string
+		wantErr  bool
+	}{
+		"round up":              {pd: nagios.PerformanceData{Label: "temp", Value: "21.4567"}, decimals: 2, want: "21.46"},
+		"round down":            {pd: nagios.PerformanceData{Label: "temp", Value: "21.4521"}, decimals: 2, want: "21.45"},
+		"round to whole number": {pd: nagios.PerformanceData{Label: "temp", Value: "21.5001"}, decimals: 0, want: "22"},
+		"pad decimals":          {pd: nagios.PerformanceData{Label: "temp", Value: "5"}, decimals: 2, want: "5.00"},
+		"negative value":        {pd: nagios.PerformanceData{Label: "temp", Value: "-0.266"}, decimals: 1, want: "-0.3"},
+		"undetermined":          {pd: nagios.PerformanceData{Label: "temp", Value: "U"}, decimals: 2, want: "U"},
+		"non-numeric":           {pd: nagios.PerformanceData{Label: "temp", Value: "abc"}, decimals: 2, wantErr: true},
+		"negative decimals":     {pd: nagios.PerformanceData{Label: "temp", Value: "1.5"}, decimals: -1, wantErr: true},
+	}
+
+	for name, tt := range tests {
+		tt := tt
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := tt.pd.RoundValue(tt.decimals)
+			switch {
+			case tt.wantErr:
+				if !errors.Is(err, nagios.ErrInvalidPerformanceDataFormat) {
+					t.Fatalf("\nwant error %v\ngot %v", nagios.ErrInvalidPerformanceDataFormat, err)
+				}
+				return
+			case err != nil:
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if got.Value != tt.want {
+				t.Errorf("\nwant %q\ngot %q", tt.want, got.Value)
+			}
+		})
+	}
+
+	original := nagios.PerformanceData{Label: "temp", Value: "1.234", Values: []string{"1.234", "5.678"}, Max: "9.999"}
+	rounded, err := original.RoundValue(1)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if d := cmp.Diff([]string{"1.2", "5.7"}, rounded.Values); d != "" {
+		t.Errorf("(-want, +got)\n:%s", d)
+	}
+
+	if original.Values[0] != "1.234" || rounded.Max != "9.999" {
+		t.Errorf("want original values and Max field unchanged, got %+v, %+v", original, rounded)
+	}
+}
+
+// TestParsePerfDataWithOptionsAllowLeadingUoM asserts that a Unit of
+// Measurement preceding the value is accepted only when the AllowLeadingUoM
+// parsing option is enabled.
+func TestParsePerfDataWithOptionsAllowLeadingUoM(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		input   string
+		opts    nagios.PerfDataParseOptions
+		result  []nagios.PerformanceData
+		wantErr bool
+	}{
+		"leading uom with option enabled": {
+			input: "temp=C20;25;30",
+			opts:  nagios.PerfDataParseOptions{AllowLeadingUoM: true},
+			result: []nagios.PerformanceData{
+				{Label: "temp", Value: "20", UnitOfMeasurement: "C", Warn: "25", Crit: "30"},
+			},
+		},
+		"leading multi-character uom with option enabled": {
+			input: "time=ms-1.5",
+			opts:  nagios.PerfDataParseOptions{AllowLeadingUoM: true},
+			result: []nagios.PerformanceData{
+				{Label: "time", Value: "-1.5", UnitOfMeasurement: "ms"},
+			},
+		},
+		"standard form with option enabled": {
+			input: "temp=20C",
+			opts:  nagios.PerfDataParseOptions{AllowLeadingUoM: true},
+			result: []nagios.PerformanceData{
+				{Label: "temp", Value: "20", UnitOfMeasurement: "C"},
+			},
+		},
+		"leading uom with option disabled": {
+			input:   "temp=C20",
+			opts:    nagios.PerfDataParseOptions{},
+			wantErr: true,
+		},
+		"uom on both sides with option enabled": {
+			input:   "temp=C20C",
+			opts:    nagios.PerfDataParseOptions{AllowLeadingUoM: true},
+			wantErr: true,
+		},
+	}
+
+	for name, tt := range tests {
+		tt := tt
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			perfDataResults, err := nagios.ParsePerfDataWithOptions(tt.input, tt.opts)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("nagios.ParsePerfDataWithOptions() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			testParsePerfDataCollection(t, perfDataResults, tt.result)
+		})
+	}
+}
+
+// BenchmarkParsePerfData measures parsing of a representative multi-metric
+// performance data string.
+func BenchmarkParsePerfData(b *testing.B) {
+	input := "load1=0.260;5.000;10.000;0; load5=0.320;4.000;6.000;0; " +
+		"load15=0.300;3.000;4.000;0; 'time'=49ms;100;200;0; " +
+		"/=2643MB;5948;5958;0;5968 'used_pct'=87%;@80:90;~:95;0;100"
+
+	b.ReportAllocs()
+
+	for i := 0; i < b.N; i++ {
+		if _, err := nagios.ParsePerfData(input); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// BenchmarkParsePerfDataSingleMetric measures parsing of the common single
+// metric (no semicolons) case.
+func BenchmarkParsePerfDataSingleMetric(b *testing.B) {
+	b.ReportAllocs()
+
+	for i := 0; i < b.N; i++ {
+		if _, err := nagios.ParsePerfData("time=49ms"); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// BenchmarkPerformanceDataValidate measures regular expression based
+// validation of a representative metric.
+func BenchmarkPerformanceDataValidate(b *testing.B) {
+	pd := nagios.PerformanceData{
+		Label: "used_pct", Value: "87", UnitOfMeasurement: "%",
+		Warn: "@80:90", Crit: "~:95", Min: "0", Max: "100",
+	}
+
+	b.ReportAllocs()
+
+	for i := 0; i < b.N; i++ {
+		if err := pd.Validate(); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// BenchmarkPerformanceDataFastValidate measures regular expression free
+// validation of the same metric used by BenchmarkPerformanceDataValidate.
+func BenchmarkPerformanceDataFastValidate(b *testing.B) {
+	pd := nagios.PerformanceData{
+		Label: "used_pct", Value: "87", UnitOfMeasurement: "%",
+		Warn: "@80:90", Crit: "~:95", Min: "0", Max: "100",
+	}
+
+	b.ReportAllocs()
+
+	for i := 0; i < b.N; i++ {
+		if err := pd.FastValidate(); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// TestPerformanceDataValidateAll asserts that all field validation failures
+// are reported at once.
+func TestPerformanceDataValidateAll(t *testing.T) {
+	t.Parallel()
+
+	pd := nagios.PerformanceData{
+		Label: "",
+		Value: "",
+		Warn:  "10ms",
+		Max:   "U",
+	}
+
+	if err := pd.Validate(); err == nil || strings.Contains(err.Error(), "Warn") {
+		t.Errorf("want Validate to fail fast on the first problem, got %v", err)
+	}
+
+	err := pd.ValidateAll()
+
+	for _, wantErr := range []error{
+		nagios.ErrInvalidPerformanceDataFormat,
+		nagios.ErrPerformanceDataMissingValue,
+		nagios.ErrPerformanceDataUnexpectedSentinel,
+	} {
+		if !errors.Is(err, wantErr) {
+			t.Errorf("\nwant error %v\ngot %v", wantErr, err)
+		}
+	}
+
+	for _, field := range []string{"Label", "Value", "Warn", "Max"} {
+		if !strings.Contains(err.Error(), "field "+field) {
+			t.Errorf("want error to report field %s, got %v", field, err)
+		}
+	}
+
+	valid := nagios.PerformanceData{Label: "time", Value: "49", UnitOfMeasurement: "ms", Warn: "100"}
+	if err := valid.ValidateAll(); err != nil {
+		t.Errorf("want nil error for valid metric, got %v", err)
+	}
+}
+
+// TestParsePerfDataWithOptionsAllowInfinityBounds asserts that infinity
+// tokens in the Min and Max fields are accepted and normalized only when the
+// AllowInfinityBounds option is enabled.
+func TestParsePerfDataWithOptionsAllowInfinityBounds(t *testing.T) {
+	t.Parallel()
+
+	allow := nagios.PerfDataParseOptions{AllowInfinityBounds: true}
+
+	tests := map[string]struct {
+		input   string
+		opts    nagios.PerfDataParseOptions
+		wantMin string
+		wantMax string
+		wantErr bool
+	}{
+		"inf max":                 {opts: allow, input: "x=1;;;0;inf", wantMax: "inf", wantMin: "0"},
+		"uppercase INF max":       {opts: allow, input: "x=1;;;0;INF", wantMax: "inf", wantMin: "0"},
+		"signed +inf max":         {opts: allow, input: "x=1;;;0;+inf", wantMax: "inf", wantMin: "0"},
+		"Infinity max":            {opts: allow, input: "x=1;;;0;Infinity", wantMax: "inf", wantMin: "0"},
+		"signed +Infinity max":    {opts: allow, input: "x=1;;;0;+Infinity", wantMax: "inf", wantMin: "0"},
+		"-inf min":                {opts: allow, input: "x=1;;;-inf;100", wantMin: "-inf", wantMax: "100"},
+		"-Infinity min":           {opts: allow, input: "x=1;;;-INFINITY;100", wantMin: "-inf", wantMax: "100"},
+		"inf max option off":      {input: "x=1;;;0;inf", opts: nagios.PerfDataParseOptions{}, wantErr: true},
+		"Infinity min option off": {input: "x=1;;;-Infinity;", opts: nagios.PerfDataParseOptions{}, wantErr: true},
+		"invalid token":           {opts: allow, input: "x=1;;;0;infinite", wantErr: true},
+	}
+
+	for name, tt := range tests {
+		tt := tt
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			perfData, err := nagios.ParsePerfDataWithOptions(tt.input, tt.opts)
+			switch {
+			case tt.wantErr:
+				if !errors.Is(err, nagios.ErrInvalidPerformanceDataFormat) {
+					t.Fatalf("\nwant error %v\ngot %v", nagios.ErrInvalidPerformanceDataFormat, err)
+				}
+				return
+			case err != nil:
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if perfData[0].Min != tt.wantMin || perfData[0].Max != tt.wantMax {
+				t.Errorf("\nwant min %q, max %q\ngot min %q, max %q", tt.wantMin, tt.wantMax, perfData[0].Min, perfData[0].Max)
+			}
+
+			// Normalized infinity tokens are valid output of this package.
+			if err := perfData[0].Validate(); err != nil {
+				t.Errorf("Validate: unexpected error: %v", err)
+			}
+
+			if err := perfData[0].FastValidate(); err != nil {
+				t.Errorf("FastValidate: unexpected error: %v", err)
+			}
+
+			text, err := perfData[0].MarshalText()
+			if err != nil {
+				t.Fatalf("MarshalText: unexpected error: %v", err)
+			}
+
+			var reparsed nagios.PerformanceData
+			if err := reparsed.UnmarshalText(text); err != nil {
+				t.Fatalf("UnmarshalText(%q): unexpected error: %v", text, err)
+			}
+
+			if reparsed.Min != tt.wantMin || reparsed.Max != tt.wantMax {
+				t.Errorf("\nwant reparsed min %q, max %q\ngot min %q, max %q", tt.wantMin, tt.wantMax, reparsed.Min, reparsed.Max)
+			}
+
+			if _, err := json.Marshal(perfData[0]); err != nil {
+				t.Errorf("MarshalJSON: unexpected error: %v", err)
+			}
+		})
+	}
+}
+
+// TestPerformanceDataMinMaxAsFloat asserts that the Min and Max fields are
+// converted to float64 values, including infinity tokens.
+func TestPerformanceDataMinMaxAsFloat(t *testing.T) {
+	t.Parallel()
+
+	pd := nagios.PerformanceData{Label: "x", Value: "1", Min: "-inf", Max: "inf"}
+
+	min, err := pd.MinAsFloat()
+	if err != nil || !math.IsInf(min, -1) {
+		t.Errorf("want negative infinity min, got %v, %v", min, err)
+	}
+
+	max, err := pd.MaxAsFloat()
+	if err != nil || !math.IsInf(max, 1) {
+		t.Errorf("want positive infinity max, got %v, %v", max, err)
+	}
+
+	pd.Min, pd.Max = "0", "100.5"
+
+	if min, err := pd.MinAsFloat(); err != nil || min != 0 {
+		t.Errorf("want min 0, got %v, %v", min, err)
+	}
+
+	if max, err := pd.MaxAsFloat(); err != nil || max != 100.5 {
+		t.Errorf("want max 100.5, got %v, %v", max, err)
+	}
+
+	pd.Min, pd.Max = "", "NaN"
+
+	if _, err := pd.MinAsFloat(); !errors.Is(err, nagios.ErrInvalidPerformanceDataFormat) {
+		t.Errorf("\nwant error %v for empty min\ngot %v", nagios.ErrInvalidPerformanceDataFormat, err)
+	}
+
+	if _, err := pd.MaxAsFloat(); !errors.Is(err, nagios.ErrInvalidPerformanceDataFormat) {
+		t.Errorf("\nwant error %v for NaN max\ngot %v", nagios.ErrInvalidPerformanceDataFormat, err)
+	}
+}
+
+// TestPerformanceDataParseValueWith asserts that a caller supplied decoder is
+// used to split a compound value into named sub-fields and that decoder
+// errors are propagated.
+func TestPerformanceDataParseValueWith(t *testing.T) {
+	t.Parallel()
+
+	// decodePairs decodes values in the form "name:value/name:value".
+	decodePairs := func(value string) (map[string]float64, error) {
+		fields := make(map[string]float64)
+		for _, pair := range strings.Split(value, "/") {
+			name, raw, found := strings.Cut(pair, ":")
+			if !found {
+				return nil, errors.New("missing sub-field separator")
+			}
+
+			num, err := strconv.ParseFloat(raw, 64)
+			if err != nil {
+				return nil, err
+			}
+			fields[name] = num
+		}
+
+		return fields, nil
+	}
+
+	pd := nagios.PerformanceData{Label: "traffic", Value: "rx:10/tx:20.5"}
+
+	got, err := pd.ParseValueWith(decodePairs)
+	if err != nil {
+		t.Fatalf("failed to decode value: %v", err)
+	}
+
+	want := map[string]float64{"rx": 10, "tx": 20.5}
+	if d := cmp.Diff(want, got); d != "" {
+		t.Errorf("(-want, +got)\n:%s", d)
+	}
+
+	pd.Value = "rx=10"
+	if _, err := pd.ParseValueWith(decodePairs); err == nil {
+		t.Error("want error for value rejected by decoder, got nil")
+	}
+
+	if _, err := pd.ParseValueWith(nil); err == nil {
+		t.Error("want error for nil decoder, got nil")
+	}
+}
+
+// TestParsePerfDataWithOptionsAllowValuelessMetrics asserts that bare labels
+// are only accepted (with an undetermined value) if the AllowValuelessMetrics
+// option is enabled.
+func TestParsePerfDataWithOptionsAllowValuelessMetrics(t *testing.T) {
+	t.Parallel()
+
+	allow := nagios.PerfDataParseOptions{AllowValuelessMetrics: true}
+
+	tests := map[string]struct {
+		input   string
+		opts    nagios.PerfDataParseOptions
+		want    []nagios.PerformanceData
+		wantErr bool
+	}{
+		"bare label": {
+			input: "load1",
+			opts:  allow,
+			want:  []nagios.PerformanceData{{Label: "load1", Value: "U"}},
+		},
+		"quoted bare label": {
+			input: "'load1'",
+			opts:  allow,
+			want:  []nagios.PerformanceData{{Label: "load1", Value: "U"}},
+		},
+		"bare label with thresholds": {
+			input: "load1;5;10",
+			opts:  allow,
+			want:  []nagios.PerformanceData{{Label: "load1", Value: "U", Warn: "5", Crit: "10"}},
+		},
+		"bare label among metrics": {
+			input: "load1=0.260 load5 load15=0.300",
+			opts:  allow,
+			want: []nagios.PerformanceData{
+				{Label: "load1", Value: "0.260"},
+				{Label: "load5", Value: "U"},
+				{Label: "load15", Value: "0.300"},
+			},
+		},
+		"equals sign without value": {
+			input:   "load1=",
+			opts:    allow,
+			wantErr: true,
+		},
+		"bare label option off": {
+			input:   "load1",
+			wantErr: true,
+		},
+		"bare label among metrics option off": {
+			input:   "load1=0.260 load5",
+			wantErr: true,
+		},
+	}
+
+	for name, tt := range tests {
+		tt := tt
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := nagios.ParsePerfDataWithOptions(tt.input, tt.opts)
+			switch {
+			case tt.wantErr:
+				if !errors.Is(err, nagios.ErrInvalidPerformanceDataFormat) {
+					t.Fatalf("\nwant error %v\ngot %v", nagios.ErrInvalidPerformanceDataFormat, err)
+				}
+				return
+			case err != nil:
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			testParsePerfDataCollection(t, got, tt.want)
+		})
+	}
+}
+
+// TestPerformanceDataSanitized asserts that invalid characters are removed or
+// escaped and that each change is described by a warning.
+func TestPerformanceDataSanitized(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		input        nagios.PerformanceData
+		want         nagios.PerformanceData
+		wantString   string
+		wantWarnings []string
+	}{
+		"already clean": {
+			input:      nagios.PerformanceData{Label: "time", Value: "49", UnitOfMeasurement: "ms"},
+			want:       nagios.PerformanceData{Label: "time", Value: "49", UnitOfMeasurement: "ms"},
+			wantString: " 'time'=49ms;;;;",
+		},
+		"quoted uom": {
+			input:      nagios.PerformanceData{Label: "disk", Value: "10", UnitOfMeasurement: `"B"`},
+			want:       nagios.PerformanceData{Label: "disk", Value: "10", UnitOfMeasurement: "B"},
+			wantString: " 'disk'=10B;;;;",
+			wantWarnings: []string{
+				`UnitOfMeasurement: removed quotes ("\"B\"" to "B")`,
+			},
+		},
+		"uom with semicolon and whitespace": {
+			input:      nagios.PerformanceData{Label: "disk", Value: "10", UnitOfMeasurement: " MB; "},
+			want:       nagios.PerformanceData{Label: "disk", Value: "10", UnitOfMeasurement: "MB"},
+			wantString: " 'disk'=10MB;;;;",
+			wantWarnings: []string{
+				`UnitOfMeasurement: removed leading and trailing whitespace (" MB; " to "MB;")`,
+				`UnitOfMeasurement: removed semicolons ("MB;" to "MB")`,
+			},
+		},
+		"label with quotes, equals sign and pipe": {
+			input:      nagios.PerformanceData{Label: "it's a=b|c", Value: "1"},
+			want:       nagios.PerformanceData{Label: `its a_b\|c`, Value: "1"},
+			wantString: ` 'its a_b\|c'=1;;;;`,
+			wantWarnings: []string{
+				`Label: removed single quotes ("it's a=b|c" to "its a=b|c")`,
+				`Label: replaced equals signs with underscores ("its a=b|c" to "its a_b|c")`,
+				`Label: escaped pipe characters ("its a_b|c" to "its a_b\\|c")`,
+			},
+		},
+		"already escaped pipe": {
+			input:      nagios.PerformanceData{Label: `a\|b`, Value: "1"},
+			want:       nagios.PerformanceData{Label: `a\|b`, Value: "1"},
+			wantString: ` 'a\|b'=1;;;;`,
+		},
+	}
+
+	for name, tt := range tests {
+		tt := tt
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			got, warnings := tt.input.Sanitized()
+
+			if d := cmp.Diff(tt.want, got); d != "" {
+				t.Errorf("(-want, +got)\n:%s", d)
+			}
+
+			if got.String() != tt.wantString {
+				t.Errorf("\nwant %q\ngot %q", tt.wantString, got.String())
+			}
+
+			if d := cmp.Diff(tt.wantWarnings, warnings); d != "" {
+				t.Errorf("(-want, +got)\n:%s", d)
+			}
+		})
+	}
+}
+
+// TestParsePerfDataWithOptionsAllowNaN asserts that the NaN token is only
+// accepted (as the undetermined value) if the AllowNaN option is enabled.
+func TestParsePerfDataWithOptionsAllowNaN(t *testing.T) {
+	t.Parallel()
+
+	allow := nagios.PerfDataParseOptions{AllowNaN: true}
+
+	tests := map[string]struct {
+		input   string
+		opts    nagios.PerfDataParseOptions
+		want    []nagios.PerformanceData
+		wantErr bool
+	}{
+		"NaN": {
+			input: "ratio=NaN",
+			opts:  allow,
+			want:  []nagios.PerformanceData{{Label: "ratio", Value: "U"}},
+		},
+		"lowercase nan with thresholds": {
+			input: "ratio=nan;0.5;0.9;0;1",
+			opts:  allow,
+			want:  []nagios.PerformanceData{{Label: "ratio", Value: "U", Warn: "0.5", Crit: "0.9", Min: "0", Max: "1"}},
+		},
+		"NaN among metrics": {
+			input: "ratio=NAN hits=10c",
+			opts:  allow,
+			want: []nagios.PerformanceData{
+				{Label: "ratio", Value: "U"},
+				{Label: "hits", Value: "10", UnitOfMeasurement: "c"},
+			},
+		},
+		"NaN with uom": {
+			input:   "ratio=NaN%",
+			opts:    allow,
+			wantErr: true,
+		},
+		"NaN option off": {
+			input:   "ratio=NaN",
+			wantErr: true,
+		},
+	}
+
+	for name, tt := range tests {
+		tt := tt
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := nagios.ParsePerfDataWithOptions(tt.input, tt.opts)
+			switch {
+			case tt.wantErr:
+				if !errors.Is(err, nagios.ErrInvalidPerformanceDataFormat) {
+					t.Fatalf("\nwant error %v\ngot %v", nagios.ErrInvalidPerformanceDataFormat, err)
+				}
+				return
+			case err != nil:
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			testParsePerfDataCollection(t, got, tt.want)
+
+			if !got[0].IsValueUndetermined() {
+				t.Errorf("want undetermined value, got %q", got[0].Value)
+			}
+		})
+	}
+}
+
+// TestParsePerfDataWithOptionsStrictPercentage asserts that percentage values
+// outside of the permitted range are only rejected if the StrictPercentage
+// option is enabled.
+func TestParsePerfDataWithOptionsStrictPercentage(t *testing.T) {
+	t.Parallel()
+
+	strict := nagios.PerfDataParseOptions{StrictPercentage: true}
+
+	tests := map[string]struct {
+		input   string
+		opts    nagios.PerfDataParseOptions
+		wantErr bool
+	}{
+		"negative strict":                {input: "cpu=-5%", opts: strict, wantErr: true},
+		"above 100 strict":               {input: "cpu=150%", opts: strict, wantErr: true},
+		"negative permissive":            {input: "cpu=-5%"},
+		"above 100 permissive":           {input: "cpu=150%"},
+		"lower bound strict":             {input: "cpu=0%", opts: strict},
+		"upper bound strict":             {input: "cpu=100%", opts: strict},
+		"within explicit max strict":     {input: "cpu=150%;;;0;400", opts: strict},
+		"above explicit max strict":      {input: "cpu=450%;;;0;400", opts: strict, wantErr: true},
+		"below explicit min strict":      {input: "cpu=5%;;;10;", opts: strict, wantErr: true},
+		"undetermined strict":            {input: "cpu=U", opts: strict},
+		"non-percentage strict":          {input: "time=150s", opts: strict},
+		"value list out of range strict": {input: "cpu=5,150%", opts: nagios.PerfDataParseOptions{StrictPercentage: true, AllowValueList: true}, wantErr: true},
+	}
+
+	for name, tt := range tests {
+		tt := tt
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := nagios.ParsePerfDataWithOptions(tt.input, tt.opts)
+			switch {
+			case tt.wantErr && !errors.Is(err, nagios.ErrPerformanceDataPercentOutOfRange):
+				t.Errorf("\nwant error %v\ngot %v", nagios.ErrPerformanceDataPercentOutOfRange, err)
+			case !tt.wantErr && err != nil:
+				t.Errorf("unexpected error: %v", err)
+			}
+		})
+	}
+}
+
+// TestPerformanceDataHash asserts that equivalent performance data produce
+// the same hash and that real differences produce different hashes.
+func TestPerformanceDataHash(t *testing.T) {
+	t.Parallel()
+
+	base := nagios.PerformanceData{
+		Label:             "load1",
+		Value:             "10",
+		UnitOfMeasurement: "s",
+		Warn:              "5",
+		Crit:              "20",
+		Min:               "0",
+		Max:               "100",
+	}
+
+	parsed, err := nagios.ParseSinglePerfData("'load1'=10.0s;5;20;0.0;100.000")
+	if err != nil {
+		t.Fatalf("failed to parse performance data: %v", err)
+	}
+
+	equivalent := map[string]nagios.PerformanceData{
+		"identical":          base,
+		"parsed":             parsed,
+		"trailing zeros":     {Label: "load1", Value: "10.0", UnitOfMeasurement: "s", Warn: "5", Crit: "20", Min: "0.0", Max: "100.00"},
+		"explicit sign":      {Label: "load1", Value: "+10", UnitOfMeasurement: "s", Warn: "5", Crit: "20", Min: "-0", Max: "1e2"},
+		"label case differs": {Label: "LOAD1", Value: "10", UnitOfMeasurement: "s", Warn: "5", Crit: "20", Min: "0", Max: "100"},
+	}
+
+	for name, pd := range equivalent {
+		if pd.Hash() != base.Hash() {
+			t.Errorf("%s: want hash %d, got %d", name, base.Hash(), pd.Hash())
+		}
+	}
+
+	different := map[string]func(pd *nagios.PerformanceData){
+		"label":  func(pd *nagios.PerformanceData) { pd.Label = "load5" },
+		"value":  func(pd *nagios.PerformanceData) { pd.Value = "10.5" },
+		"uom":    func(pd *nagios.PerformanceData) { pd.UnitOfMeasurement = "ms" },
+		"warn":   func(pd *nagios.PerformanceData) { pd.Warn = "6" },
+		"crit":   func(pd *nagios.PerformanceData) { pd.Crit = "" },
+		"min":    func(pd *nagios.PerformanceData) { pd.Min = "1" },
+		"max":    func(pd *nagios.PerformanceData) { pd.Max = "" },
+		"values": func(pd *nagios.PerformanceData) { pd.Values = []string{"10", "11"} },
+		"shifted fields": func(pd *nagios.PerformanceData) {
+			pd.Warn, pd.Crit = pd.Warn+pd.Crit, ""
+		},
+	}
+
+	for name, modify := range different {
+		pd := base.Clone()
+		modify(&pd)
+
+		if pd.Hash() == base.Hash() {
+			t.Errorf("%s: want different hash, got %d for both", name, pd.Hash())
+		}
+	}
+
+	if base.Hash() != base.Clone().Hash() {
+		t.Error("want stable hash across calls")
+	}
+}
+
+// TestParsePerfDataWithOptionsMetricSeparators asserts that metrics are
+// split using only the specified separator characters if set.
+func TestParsePerfDataWithOptionsMetricSeparators(t *testing.T) {
+	t.Parallel()
+
+	newlineOnly := nagios.PerfDataParseOptions{MetricSeparators: "\n"}
+
+	tests := map[string]struct {
+		input   string
+		opts    nagios.PerfDataParseOptions
+		want    []nagios.PerformanceData
+		wantErr bool
+	}{
+		"newline only": {
+			input: "load1=0.260;5;10\nload5=0.320;4;6\n",
+			opts:  newlineOnly,
+			want: []nagios.PerformanceData{
+				{Label: "load1", Value: "0.260", Warn: "5", Crit: "10"},
+				{Label: "load5", Value: "0.320", Warn: "4", Crit: "6"},
+			},
+		},
+		"newline only preserves whitespace in quoted labels": {
+			input: "'disk used'=80%\n\n  'eth0\trx'=1024c  \n",
+			opts:  newlineOnly,
+			want: []nagios.PerformanceData{
+				{Label: "disk used", Value: "80", UnitOfMeasurement: "%"},
+				{Label: "eth0\trx", Value: "1024", UnitOfMeasurement: "c"},
+			},
+		},
+		"newline and tab": {
+			input: "load1=0.260\tload5=0.320\nload15=0.300",
+			opts:  nagios.PerfDataParseOptions{MetricSeparators: "\n\t"},
+			want: []nagios.PerformanceData{
+				{Label: "load1", Value: "0.260"},
+				{Label: "load5", Value: "0.320"},
+				{Label: "load15", Value: "0.300"},
+			},
+		},
+		"newline only with space separated metrics": {
+			input:   "load1=0.260 load5=0.320",
+			opts:    newlineOnly,
+			wantErr: true,
+		},
+		"default splits on all whitespace": {
+			input: "load1=0.260\tload5=0.320\nload15=0.300 load30=0.1",
+			want: []nagios.PerformanceData{
+				{Label: "load1", Value: "0.260"},
+				{Label: "load5", Value: "0.320"},
+				{Label: "load15", Value: "0.300"},
+				{Label: "load30", Value: "0.1"},
+			},
+		},
+	}
+
+	for name, tt := range tests {
+		tt := tt
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := nagios.ParsePerfDataWithOptions(tt.input, tt.opts)
+			switch {
+			case tt.wantErr:
+				if !errors.Is(err, nagios.ErrInvalidPerformanceDataFormat) {
+					t.Fatalf("\nwant error %v\ngot %v", nagios.ErrInvalidPerformanceDataFormat, err)
+				}
+				return
+			case err != nil:
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			testParsePerfDataCollection(t, got, tt.want)
+		})
+	}
+}
+
+// TestPerformanceDataIsZeroValue asserts that only values parsing as exactly
+// zero are reported as zero.
+func TestPerformanceDataIsZeroValue(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		value string
+		want  bool
+	}{
+		"zero":          {value: "0", want: true},
+		"zero decimal":  {value: "0.0", want: true},
+		"negative zero": {value: "-0", want: true},
+		"undetermined":  {value: "U", want: false},
+		"non-zero":      {value: "0.001", want: false},
+		"negative":      {value: "-1", want: false},
+		"non-numeric":   {value: "abc", want: false},
+		"empty":         {value: "", want: false},
+	}
+
+	for name, tt := range tests {
+		tt := tt
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			pd := nagios.PerformanceData{Label: "x", Value: tt.value}
+			if got := pd.IsZeroValue(); got != tt.want {
+				t.Errorf("\nwant %t\ngot %t", tt.want, got)
+			}
+		})
+	}
+}
+
+// TestParsePerfDataWithOptionsEuropeanNumberFormat asserts that values using
+// periods as thousands separators and a comma as decimal separator are
+// normalized if the EuropeanNumberFormat option is enabled and that
+// malformed or ambiguous values are rejected.
+func TestParsePerfDataWithOptionsEuropeanNumberFormat(t *testing.T) {
+	t.Parallel()
+
+	european := nagios.PerfDataParseOptions{EuropeanNumberFormat: true}
+
+	tests := map[string]struct {
+		input     string
+		opts      nagios.PerfDataParseOptions
+		wantValue string
+		wantUoM   string
+		wantErr   bool
+	}{
+		"thousands and decimal":      {input: "used=1.234,56MB", opts: european, wantValue: "1234.56", wantUoM: "MB"},
+		"multiple thousands groups":  {input: "bytes=12.345.678B", opts: european, wantValue: "12345678", wantUoM: "B"},
+		"decimal only":               {input: "temp=23,5C", opts: european, wantValue: "23.5", wantUoM: "C"},
+		"negative value":             {input: "delta=-1.000,5s", opts: european, wantValue: "-1000.5", wantUoM: "s"},
+		"integer":                    {input: "users=42", opts: european, wantValue: "42"},
+		"period decimal passthrough": {input: "load=0.260", opts: european, wantValue: "0.260"},
+		"multiple commas":            {input: "x=1,2,3", opts: european, wantErr: true},
+		"period after comma":         {input: "x=1,234.5", opts: european, wantErr: true},
+		"short thousands group":      {input: "x=1.23,5", opts: european, wantErr: true},
+		"long first group":           {input: "x=1234.567,8", opts: european, wantErr: true},
+		"ambiguous single period":    {input: "x=1.234", opts: european, wantErr: true},
+		"trailing comma":             {input: "x=1,", opts: european, wantErr: true},
+		"option off":                 {input: "used=1.234,56MB", wantErr: true},
+	}
+
+	for name, tt := range tests {
+		tt := tt
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := nagios.ParsePerfDataWithOptions(tt.input, tt.opts)
+			switch {
+			case tt.wantErr:
+				if !errors.Is(err, nagios.ErrInvalidPerformanceDataFormat) {
+					t.Fatalf("\nwant error %v\ngot %v", nagios.ErrInvalidPerformanceDataFormat, err)
+				}
+				return
+			case err != nil:
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if got[0].Value != tt.wantValue || got[0].UnitOfMeasurement != tt.wantUoM {
+				t.Errorf(
+					"\nwant value %q, uom %q\ngot value %q, uom %q",
+					tt.wantValue,
+					tt.wantUoM,
+					got[0].Value,
+					got[0].UnitOfMeasurement,
+				)
+			}
+		})
+	}
+}
+
+// TestParsePerfDataWithOptionsRequireUTF8Labels asserts that labels which
+// are not valid UTF-8 are only rejected if the RequireUTF8Labels option is
+// enabled.
+func TestParsePerfDataWithOptionsRequireUTF8Labels(t *testing.T) {
+	t.Parallel()
+
+	strict := nagios.PerfDataParseOptions{RequireUTF8Labels: true}
+
+	tests := map[string]struct {
+		input     string
+		opts      nagios.PerfDataParseOptions
+		wantLabel string
+		wantErr   bool
+	}{
+		"ascii label strict":        {input: "load1=0.260", opts: strict, wantLabel: "load1"},
+		"multibyte label strict":    {input: "température=21.5C", opts: strict, wantLabel: "température"},
+		"multibyte label quoted":    {input: "'磁盘'=80%", opts: strict, wantLabel: "磁盘"},
+		"invalid byte strict":       {input: "temp\xffrature=21.5C", opts: strict, wantErr: true},
+		"truncated sequence strict": {input: "temp\xc3=21.5C", opts: strict, wantErr: true},
+		"invalid byte permissive":   {input: "temp\xffrature=21.5C", wantLabel: "temp\xffrature"},
+	}
+
+	for name, tt := range tests {
+		tt := tt
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := nagios.ParsePerfDataWithOptions(tt.input, tt.opts)
+			switch {
+			case tt.wantErr:
+				if !errors.Is(err, nagios.ErrPerformanceDataInvalidUTF8) {
+					t.Fatalf("\nwant error %v\ngot %v", nagios.ErrPerformanceDataInvalidUTF8, err)
+				}
+				return
+			case err != nil:
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if got[0].Label != tt.wantLabel {
+				t.Errorf("\nwant label %q\ngot %q", tt.wantLabel, got[0].Label)
+			}
+		})
+	}
+}
+
+// TestParsePerfDataWithWarnings asserts that warnings are returned only when
+// a lenient parsing path was taken and identify the affected metric.
+func TestParsePerfDataWithWarnings(t *testing.T) {
+	t.Parallel()
+
+	lenient := nagios.PerfDataParseOptions{
+		AllowCommaDecimal:       true,
+		AllowNaN:                true,
+		UndeterminedValueTokens: []string{"N/A"},
+		CanonicalizeUoM:         true,
+		NormalizeLabelCase:      true,
+		StripPositiveSign:       true,
+		AllowLeadingUoM:         true,
+		AllowInfinityBounds:     true,
+		AllowValuelessMetrics:   true,
+		TrimTrailingGarbage:     true,
+	}
+
+	tests := map[string]struct {
+		input        string
+		opts         nagios.PerfDataParseOptions
+		wantWarnings []string
+	}{
+		"standard input with lenient options": {
+			input: "load1=0.260;5;10;0; time=49ms",
+			opts:  lenient,
+		},
+		"standard input without options": {
+			input: "load1=0.260;5;10;0; time=49ms",
+		},
+		"comma decimal": {
+			input:        "load1=0.260 temp=23,5C",
+			opts:         lenient,
+			wantWarnings: []string{`metric 1: converted comma decimal separator in "23,5C" to "23.5C"`},
+		},
+		"NaN and undetermined token": {
+			input: "ratio=NaN hits=N/A",
+			opts:  lenient,
+			wantWarnings: []string{
+				`metric 0: mapped "NaN" to "U"`,
+				`metric 1: mapped undetermined value token "N/A" to "U"`,
+			},
+		},
+		"label case and uom": {
+			input: "Shm=2kb",
+			opts:  lenient,
+			wantWarnings: []string{
+				`metric 0: lowercased label "Shm"`,
+				`metric 0: canonicalized unit of measurement "kb" to "KB"`,
+			},
+		},
+		"positive signs and infinity": {
+			input: "delta=+5;;;+0;inf",
+			opts:  lenient,
+			wantWarnings: []string{
+				`metric 0: removed positive sign from value "+5"`,
+				`metric 0: accepted infinity token "inf" in max field`,
+				`metric 0: removed positive sign from min field "+0"`,
+			},
+		},
+		"leading uom and valueless metric": {
+			input: "price=$5 broken",
+			opts:  lenient,
+			wantWarnings: []string{
+				`metric 0: accepted leading unit of measurement in "$5"`,
+				`metric 1: missing value for label "broken"; using undetermined value`,
+			},
+		},
+		"value list": {
+			input:        "temp=20,21,22C",
+			opts:         nagios.PerfDataParseOptions{AllowValueList: true},
+			wantWarnings: []string{"metric 0: accepted list of 3 values"},
+		},
+		"trailing garbage": {
+			input:        "\"load1=0.260 load5=0.320;;;0;\".\r\n",
+			opts:         lenient,
+			wantWarnings: []string{`metric 1: removed trailing characters ".\r\n"`},
+		},
+	}
+
+	for name, tt := range tests {
+		tt := tt
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			perfData, warnings, err := nagios.ParsePerfDataWithWarnings(tt.input, tt.opts)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if d := cmp.Diff(tt.wantWarnings, warnings); d != "" {
+				t.Errorf("(-want, +got)\n:%s", d)
+			}
+
+			want, err := nagios.ParsePerfDataWithOptions(tt.input, tt.opts)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			testParsePerfDataCollection(t, perfData, want)
+		})
+	}
+
+	_, warnings, err := nagios.ParsePerfDataWithWarnings("temp=23,5C bad=", lenient)
+	if !errors.Is(err, nagios.ErrInvalidPerformanceDataFormat) {
+		t.Errorf("\nwant error %v\ngot %v", nagios.ErrInvalidPerformanceDataFormat, err)
+	}
+
+	if len(warnings) != 1 {
+		t.Errorf("want warnings collected prior to failure, got %q", warnings)
+	}
+}
+
+// TestPerformanceDataAnnotations asserts that annotations are retained by
+// copies and JSON encoding but never emitted in plugin output.
+func TestPerformanceDataAnnotations(t *testing.T) {
+	t.Parallel()
+
+	original := nagios.PerformanceData{Label: "time", Value: "49", UnitOfMeasurement: "ms", Warn: "100"}
+
+	annotated := original.
+		WithAnnotation("note", "measured from secondary site").
+		WithAnnotation("owner", "ops")
+
+	if _, ok := original.Annotation("note"); ok {
+		t.Error("want original value unmodified by WithAnnotation")
+	}
+
+	if got, ok := annotated.Annotation("note"); !ok || got != "measured from secondary site" {
+		t.Errorf("want annotation %q, got %q, %t", "measured from secondary site", got, ok)
+	}
+
+	if _, ok := annotated.Annotation("missing"); ok {
+		t.Error("want missing annotation to be reported as absent")
+	}
+
+	if annotated.String() != original.String() {
+		t.Errorf("\nwant %q\ngot %q", original.String(), annotated.String())
+	}
+
+	if strings.Contains(nagios.FormatPerfDataLine([]nagios.PerformanceData{annotated}), "secondary") {
+		t.Error("annotation emitted in performance data output")
+	}
+
+	text, err := annotated.MarshalText()
+	if err != nil || strings.Contains(string(text), "secondary") {
+		t.Errorf("annotation emitted in text output %q (error %v)", text, err)
+	}
+
+	if !annotated.Equal(original) || annotated.Hash() != original.Hash() {
+		t.Error("want annotations ignored by Equal and Hash")
+	}
+
+	clone := annotated.Clone()
+	modified := clone.WithAnnotation("note", "changed")
+	if got, _ := clone.Annotation("note"); got != "measured from secondary site" {
+		t.Errorf("want clone unaffected by later annotation, got %q", got)
+	}
+	if got, _ := modified.Annotation("note"); got != "changed" {
+		t.Errorf("want replaced annotation %q, got %q", "changed", got)
+	}
+}
+
+// TestPerformanceDataJSON asserts that performance data is encoded as a JSON
+// string unless annotated and that both forms can be decoded, including for
+// a quoted label containing spaces.
+func TestPerformanceDataJSON(t *testing.T) {
+	t.Parallel()
+
+	pd := nagios.PerformanceData{Label: "time", Value: "49", UnitOfMeasurement: "ms", Warn: "100"}
+
+	plain, err := json.Marshal(pd)
+	if err != nil {
+		t.Fatalf("failed to marshal performance data: %v", err)
+	}
+
+	if want := `"'time'=49ms;100;;;"`; string(plain) != want {
+		t.Errorf("\nwant %s\ngot %s", want, plain)
+	}
+
+	annotated := pd.WithAnnotation("note", "secondary site")
+
+	encoded, err := json.Marshal(annotated)
+	if err != nil {
+		t.Fatalf("failed to marshal annotated performance data: %v", err)
+	}
+
+	want := `{"metric":"'time'=49ms;100;;;","annotations":{"note":"secondary site"}}`
+	if string(encoded) != want {
+		t.Errorf("\nwant %s\ngot %s", want, encoded)
+	}
+
+	for name, data := range map[string][]byte{"plain": plain, "annotated": encoded} {
+		var decoded nagios.PerformanceData
+		if err := json.Unmarshal(data, &decoded); err != nil {
+			t.Fatalf("%s: failed to unmarshal performance data: %v", name, err)
+		}
+
+		if d := cmp.Diff(pd, decoded); d != "" {
+			t.Errorf("%s: (-want, +got)\n:%s", name, d)
+		}
+
+		note, ok := decoded.Annotation("note")
+		if wantOK := name == "annotated"; ok != wantOK || (ok && note != "secondary site") {
+			t.Errorf("%s: unexpected annotation %q, %t", name, note, ok)
+		}
+	}
+
+	spaced := nagios.PerformanceData{Label: "percent packet loss", Value: "0", UnitOfMeasurement: "%", Warn: "20", Crit: "60"}
+
+	spacedTests := map[string]struct {
+		pd   nagios.PerformanceData
+		want string
+	}{
+		"spaced label": {
+			pd:   spaced,
+			want: `"'percent packet loss'=0%;20;60;;"`,
+		},
+		"annotated spaced label": {
+			pd:   spaced.WithAnnotation("site", "dr"),
+			want: `{"metric":"'percent packet loss'=0%;20;60;;","annotations":{"site":"dr"}}`,
+		},
+	}
+
+	for name, tt := range spacedTests {
+		encoded, err := json.Marshal(tt.pd)
+		if err != nil {
+			t.Fatalf("%s: failed to marshal performance data: %v", name, err)
+		}
+
+		if string(encoded) != tt.want {
+			t.Errorf("%s:\nwant %s\ngot %s", name, tt.want, encoded)
+		}
+
+		var decoded nagios.PerformanceData
+		if err := json.Unmarshal(encoded, &decoded); err != nil {
+			t.Fatalf("%s: failed to unmarshal performance data: %v", name, err)
+		}
+
+		if d := cmp.Diff(spaced, decoded); d != "" {
+			t.Errorf("%s: (-want, +got)\n:%s", name, d)
+		}
+
+		wantSite, wantOK := tt.pd.Annotation("site")
+		if site, ok := decoded.Annotation("site"); site != wantSite || ok != wantOK {
+			t.Errorf("%s:\nwant annotation %q, %t\ngot %q, %t", name, wantSite, wantOK, site, ok)
+		}
+	}
+
+	var decoded nagios.PerformanceData
+	if err := json.Unmarshal([]byte(`{"metric":"time="}`), &decoded); !errors.Is(err, nagios.ErrInvalidPerformanceDataFormat) {
+		t.Errorf("\nwant error %v\ngot %v", nagios.ErrInvalidPerformanceDataFormat, err)
+	}
+
+	if err := json.Unmarshal([]byte(`42`), &decoded); err == nil {
+		t.Error("want error for unsupported JSON value, got nil")
+	}
+}
+
+// TestParsePerfDataWithOptionsRejectUndeterminedWithThresholds asserts that
+// undetermined values with thresholds are only rejected if the
+// RejectUndeterminedWithThresholds option is enabled.
+func TestParsePerfDataWithOptionsRejectUndeterminedWithThresholds(t *testing.T) {
+	t.Parallel()
+
+	reject := nagios.PerfDataParseOptions{RejectUndeterminedWithThresholds: true}
+
+	tests := map[string]struct {
+		input   string
+		opts    nagios.PerfDataParseOptions
+		wantErr bool
+	}{
+		"undetermined with thresholds":         {input: "x=U;10;20", opts: reject, wantErr: true},
+		"undetermined with warn only":          {input: "x=U;10", opts: reject, wantErr: true},
+		"undetermined with crit only":          {input: "x=U;;20", opts: reject, wantErr: true},
+		"undetermined with min and max only":   {input: "x=U;;;0;100", opts: reject},
+		"undetermined without thresholds":      {input: "x=U", opts: reject},
+		"value with thresholds":                {input: "x=5;10;20", opts: reject},
+		"undetermined with thresholds default": {input: "x=U;10;20"},
+	}
+
+	for name, tt := range tests {
+		tt := tt
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := nagios.ParsePerfDataWithOptions(tt.input, tt.opts)
+			switch {
+			case tt.wantErr && !errors.Is(err, nagios.ErrPerformanceDataUndeterminedWithThresholds):
+				t.Errorf("\nwant error %v\ngot %v", nagios.ErrPerformanceDataUndeterminedWithThresholds, err)
+			case !tt.wantErr && err != nil:
+				t.Errorf("unexpected error: %v", err)
+			}
+		})
+	}
+}
+
+// TestPerformanceDataScale asserts that values, bounds and thresholds are
+// scaled and the Unit of Measurement replaced.
+func TestPerformanceDataScale(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		input   nagios.PerformanceData
+		factor  float64
+		uom     string
+		want    nagios.PerformanceData
+		wantErr bool
+	}{
+		"bytes to megabytes": {
+			input: nagios.PerformanceData{
+				Label: "used", Value: "104857600", UnitOfMeasurement: "B",
+				Warn: "83886080", Crit: "94371840", Min: "0", Max: "209715200",
+			},
+			factor: 1.0 / 1048576,
+			uom:    "MB",
+			want: nagios.PerformanceData{
+				Label: "used", Value: "100", UnitOfMeasurement: "MB",
+				Warn: "80", Crit: "90", Min: "0", Max: "200",
+			},
+		},
+		"ranges and open-ended thresholds": {
+			input: nagios.PerformanceData{
+				Label: "used", Value: "1572864", UnitOfMeasurement: "B",
+				Warn: "1048576:", Crit: "@~:524288",
+			},
+			factor: 1.0 / 1048576,
+			uom:    "MB",
+			want: nagios.PerformanceData{
+				Label: "used", Value: "1.5", UnitOfMeasurement: "MB",
+				Warn: "1:", Crit: "@~:0.5",
+			},
+		},
+		"seconds to milliseconds": {
+			input:  nagios.PerformanceData{Label: "time", Value: "0.26", UnitOfMeasurement: "s", Warn: "1"},
+			factor: 1000,
+			uom:    "ms",
+			want:   nagios.PerformanceData{Label: "time", Value: "260", UnitOfMeasurement: "ms", Warn: "1000"},
+		},
+		"undetermined value": {
+			input:  nagios.PerformanceData{Label: "used", Value: "U", UnitOfMeasurement: "B", Max: "2048"},
+			factor: 1.0 / 1024,
+			uom:    "KB",
+			want:   nagios.PerformanceData{Label: "used", Value: "U", UnitOfMeasurement: "KB", Max: "2"},
+		},
+		"infinity bound": {
+			input:  nagios.PerformanceData{Label: "used", Value: "2048", UnitOfMeasurement: "B", Min: "0", Max: "inf"},
+			factor: 1.0 / 1024,
+			uom:    "KB",
+			want:   nagios.PerformanceData{Label: "used", Value: "2", UnitOfMeasurement: "KB", Min: "0", Max: "inf"},
+		},
+		"non-numeric value": {
+			input:   nagios.PerformanceData{Label: "used", Value: "abc"},
+			factor:  2,
+			wantErr: true,
+		},
+		"non-numeric max": {
+			input:   nagios.PerformanceData{Label: "used", Value: "1", Max: "abc"},
+			factor:  2,
+			wantErr: true,
+		},
+		"unparseable threshold": {
+			input:   nagios.PerformanceData{Label: "used", Value: "1", Crit: "@@"},
+			factor:  2,
+			wantErr: true,
+		},
+		"zero factor": {
+			input:   nagios.PerformanceData{Label: "used", Value: "1"},
+			factor:  0,
+			wantErr: true,
+		},
+		"negative factor": {
+			input:   nagios.PerformanceData{Label: "used", Value: "1"},
+			factor:  -1,
+			wantErr: true,
+		},
+	}
+
+	for name, tt := range tests {
+		tt := tt
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := tt.input.Scale(tt.factor, tt.uom)
+			switch {
+			case tt.wantErr:
+				if !errors.Is(err, nagios.ErrInvalidPerformanceDataFormat) {
+					t.Fatalf("\nwant error %v\ngot %v", nagios.ErrInvalidPerformanceDataFormat, err)
+				}
+				return
+			case err != nil:
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			// Compare as strings; Equal considers numerically equal values
+			// such as "100" and "100.0" to be the same.
+			if got.String() != tt.want.String() {
+				t.Errorf("\nwant %q\ngot %q", tt.want.String(), got.String())
+			}
+		})
+	}
+}
+
+// TestPerformanceDataOutputSize asserts that computed output sizes match the
+// length of the output generated by FormatPerfDataLine.
+func TestPerformanceDataOutputSize(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string][]nagios.PerformanceData{
+		"empty": {},
+		"single metric": {
+			{Label: "time", Value: "49", UnitOfMeasurement: "ms", Warn: "100", Crit: "200"},
+		},
+		"multiple metrics": {
+			{Label: "load1", Value: "0.260", Warn: "5.000", Crit: "10.000", Min: "0"},
+			{Label: "load5", Value: "0.320", Warn: "4.000", Crit: "6.000", Min: "0"},
+			{Label: "load15", Value: "0.300", Warn: "3.000", Crit: "4.000", Min: "0"},
+		},
+		"multibyte label": {
+			{Label: "température", Value: "21.5", UnitOfMeasurement: "C"},
+			{Label: "users", Value: "U"},
+		},
+	}
+
+	for name, pd := range tests {
+		pd := pd
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			want := len(nagios.FormatPerfDataLine(pd))
+			if got := nagios.TotalOutputSize(pd); got != want {
+				t.Errorf("\nwant total %d\ngot %d", want, got)
+			}
+
+			for i := range pd {
+				want := len(nagios.FormatPerfDataLine(pd[i : i+1]))
+				if got := pd[i].OutputSize(); got != want {
+					t.Errorf("\nwant size %d for metric %q\ngot %d", want, pd[i].Label, got)
+				}
+			}
+		})
+	}
+}
+
+// TestParsePerfDataWithOptionsStripThresholdUnits asserts that a unit
+// matching the metric's Unit of Measurement is only removed from thresholds
+// if the StripThresholdUnits option is enabled.
+func TestParsePerfDataWithOptionsStripThresholdUnits(t *testing.T) {
+	t.Parallel()
+
+	strip := nagios.PerfDataParseOptions{StripThresholdUnits: true}
+
+	tests := map[string]struct {
+		input    string
+		opts     nagios.PerfDataParseOptions
+		wantWarn string
+		wantCrit string
+		wantErr  bool
+	}{
+		"percent thresholds":          {input: "usage=50%;80%;90%;0;100", opts: strip, wantWarn: "80", wantCrit: "90"},
+		"percent range":               {input: "usage=50%;10%:80%;@90%:95%", opts: strip, wantWarn: "10:80", wantCrit: "@90:95"},
+		"mixed with and without unit": {input: "usage=50%;80%;90", opts: strip, wantWarn: "80", wantCrit: "90"},
+		"multi-character unit":        {input: "used=5MB;80MB;90MB", opts: strip, wantWarn: "80", wantCrit: "90"},
+		"no unit in thresholds":       {input: "usage=50%;80;90", opts: strip, wantWarn: "80", wantCrit: "90"},
+		"mismatched unit":             {input: "used=5MB;80KB;90", opts: strip, wantErr: true},
+		"unit without metric unit":    {input: "used=5;80%;90", opts: strip, wantErr: true},
+		"percent thresholds strict":   {input: "usage=50%;80%;90%;0;100", wantErr: true},
+	}
+
+	for name, tt := range tests {
+		tt := tt
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := nagios.ParsePerfDataWithOptions(tt.input, tt.opts)
+			switch {
+			case tt.wantErr:
+				if !errors.Is(err, nagios.ErrInvalidPerformanceDataFormat) {
+					t.Fatalf("\nwant error %v\ngot %v", nagios.ErrInvalidPerformanceDataFormat, err)
+				}
+				return
+			case err != nil:
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if got[0].Warn != tt.wantWarn || got[0].Crit != tt.wantCrit {
+				t.Errorf(
+					"\nwant warn %q, crit %q\ngot warn %q, crit %q",
+					tt.wantWarn,
+					tt.wantCrit,
+					got[0].Warn,
+					got[0].Crit,
+				)
+			}
+		})
+	}
+}
+
+// TestParsePerfDataWithOptionsDecodePercentEncodedLabels asserts that
+// percent-encoded labels are only decoded if the DecodePercentEncodedLabels
+// option is enabled and that decoded labels are validated.
+func TestParsePerfDataWithOptionsDecodePercentEncodedLabels(t *testing.T) {
+	t.Parallel()
+
+	decode := nagios.PerfDataParseOptions{DecodePercentEncodedLabels: true}
+
+	tests := map[string]struct {
+		input     string
+		opts      nagios.PerfDataParseOptions
+		wantLabel string
+		wantErr   bool
+	}{
+		"encoded space":            {input: "cpu%20load=0.5", opts: decode, wantLabel: "cpu load"},
+		"encoded slash and quoted": {input: "'%2Fvar%2Flog'=80%", opts: decode, wantLabel: "/var/log"},
+		"encoded multibyte":        {input: "temp%C3%A9rature=21C", opts: decode, wantLabel: "température"},
+		"plus sign kept":           {input: "cpu+load=0.5", opts: decode, wantLabel: "cpu+load"},
+		"not encoded":              {input: "load1=0.5", opts: decode, wantLabel: "load1"},
+		"encoded space disabled":   {input: "cpu%20load=0.5", wantLabel: "cpu%20load"},
+		"malformed escape":         {input: "cpu%2=0.5", opts: decode, wantErr: true},
+		"decoded equals sign":      {input: "a%3Db=0.5", opts: decode, wantErr: true},
+		"decoded single quote":     {input: "it%27s=0.5", opts: decode, wantErr: true},
+		"decoded to whitespace":    {input: "%20=0.5", opts: decode, wantErr: true},
+	}
+
+	for name, tt := range tests {
+		tt := tt
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := nagios.ParsePerfDataWithOptions(tt.input, tt.opts)
+			switch {
+			case tt.wantErr:
+				if !errors.Is(err, nagios.ErrInvalidPerformanceDataFormat) {
+					t.Fatalf("\nwant error %v\ngot %v", nagios.ErrInvalidPerformanceDataFormat, err)
+				}
+				return
+			case err != nil:
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if got[0].Label != tt.wantLabel {
+				t.Errorf("\nwant label %q\ngot %q", tt.wantLabel, got[0].Label)
+			}
+		})
+	}
+}
+
+// TestPerformanceDataFastValidateMatchesValidate asserts that FastValidate
+// and Validate agree on a broad corpus of metrics and report the same
+// sentinel errors.
+func TestPerformanceDataFastValidateMatchesValidate(t *testing.T) {
+	t.Parallel()
+
+	base := nagios.PerformanceData{
+		Label: "used_pct", Value: "87", UnitOfMeasurement: "%",
+		Warn: "80", Crit: "90", Min: "0", Max: "100",
+	}
+
+	numbers := []string{
+		"", " ", "0", "-1", "+1", "1.5", "-.5", "5.", "1.2.3", "-", "+",
+		"1e3", "1E3", "1,5", "5ms", "abc5", "U", "NaN", "Inf", "inf", "-inf",
+		"+inf", "abc", " 42 ",
+	}
+
+	thresholds := []string{
+		"", "10", "-1.5", "10:", "~:10", "10:20", "@10", "@10:", "@~:10",
+		"@10:20", "~:", "@~:", "1.:", "1:2:3", "10%", "80:90ms", "@@1", "1e3",
+	}
+
+	values := []string{
+		"", " ", "0", "-1", "+1", "1.5", "-.5", "5.", "1.2.3", "U", " 42 ",
+		"abc", "NaN", "Inf", "abc5", "5ms", "1,5", "1e3", "1E3", "4 9", "1abc",
+		"x-y", "UU", "u",
+	}
+
+	corpus := []nagios.PerformanceData{base}
+
+	for _, label := range []string{"", " ", "load 1", "a=b", "it's", "/var/log", "température"} {
+		pd := base
+		pd.Label = label
+		corpus = append(corpus, pd)
+	}
+
+	for _, uom := range []string{"", "s", "ms", "KB", "c", "5s", "'", `"`, ";", "°C"} {
+		pd := base
+		pd.UnitOfMeasurement = uom
+		corpus = append(corpus, pd)
+	}
+
+	for _, n := range numbers {
+		for _, set := range []func(*nagios.PerformanceData){
+			func(pd *nagios.PerformanceData) { pd.Min = n },
+			func(pd *nagios.PerformanceData) { pd.Max = n },
+		} {
+			pd := base
+			set(&pd)
+			corpus = append(corpus, pd)
+		}
+	}
+
+	for _, th := range thresholds {
+		for _, set := range []func(*nagios.PerformanceData){
+			func(pd *nagios.PerformanceData) { pd.Warn = th },
+			func(pd *nagios.PerformanceData) { pd.Crit = th },
+		} {
+			pd := base
+			set(&pd)
+			corpus = append(corpus, pd)
+		}
+	}
+
+	for _, v := range values {
+		pd := base
+		pd.Value = v
+		corpus = append(corpus, pd)
+	}
+
+	sentinels := []error{
+		nagios.ErrInvalidPerformanceDataFormat,
+		nagios.ErrPerformanceDataMissingValue,
+		nagios.ErrPerformanceDataUnexpectedSentinel,
+	}
+
+	for _, pd := range corpus {
+		want := pd.Validate()
+		got := pd.FastValidate()
+
+		if (want == nil) != (got == nil) {
+			t.Errorf("%#v:\nwant error %v\ngot %v", pd, want, got)
+			continue
+		}
+
+		for _, sentinel := range sentinels {
+			if errors.Is(want, sentinel) != errors.Is(got, sentinel) {
+				t.Errorf("%#v: errors.Is(err, %v) mismatch:\nwant %v\ngot %v", pd, sentinel, want, got)
+			}
+		}
+	}
+}
+
+// TestParsePerfDataStripsByteOrderMark asserts that a leading UTF-8 byte
+// order mark is always removed and that other leading zero width characters
+// are only removed if the StripZeroWidthPrefix option is enabled.
+func TestParsePerfDataStripsByteOrderMark(t *testing.T) {
+	t.Parallel()
+
+	want := []nagios.PerformanceData{
+		{Label: "load1", Value: "0.260", Warn: "5", Crit: "10", Min: "0"},
+		{Label: "time", Value: "49", UnitOfMeasurement: "ms"},
+	}
+
+	stripZeroWidth := nagios.PerfDataParseOptions{StripZeroWidthPrefix: true}
+
+	tests := map[string]struct {
+		input          string
+		opts           nagios.PerfDataParseOptions
+		wantFirstLabel string
+	}{
+		"byte order mark": {
+			input:          "\uFEFFload1=0.260;5;10;0; time=49ms",
+			wantFirstLabel: "load1",
+		},
+		"byte order mark before double quote": {
+			input:          "\uFEFF\"load1=0.260;5;10;0; time=49ms\"",
+			wantFirstLabel: "load1",
+		},
+		"byte order mark before single quoted label": {
+			input:          "\uFEFF'load1'=0.260;5;10;0; time=49ms",
+			wantFirstLabel: "load1",
+		},
+		"zero width space with option": {
+			input:          "\u200B\u2060load1=0.260;5;10;0; time=49ms",
+			opts:           stripZeroWidth,
+			wantFirstLabel: "load1",
+		},
+		"zero width space and byte order mark with option": {
+			input:          "\uFEFF\u200Bload1=0.260;5;10;0; time=49ms",
+			opts:           stripZeroWidth,
+			wantFirstLabel: "load1",
+		},
+		"zero width space without option": {
+			input:          "\u200Bload1=0.260;5;10;0; time=49ms",
+			wantFirstLabel: "\u200Bload1",
+		},
+	}
+
+	for name, tt := range tests {
+		tt := tt
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := nagios.ParsePerfDataWithOptions(tt.input, tt.opts)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if got[0].Label != tt.wantFirstLabel {
+				t.Errorf("\nwant first label %q\ngot %q", tt.wantFirstLabel, got[0].Label)
+			}
+
+			if tt.wantFirstLabel == "load1" {
+				testParsePerfDataCollection(t, got, want)
+			}
+		})
+	}
+
+	single, err := nagios.ParseSinglePerfData("\uFEFFtime=49ms")
+	switch {
+	case err != nil:
+		t.Errorf("ParseSinglePerfData: unexpected error: %v", err)
+	case single.Label != "time":
+		t.Errorf("ParseSinglePerfData:\nwant label %q\ngot %q", "time", single.Label)
+	}
+}
+
+// TestParsePerfDataWithOptionsMaxMetricsPerLine asserts that input
+// containing more metrics than the configured limit is rejected and that
+// there is no limit by default.
+func TestParsePerfDataWithOptionsMaxMetricsPerLine(t *testing.T) {
+	t.Parallel()
+
+	metrics := func(n int) string {
+		fields := make([]string, n)
+		for i := range fields {
+			fields[i] = "m" + strconv.Itoa(i) + "=" + strconv.Itoa(i)
+		}
+		return strings.Join(fields, " ")
+	}
+
+	tests := map[string]struct {
+		input     string
+		opts      nagios.PerfDataParseOptions
+		wantCount int
+		wantErr   error
+	}{
+		"under limit": {
+			input:     metrics(9),
+			opts:      nagios.PerfDataParseOptions{MaxMetricsPerLine: 10},
+			wantCount: 9,
+		},
+		"at limit": {
+			input:     metrics(10),
+			opts:      nagios.PerfDataParseOptions{MaxMetricsPerLine: 10},
+			wantCount: 10,
+		},
+		"just over limit": {
+			input:   metrics(11),
+			opts:    nagios.PerfDataParseOptions{MaxMetricsPerLine: 10},
+			wantErr: nagios.ErrPerformanceDataTooManyMetrics,
+		},
+		"over limit with custom separators": {
+			input:   strings.ReplaceAll(metrics(11), " ", "\n"),
+			opts:    nagios.PerfDataParseOptions{MaxMetricsPerLine: 10, MetricSeparators: "\n"},
+			wantErr: nagios.ErrPerformanceDataTooManyMetrics,
+		},
+		"no limit by default": {
+			input:     metrics(1000),
+			wantCount: 1000,
+		},
+		"negative limit": {
+			input:     metrics(11),
+			opts:      nagios.PerfDataParseOptions{MaxMetricsPerLine: -1},
+			wantCount: 11,
+		},
+	}
+
+	for name, tt := range tests {
+		tt := tt
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := nagios.ParsePerfDataWithOptions(tt.input, tt.opts)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("\nwant error %v\ngot %v", tt.wantErr, err)
+			}
+
+			if len(got) != tt.wantCount {
+				t.Errorf("\nwant %d metrics\ngot %d", tt.wantCount, len(got))
+			}
+		})
+	}
+}
+
+// TestPerformanceDataToMap asserts that each map key holds a value of the
+// documented type for a fully populated metric and that empty and
+// undetermined numeric fields are nil.
+func TestPerformanceDataToMap(t *testing.T) {
+	t.Parallel()
+
+	pd := nagios.PerformanceData{
+		Label:             "used_pct",
+		Value:             "87.5",
+		UnitOfMeasurement: "%",
+		Warn:              "@80:90",
+		Crit:              "~:95",
+		Min:               "0",
+		Max:               "100",
+	}
+
+	want := map[string]interface{}{
+		"label": "used_pct",
+		"value": 87.5,
+		"uom":   "%",
+		"warn":  "@80:90",
+		"crit":  "~:95",
+		"min":   0.0,
+		"max":   100.0,
+	}
+
+	if d := cmp.Diff(want, pd.ToMap()); d != "" {
+		t.Errorf("(-want, +got)\n:%s", d)
+	}
+
+	undetermined := nagios.PerformanceData{
+		Label:  "temp",
+		Value:  "U",
+		Values: []string{"U", "21.5", "bogus"},
+	}
+
+	want = map[string]interface{}{
+		"label":  "temp",
+		"value":  nil,
+		"uom":    "",
+		"warn":   "",
+		"crit":   "",
+		"min":    nil,
+		"max":    nil,
+		"values": []interface{}{nil, 21.5, "bogus"},
+	}
+
+	if d := cmp.Diff(want, undetermined.ToMap()); d != "" {
+		t.Errorf("(-want, +got)\n:%s", d)
+	}
+
+	encoded, err := json.Marshal(pd.ToMap())
+	if err != nil {
+		t.Fatalf("failed to encode map: %v", err)
+	}
+
+	wantJSON := `{"crit":"~:95","label":"used_pct","max":100,"min":0,"uom":"%","value":87.5,"warn":"@80:90"}`
+	if string(encoded) != wantJSON {
+		t.Errorf("\nwant %s\ngot %s", wantJSON, encoded)
+	}
+}
+
+// TestParsePerfDataWithOptionsAllowSemicolonInQuotedLabel asserts that a
+// semicolon within a quoted label is only treated as part of the label if
+// the AllowSemicolonInQuotedLabel option is enabled.
+func TestParsePerfDataWithOptionsAllowSemicolonInQuotedLabel(t *testing.T) {
+	t.Parallel()
+
+	tolerant := nagios.PerfDataParseOptions{AllowSemicolonInQuotedLabel: true}
+
+	tests := map[string]struct {
+		input   string
+		opts    nagios.PerfDataParseOptions
+		result  []nagios.PerformanceData
+		wantErr bool
+	}{
+		"semicolon in quoted label": {
+			input: "'rx;tx'=10B;80;90;0;100",
+			opts:  tolerant,
+			result: []nagios.PerformanceData{
+				{Label: "rx;tx", Value: "10", UnitOfMeasurement: "B", Warn: "80", Crit: "90", Min: "0", Max: "100"},
+			},
+		},
+		"semicolon in quoted label without other fields": {
+			input: "'a;b;c'=1 load1=0.26;5;10",
+			opts:  tolerant,
+			result: []nagios.PerformanceData{
+				{Label: "a;b;c", Value: "1"},
+				{Label: "load1", Value: "0.26", Warn: "5", Crit: "10"},
+			},
+		},
+		"semicolon and space in quoted label with separators": {
+			input: "'rx; tx'=10B;80;90\n'time'=49ms",
+			opts:  nagios.PerfDataParseOptions{AllowSemicolonInQuotedLabel: true, MetricSeparators: "\n"},
+			result: []nagios.PerformanceData{
+				{Label: "rx; tx", Value: "10", UnitOfMeasurement: "B", Warn: "80", Crit: "90"},
+				{Label: "time", Value: "49", UnitOfMeasurement: "ms"},
+			},
+		},
+		"quoted label without semicolon": {
+			input: "'time'=49ms;100;200;;",
+			opts:  tolerant,
+			result: []nagios.PerformanceData{
+				{Label: "time", Value: "49", UnitOfMeasurement: "ms", Warn: "100", Crit: "200"},
+			},
+		},
+		"unquoted label with semicolon": {
+			input:   "rx;tx=10B",
+			opts:    tolerant,
+			wantErr: true,
+		},
+		"semicolon in quoted label without option": {
+			input:   "'rx;tx'=10B;80;90;0;100",
+			wantErr: true,
+		},
+	}
+
+	for name, tt := range tests {
+		tt := tt
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := nagios.ParsePerfDataWithOptions(tt.input, tt.opts)
+			if tt.wantErr {
+				if !errors.Is(err, nagios.ErrInvalidPerformanceDataFormat) {
+					t.Fatalf("\nwant error %v\ngot %v", nagios.ErrInvalidPerformanceDataFormat, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			testParsePerfDataCollection(t, got, tt.result)
+		})
+	}
+}
@@ -0,0 +1,245 @@
+// Copyright 2023 Adam Chalkley
+//
+// https://github.com/atc0005/go-nagios
+//
+// Licensed under the MIT License. See LICENSE file in the project root for
+// full license information.
+
+package nagios
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ExtractPerfDataSection splits complete plugin output (e.g., "OK - all good
+// | cpu=1 mem=2") into the human readable text and the raw performance data
+// string. The returned performance data string is suitable for use with
+// ParsePerfData and is empty if the output does not contain performance
+// data.
+//
+// Multi-line output is handled as described by the [Nagios Plugin API]: the
+// first line may contain text and performance data separated by a pipe
+// character and subsequent lines contain long text until a line containing
+// a pipe character is found. Everything after that pipe character
+// (including any remaining lines) is additional performance data. The text
+// from all lines is returned (separated by newlines) and the performance
+// data from all lines is returned as a single space separated string.
+//
+// As with Nagios, only the first pipe character on a line is treated as the
+// separator. Pipe characters are therefore not supported in the text
+// portion of the output: for output such as "OK - status: up | down counts |
+// cpu=1" the text is "OK - status: up" and everything after the first pipe
+// character ("down counts | cpu=1") is returned as performance data (which
+// then fails to parse). Pipe characters in text should be escaped with a
+// backslash (e.g., "\|"); escaped pipe characters are not treated as
+// separators and are returned as-is.
+//
+// [Nagios Plugin API]: https://assets.nagios.com/downloads/nagioscore/docs/nagioscore/3/en/pluginapi.html
+func ExtractPerfDataSection(output string) (text string, perfdata string) {
+	text, longText, perfdata := splitPluginOutput(output)
+
+	if longText != "" {
+		text += "\n" + longText
+	}
+
+	return text, perfdata
+}
+
+// ParsePluginOutput parses complete (possibly multi-line) plugin output in
+// the format described by the [Nagios Plugin API]:
+//
+//	TEXT OUTPUT | OPTIONAL PERFDATA
+//	LONG TEXT LINE 1
+//	LONG TEXT LINE 2
+//	...
+//	LONG TEXT LINE N | PERFDATA LINE 2
+//	PERFDATA LINE 3
+//	...
+//
+// The text from the first line, the long text from subsequent lines (newline
+// separated) and the parsed performance data from all lines (in order) are
+// returned. A nil performance data collection is returned if the output does
+// not contain performance data. An error is returned if the performance data
+// fails to parse.
+//
+// [Nagios Plugin API]: https://assets.nagios.com/downloads/nagioscore/docs/nagioscore/3/en/pluginapi.html
+func ParsePluginOutput(output string) (text string, longText string, pd []PerformanceData, err error) {
+	text, longText, rawPerfData := splitPluginOutput(output)
+
+	if rawPerfData == "" {
+		return text, longText, nil, nil
+	}
+
+	pd, err = ParsePerfData(rawPerfData)
+	if err != nil {
+		return text, longText, nil, fmt.Errorf("failed to parse plugin output performance data: %w", err)
+	}
+
+	return text, longText, pd, nil
+}
+
+// ValidatePluginOutput checks complete (possibly multi-line) plugin output
+// against the format described by the [Nagios Plugin API] and is intended
+// for use as a single gate when linting samples of plugin output (e.g., in
+// CI). The following checks are performed:
+//
+//   - the first line begins with a status prefix whose last word is a
+//     supported state label (e.g., "OK - all good", "DISK WARNING: 92%
+//     used"); the prefix is the text before the first " - " or ":"
+//     separator, or the entire first line if neither is present
+//   - the text contains no unescaped pipe character other than the
+//     separator preceding the performance data (see ExtractPerfDataSection)
+//   - the performance data, if present, parses using ParsePerfData
+//
+// All detected problems are returned as a single error wrapping each
+// problem (see errors.Is); nil is returned if the output is valid. Problems with the
+// status prefix or text wrap ErrInvalidPluginOutput while performance data
+// parsing failures wrap the error returned by ParsePerfData.
+//
+// [Nagios Plugin API]: https://assets.nagios.com/downloads/nagioscore/docs/nagioscore/3/en/pluginapi.html
+func ValidatePluginOutput(output string) error {
+	text, _, rawPerfData := splitPluginOutput(output)
+
+	var errs []error
+
+	if status := pluginOutputStatus(text); !inList(status, SupportedStateLabels(), false) {
+		errs = append(errs, fmt.Errorf(
+			"status %q of plugin output %q not in set %q: %w",
+			status,
+			text,
+			SupportedStateLabels(),
+			ErrInvalidPluginOutput,
+		))
+	}
+
+	if before, _, found := cutUnescapedPipe(rawPerfData); found {
+		errs = append(errs, fmt.Errorf(
+			"unescaped pipe character found in plugin output text after %q: %w",
+			before,
+			ErrInvalidPluginOutput,
+		))
+
+		// Only attempt to parse the performance data following the stray
+		// pipe character to avoid reporting the same problem twice.
+		_, rawPerfData = ExtractPerfDataSection(rawPerfData)
+	}
+
+	if rawPerfData != "" {
+		if _, err := ParsePerfData(rawPerfData); err != nil {
+			errs = append(errs, fmt.Errorf("failed to parse plugin output performance data: %w", err))
+		}
+	}
+
+	return joinErrors(errs...)
+}
+
+// BuildOutputLine assembles a single line of plugin output from the given
+// status (e.g., StateOKLabel), text and performance data following the
+// convention described by the [Nagios Plugin API]:
+//
+//	STATUS - text | perfdata
+//
+// The " - " separator is omitted if text is empty and the " | " separator is
+// omitted if there is no performance data. The performance data is formatted
+// using FormatPerfDataLine; no validation is performed.
+//
+// This is intended as a convenience for simple scripts which do not use the
+// Plugin type to manage output.
+//
+// [Nagios Plugin API]: https://assets.nagios.com/downloads/nagioscore/docs/nagioscore/3/en/pluginapi.html
+func BuildOutputLine(status string, text string, pd []PerformanceData) string {
+	var output strings.Builder
+
+	output.WriteString(status)
+
+	if text != "" {
+		output.WriteString(" - ")
+		output.WriteString(text)
+	}
+
+	if len(pd) > 0 {
+		output.WriteString(" | ")
+		output.WriteString(FormatPerfDataLine(pd))
+	}
+
+	return output.String()
+}
+
+// splitPluginOutput splits complete (possibly multi-line) plugin output into
+// the text from the first line, the long text from subsequent lines and the
+// combined raw performance data from all lines. See ExtractPerfDataSection
+// for details regarding the expected format.
+func splitPluginOutput(output string) (text string, longText string, perfdata string) {
+	lines := strings.Split(strings.ReplaceAll(output, "\r\n", "\n"), "\n")
+
+	var perfDataParts []string
+	addPerfData := func(s string) {
+		if s = strings.TrimSpace(s); s != "" {
+			perfDataParts = append(perfDataParts, s)
+		}
+	}
+
+	text, firstPerfData, _ := cutUnescapedPipe(lines[0])
+	text = strings.TrimSpace(text)
+	addPerfData(firstPerfData)
+
+	var longTextLines []string
+	inLongPerfData := false
+	for _, line := range lines[1:] {
+		if inLongPerfData {
+			addPerfData(line)
+			continue
+		}
+
+		before, after, found := cutUnescapedPipe(line)
+		longTextLines = append(longTextLines, strings.TrimRight(before, " \t"))
+
+		if found {
+			inLongPerfData = true
+			addPerfData(after)
+		}
+	}
+
+	longText = strings.Trim(strings.Join(longTextLines, "\n"), "\n")
+
+	return text, longText, strings.Join(perfDataParts, " ")
+}
+
+// pluginOutputStatus returns the last word of the status prefix of the given
+// first line of plugin output text. The status prefix is the text before the
+// first " - " or ":" separator or the entire line if neither is present.
+func pluginOutputStatus(text string) string {
+	prefix := text
+	if i := strings.Index(prefix, " - "); i >= 0 {
+		prefix = prefix[:i]
+	}
+	if i := strings.Index(prefix, ":"); i >= 0 {
+		prefix = prefix[:i]
+	}
+
+	words := strings.Fields(prefix)
+	if len(words) == 0 {
+		return ""
+	}
+
+	return words[len(words)-1]
+}
+
+// cutUnescapedPipe slices s around the first pipe character which is not
+// preceded by a backslash, returning the text before and after the pipe
+// character. The found result reports whether an unescaped pipe character
+// was found. If not found, s is returned as before and after is empty.
+func cutUnescapedPipe(s string) (before string, after string, found bool) {
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '\\':
+			// Skip the escaped character.
+			i++
+		case '|':
+			return s[:i], s[i+1:], true
+		}
+	}
+
+	return s, "", false
+}
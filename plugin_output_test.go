@@ -0,0 +1,343 @@
+// Copyright 2023 Adam Chalkley
+//
+// https://github.com/atc0005/go-nagios
+//
+// Licensed under the MIT License. See LICENSE file in the project root for
+// full license information.
+
+package nagios_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/atc0005/go-nagios"
+)
+
+// TestExtractPerfDataSection asserts that the text and performance data
+// portions of single-line and multi-line plugin output are extracted as
+// expected.
+func TestExtractPerfDataSection(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		output       string
+		wantText     string
+		wantPerfData string
+	}{
+		"single line": {
+			output:       "OK - all good | cpu=1 mem=2",
+			wantText:     "OK - all good",
+			wantPerfData: "cpu=1 mem=2",
+		},
+		"single line without perfdata": {
+			output:       "OK - all good",
+			wantText:     "OK - all good",
+			wantPerfData: "",
+		},
+		"escaped pipe in text": {
+			output:       `OK - a \| b | cpu=1`,
+			wantText:     `OK - a \| b`,
+			wantPerfData: "cpu=1",
+		},
+		"multi-line with long perfdata": {
+			output: strings.Join([]string{
+				"DISK OK - free space: / 3326 MB (56%); | /=2643MB;5948;5958;0;5968",
+				"/ 15272 MB (77%);",
+				"/boot 68 MB (69%);",
+				"/home 69357 MB (27%);",
+				"/var/log 819 MB (84%); | /boot=68MB;88;93;0;98",
+				"/home=69357MB;253404;253409;0;253414",
+				"/var/log=818MB;970;975;0;980",
+			}, "\n"),
+			wantText: strings.Join([]string{
+				"DISK OK - free space: / 3326 MB (56%);",
+				"/ 15272 MB (77%);",
+				"/boot 68 MB (69%);",
+				"/home 69357 MB (27%);",
+				"/var/log 819 MB (84%);",
+			}, "\n"),
+			wantPerfData: "/=2643MB;5948;5958;0;5968 /boot=68MB;88;93;0;98 " +
+				"/home=69357MB;253404;253409;0;253414 /var/log=818MB;970;975;0;980",
+		},
+		"multi-line with perfdata on last line only": {
+			output:       "OK - summary\nline one\nline two | time=1s\n",
+			wantText:     "OK - summary\nline one\nline two",
+			wantPerfData: "time=1s",
+		},
+	}
+
+	for name, tt := range tests {
+		tt := tt
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			text, perfData := nagios.ExtractPerfDataSection(tt.output)
+
+			if text != tt.wantText {
+				t.Errorf("\nwant text %q\ngot %q", tt.wantText, text)
+			}
+
+			if perfData != tt.wantPerfData {
+				t.Errorf("\nwant perfdata %q\ngot %q", tt.wantPerfData, perfData)
+			}
+
+			if perfData == "" {
+				return
+			}
+
+			if _, err := nagios.ParsePerfData(perfData); err != nil {
+				t.Errorf("failed to parse extracted perfdata %q: %v", perfData, err)
+			}
+		})
+	}
+}
+
+// TestParsePluginOutput asserts that multi-line plugin output is parsed into
+// text, long text and performance data using the example from the Nagios
+// Plugin API documentation.
+func TestParsePluginOutput(t *testing.T) {
+	t.Parallel()
+
+	output := strings.Join([]string{
+		"DISK OK - free space: / 3326 MB (56%); | /=2643MB;5948;5958;0;5968",
+		"/ 15272 MB (77%);",
+		"/boot 68 MB (69%);",
+		"/home 69357 MB (27%);",
+		"/var/log 819 MB (84%); | /boot=68MB;88;93;0;98",
+		"/home=69357MB;253404;253409;0;253414",
+		"/var/log=818MB;970;975;0;980",
+	}, "\n")
+
+	text, longText, perfData, err := nagios.ParsePluginOutput(output)
+	if err != nil {
+		t.Fatalf("failed to parse plugin output: %v", err)
+	}
+
+	wantText := "DISK OK - free space: / 3326 MB (56%);"
+	if text != wantText {
+		t.Errorf("\nwant text %q\ngot %q", wantText, text)
+	}
+
+	wantLongText := strings.Join([]string{
+		"/ 15272 MB (77%);",
+		"/boot 68 MB (69%);",
+		"/home 69357 MB (27%);",
+		"/var/log 819 MB (84%);",
+	}, "\n")
+	if longText != wantLongText {
+		t.Errorf("\nwant long text %q\ngot %q", wantLongText, longText)
+	}
+
+	wantPerfData := []nagios.PerformanceData{
+		{Label: "/", Value: "2643", UnitOfMeasurement: "MB", Warn: "5948", Crit: "5958", Min: "0", Max: "5968"},
+		{Label: "/boot", Value: "68", UnitOfMeasurement: "MB", Warn: "88", Crit: "93", Min: "0", Max: "98"},
+		{Label: "/home", Value: "69357", UnitOfMeasurement: "MB", Warn: "253404", Crit: "253409", Min: "0", Max: "253414"},
+		{Label: "/var/log", Value: "818", UnitOfMeasurement: "MB", Warn: "970", Crit: "975", Min: "0", Max: "980"},
+	}
+	testParsePerfDataCollection(t, perfData, wantPerfData)
+
+	text, longText, perfData, err = nagios.ParsePluginOutput("OK - no metrics")
+	if err != nil || text != "OK - no metrics" || longText != "" || perfData != nil {
+		t.Errorf("unexpected results for output without perfdata: %q, %q, %v, %v", text, longText, perfData, err)
+	}
+
+	_, _, _, err = nagios.ParsePluginOutput("OK | time=")
+	if !errors.Is(err, nagios.ErrInvalidPerformanceDataFormat) {
+		t.Errorf("\nwant error %v\ngot %v", nagios.ErrInvalidPerformanceDataFormat, err)
+	}
+}
+
+// TestBuildOutputLine asserts that a single line of plugin output is
+// assembled as expected with and without text and performance data.
+func TestBuildOutputLine(t *testing.T) {
+	t.Parallel()
+
+	perfData := []nagios.PerformanceData{
+		{Label: "time", Value: "49", UnitOfMeasurement: "ms", Warn: "100", Crit: "200"},
+		{Label: "load", Value: "0.26"},
+	}
+
+	tests := map[string]struct {
+		status   string
+		text     string
+		perfData []nagios.PerformanceData
+		want     string
+	}{
+		"with perfdata": {
+			status:   nagios.StateOKLabel,
+			text:     "all good",
+			perfData: perfData,
+			want:     "OK - all good | 'time'=49ms;100;200;; 'load'=0.26;;;;",
+		},
+		"without perfdata": {
+			status: nagios.StateWARNINGLabel,
+			text:   "disk filling up",
+			want:   "WARNING - disk filling up",
+		},
+		"empty perfdata collection": {
+			status:   nagios.StateOKLabel,
+			text:     "all good",
+			perfData: []nagios.PerformanceData{},
+			want:     "OK - all good",
+		},
+		"empty text": {
+			status:   nagios.StateCRITICALLabel,
+			perfData: perfData[:1],
+			want:     "CRITICAL | 'time'=49ms;100;200;;",
+		},
+		"empty text without perfdata": {
+			status: nagios.StateUNKNOWNLabel,
+			want:   "UNKNOWN",
+		},
+	}
+
+	for name, tt := range tests {
+		tt := tt
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			got := nagios.BuildOutputLine(tt.status, tt.text, tt.perfData)
+			if got != tt.want {
+				t.Errorf("\nwant %q\ngot %q", tt.want, got)
+			}
+
+			if len(tt.perfData) == 0 {
+				return
+			}
+
+			text, rawPerfData := nagios.ExtractPerfDataSection(got)
+			if rawPerfData != nagios.FormatPerfDataLine(tt.perfData) {
+				t.Errorf("\nwant perfdata %q\ngot %q", nagios.FormatPerfDataLine(tt.perfData), rawPerfData)
+			}
+			if strings.HasSuffix(text, " ") {
+				t.Errorf("unexpected trailing whitespace in text %q", text)
+			}
+		})
+	}
+}
+
+// TestExtractPerfDataSectionSplitsOnFirstPipe asserts that only the first
+// (unescaped) pipe character in status text is treated as the separator, as
+// documented, so that a pipe in the text itself causes a mis-split unless
+// escaped.
+func TestExtractPerfDataSectionSplitsOnFirstPipe(t *testing.T) {
+	t.Parallel()
+
+	text, perfData := nagios.ExtractPerfDataSection("OK - status: up | down counts | cpu=1")
+
+	if want := "OK - status: up"; text != want {
+		t.Errorf("\nwant text %q\ngot %q", want, text)
+	}
+
+	if want := "down counts | cpu=1"; perfData != want {
+		t.Errorf("\nwant perfdata %q\ngot %q", want, perfData)
+	}
+
+	if _, err := nagios.ParsePerfData(perfData); !errors.Is(err, nagios.ErrInvalidPerformanceDataFormat) {
+		t.Errorf("\nwant error %v\ngot %v", nagios.ErrInvalidPerformanceDataFormat, err)
+	}
+
+	text, perfData = nagios.ExtractPerfDataSection(`OK - status: up \| down counts | cpu=1`)
+
+	if want := `OK - status: up \| down counts`; text != want {
+		t.Errorf("\nwant text %q\ngot %q", want, text)
+	}
+
+	if want := "cpu=1"; perfData != want {
+		t.Errorf("\nwant perfdata %q\ngot %q", want, perfData)
+	}
+}
+
+// TestValidatePluginOutput asserts that valid plugin output passes
+// validation and that each detected problem with malformed plugin output is
+// reported.
+func TestValidatePluginOutput(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		output          string
+		wantOutputErr   bool
+		wantPerfDataErr bool
+		wantErrCount    int
+	}{
+		"valid with perfdata": {
+			output: "OK - all good | 'time'=49ms;100;200;; load=0.26",
+		},
+		"valid without perfdata": {
+			output: "WARNING - disk filling up",
+		},
+		"valid service prefix and colon": {
+			output: "DISK CRITICAL: / 98% used | /=98%;80;90;0;100",
+		},
+		"valid status only": {
+			output: "UNKNOWN",
+		},
+		"valid escaped pipe in text": {
+			output: `OK - a \| b | cpu=1`,
+		},
+		"valid multi-line": {
+			output: "OK - summary\nline one\nline two | time=1s\n/boot=68MB;88;93;0;98",
+		},
+		"empty output": {
+			output:        "",
+			wantOutputErr: true,
+			wantErrCount:  1,
+		},
+		"unknown status word": {
+			output:        "FINE - all good | cpu=1",
+			wantOutputErr: true,
+			wantErrCount:  1,
+		},
+		"lowercase status word": {
+			output:        "ok - all good",
+			wantOutputErr: true,
+			wantErrCount:  1,
+		},
+		"malformed perfdata": {
+			output:          "OK - all good | cpu=abc",
+			wantPerfDataErr: true,
+			wantErrCount:    1,
+		},
+		"stray pipe in text": {
+			output:        "OK - status: up | down counts | cpu=1",
+			wantOutputErr: true,
+			wantErrCount:  1,
+		},
+		"multiple problems": {
+			output:          "FINE - status: up | down counts | cpu=abc",
+			wantOutputErr:   true,
+			wantPerfDataErr: true,
+			wantErrCount:    3,
+		},
+	}
+
+	for name, tt := range tests {
+		tt := tt
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			err := nagios.ValidatePluginOutput(tt.output)
+
+			if got := errors.Is(err, nagios.ErrInvalidPluginOutput); got != tt.wantOutputErr {
+				t.Errorf("\nwant errors.Is(err, ErrInvalidPluginOutput) %v\ngot %v (err: %v)", tt.wantOutputErr, got, err)
+			}
+
+			if got := errors.Is(err, nagios.ErrInvalidPerformanceDataFormat); got != tt.wantPerfDataErr {
+				t.Errorf("\nwant errors.Is(err, ErrInvalidPerformanceDataFormat) %v\ngot %v (err: %v)", tt.wantPerfDataErr, got, err)
+			}
+
+			var gotErrCount int
+			if joined, ok := err.(interface{ Unwrap() []error }); ok {
+				gotErrCount = len(joined.Unwrap())
+			}
+			if gotErrCount != tt.wantErrCount {
+				t.Errorf("\nwant %d errors\ngot %d (err: %v)", tt.wantErrCount, gotErrCount, err)
+			}
+		})
+	}
+}
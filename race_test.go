@@ -0,0 +1,14 @@
+// Copyright 2023 Adam Chalkley
+//
+// https://github.com/atc0005/go-nagios
+//
+// Licensed under the MIT License. See LICENSE file in the project root for
+// full license information.
+
+//go:build race
+
+package nagios
+
+// raceEnabled indicates whether the race detector is enabled. The race
+// detector instrumentation allocates, which affects allocation counts.
+const raceEnabled = true
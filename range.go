@@ -70,6 +70,15 @@ func (r Range) checkOutsideRange(valueAsAFloat float64) bool {
 	}
 }
 
+// Compiled regular expressions used by ParseRangeString. These are compiled
+// once at package initialization instead of on each use.
+var (
+	rangeDigitOrInfinityRe        = regexp.MustCompile(`[\d~]`)
+	rangeOptionalInvertAndRangeRe = regexp.MustCompile(`^\@?((?:[-+]?[\d\.]+)(?:e(?:[-+]?[\d\.]+))?|~)?(:((?:[-+]?[\d\.]+)(?:e(?:[-+]?[\d\.]+))?)?)?$`)
+	rangeFirstHalfRe              = regexp.MustCompile(`^((?:[-+]?[\d\.]+)(?:e(?:[-+]?[\d\.]+))?)?:`)
+	rangeEndRe                    = regexp.MustCompile(`^(?:[-+]?[\d\.]+)(?:e(?:[-+]?[\d\.]+))?$`)
+)
+
 // ParseRangeString static method to construct a Range object from the string
 // representation based on the [Nagios Plugin Dev Guidelines: Threshold and
 // Ranges] definition.
@@ -78,11 +87,6 @@ func (r Range) checkOutsideRange(valueAsAFloat float64) bool {
 func ParseRangeString(input string) *Range {
 	r := Range{}
 
-	digitOrInfinity := regexp.MustCompile(`[\d~]`)
-	optionalInvertAndRange := regexp.MustCompile(`^\@?((?:[-+]?[\d\.]+)(?:e(?:[-+]?[\d\.]+))?|~)?(:((?:[-+]?[\d\.]+)(?:e(?:[-+]?[\d\.]+))?)?)?$`)
-	firstHalfOfRange := regexp.MustCompile(`^((?:[-+]?[\d\.]+)(?:e(?:[-+]?[\d\.]+))?)?:`)
-	endOfRange := regexp.MustCompile(`^(?:[-+]?[\d\.]+)(?:e(?:[-+]?[\d\.]+))?$`)
-
 	r.Start = 0
 	r.StartInfinity = false
 	r.End = 0
@@ -92,7 +96,7 @@ func ParseRangeString(input string) *Range {
 	valid := true
 
 	// If regex does not match ...
-	if !(digitOrInfinity.MatchString(input) && optionalInvertAndRange.MatchString(input)) {
+	if !(rangeDigitOrInfinityRe.MatchString(input) && rangeOptionalInvertAndRangeRe.MatchString(input)) {
 		return nil
 	}
 
@@ -112,7 +116,7 @@ func ParseRangeString(input string) *Range {
 	}
 
 	// 10:
-	rangeComponents := firstHalfOfRange.FindAllStringSubmatch(input, -1)
+	rangeComponents := rangeFirstHalfRe.FindAllStringSubmatch(input, -1)
 	if rangeComponents != nil {
 		if rangeComponents[0][1] != "" {
 			r.Start, _ = strconv.ParseFloat(rangeComponents[0][1], 64)
@@ -125,7 +129,7 @@ func ParseRangeString(input string) *Range {
 	}
 
 	// x:10 or 10
-	endOfRangeComponents := endOfRange.FindAllStringSubmatch(input, -1)
+	endOfRangeComponents := rangeEndRe.FindAllStringSubmatch(input, -1)
 	if endOfRangeComponents != nil {
 
 		r.End, _ = strconv.ParseFloat(endOfRangeComponents[0][0], 64)
@@ -170,19 +170,15 @@ func (p *Plugin) handlePerformanceData(w io.Writer) {
 		return
 	}
 
-	// Performance data metrics are appended to plugin output. These
-	// metrics are provided as a single line, leading with a pipe
-	// character, a space and one or more metrics each separated from
-	// another by a single space.
-	fmt.Fprint(w, " |")
-
 	// Sort performance data values prior to emitting them so that the
 	// output is consistent across plugin execution.
 	perfData := p.getSortedPerfData()
 
-	for _, pd := range perfData {
-		fmt.Fprint(w, pd.String())
-	}
+	// Performance data metrics are appended to plugin output. These
+	// metrics are provided as a single line, leading with a pipe
+	// character, a space and one or more metrics each separated from
+	// another by a single space.
+	fmt.Fprint(w, " | ", FormatPerfDataLine(perfData))
 
 	// Add final trailing newline to satisfy Nagios plugin output format.
 	fmt.Fprint(w, CheckOutputEOL)
@@ -0,0 +1,816 @@
+// Copyright 2023 Adam Chalkley
+//
+// https://github.com/atc0005/go-nagios
+//
+// Licensed under the MIT License. See LICENSE file in the project root for
+// full license information.
+
+package nagios
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// Threshold represents a parsed Warn or Crit performance data field value
+// using the range format described by the [Nagios Plugin Dev Guidelines:
+// Threshold and Ranges] definition.
+//
+// Unbounded range ends are represented using math.Inf; a range such as "~:10"
+// has a Start value of math.Inf(-1) and a range such as "10:" has an End
+// value of math.Inf(1).
+//
+// [Nagios Plugin Dev Guidelines: Threshold and Ranges]: https://nagios-plugins.org/doc/guidelines.html#THRESHOLDFORMAT
+type Threshold struct {
+	// Start is the inclusive lower bound of the range.
+	Start float64
+
+	// End is the inclusive upper bound of the range.
+	End float64
+
+	// Inverted indicates that an alert is raised if a value is inside the
+	// range (inclusive of endpoints) instead of outside of it. This is
+	// specified by a leading "@" character.
+	Inverted bool
+
+	// specified indicates whether the threshold was parsed from (or
+	// constructed for) a non-empty range. The zero value represents an empty
+	// (unspecified) threshold.
+	specified bool
+}
+
+// ParseThreshold parses the given string in the Nagios range format (e.g.,
+// "10", "10:", "~:10", "10:20", "@10:20") into a Threshold value. The "~"
+// character is only valid as the start of a range (negative infinity). An
+// error is returned if the input string is empty or not in a valid format.
+// An error also wrapping ErrInvalidRangeThreshold is returned if the start
+// of the range is greater than the end, including a negative single value
+// (e.g., "-5" is shorthand for "0:-5"), as is done by NewThreshold.
+func ParseThreshold(s string) (Threshold, error) {
+	input := strings.TrimSpace(s)
+
+	if input == "" {
+		return Threshold{}, fmt.Errorf(
+			"empty threshold provided: %w",
+			ErrInvalidPerformanceDataFormat,
+		)
+	}
+
+	if !perfDataThresholdRangeSyntaxRe.MatchString(input) {
+		return Threshold{}, fmt.Errorf(
+			"threshold %q not in valid range format: %w",
+			input,
+			ErrInvalidPerformanceDataFormat,
+		)
+	}
+
+	t := Threshold{specified: true}
+
+	if strings.HasPrefix(input, "@") {
+		t.Inverted = true
+		input = input[1:]
+	}
+
+	rawStart, rawEnd, isRange := strings.Cut(input, ":")
+
+	// A single value (e.g., "10") is shorthand for the range "0:10" and is
+	// evaluated as such (e.g., "-5" is rejected as "0:-5" would be).
+	if !isRange {
+		rawStart, rawEnd = "0", rawStart
+	}
+
+	switch rawStart {
+	case "~":
+		t.Start = math.Inf(-1)
+	default:
+		start, err := strconv.ParseFloat(rawStart, 64)
+		if err != nil {
+			return Threshold{}, fmt.Errorf(
+				"failed to parse start of threshold %q: %v: %w",
+				s,
+				err,
+				ErrInvalidPerformanceDataFormat,
+			)
+		}
+		t.Start = start
+	}
+
+	switch rawEnd {
+	case "":
+		t.End = math.Inf(1)
+	default:
+		end, err := strconv.ParseFloat(rawEnd, 64)
+		if err != nil {
+			return Threshold{}, fmt.Errorf(
+				"failed to parse end of threshold %q: %v: %w",
+				s,
+				err,
+				ErrInvalidPerformanceDataFormat,
+			)
+		}
+		t.End = end
+	}
+
+	if t.Start > t.End {
+		return Threshold{}, fmt.Errorf(
+			"start of threshold %q is greater than end: %w",
+			s,
+			chainErrors(ErrInvalidRangeThreshold, ErrInvalidPerformanceDataFormat),
+		)
+	}
+
+	return t, nil
+}
+
+// NewThreshold creates a Threshold using the given inclusive start and end
+// values. Use math.Inf(-1) as the start value for a range without a lower
+// bound (rendered as "~") and math.Inf(1) as the end value for a range
+// without an upper bound. An error wrapping both ErrInvalidRangeThreshold and
+// ErrInvalidPerformanceDataFormat is returned if either value is NaN, if the
+// start value is math.Inf(1) or the end value is math.Inf(-1) (neither can
+// be represented in the range format), if an inverted range has neither a
+// lower nor an upper bound ("@~:" is not a valid range) or if the start value
+// is greater than the end value.
+func NewThreshold(start float64, end float64, inverted bool) (Threshold, error) {
+	switch {
+	case math.IsNaN(start) || math.IsNaN(end):
+		return Threshold{}, fmt.Errorf(
+			"threshold start and end values must be numeric: %w",
+			chainErrors(ErrInvalidRangeThreshold, ErrInvalidPerformanceDataFormat),
+		)
+
+	case math.IsInf(start, 1) || math.IsInf(end, -1):
+		return Threshold{}, fmt.Errorf(
+			"threshold start %v or end %v is an infinity on the wrong side of the range: %w",
+			start,
+			end,
+			chainErrors(ErrInvalidRangeThreshold, ErrInvalidPerformanceDataFormat),
+		)
+
+	case inverted && math.IsInf(start, -1) && math.IsInf(end, 1):
+		return Threshold{}, fmt.Errorf(
+			"inverted threshold must have a lower or upper bound: %w",
+			chainErrors(ErrInvalidRangeThreshold, ErrInvalidPerformanceDataFormat),
+		)
+
+	case start > end:
+		return Threshold{}, fmt.Errorf(
+			"threshold start %v is greater than end %v: %w",
+			start,
+			end,
+			chainErrors(ErrInvalidRangeThreshold, ErrInvalidPerformanceDataFormat),
+		)
+	}
+
+	return Threshold{
+		Start:     start,
+		End:       end,
+		Inverted:  inverted,
+		specified: true,
+	}, nil
+}
+
+// NewThresholdGreaterThan creates a Threshold which raises an alert if a
+// value is greater than n (i.e., the range "~:n").
+func NewThresholdGreaterThan(n float64) (Threshold, error) {
+	return NewThreshold(math.Inf(-1), n, false)
+}
+
+// NewThresholdLessThan creates a Threshold which raises an alert if a value
+// is less than n (i.e., the range "n:").
+func NewThresholdLessThan(n float64) (Threshold, error) {
+	return NewThreshold(n, math.Inf(1), false)
+}
+
+// IsEmpty reports whether the threshold is empty (i.e., not specified). The
+// zero value Threshold is empty.
+func (t Threshold) IsEmpty() bool {
+	return !t.specified
+}
+
+// String renders the Threshold in canonical Nagios range format such that
+// ParseThreshold(t.String()) returns an equivalent Threshold. Equivalent
+// forms are canonicalized: a range starting at 0 with a finite end is
+// rendered using the shorthand form (e.g., "10" instead of "0:10"), a
+// negative infinity start is rendered as "~" and a positive infinity end is
+// omitted (e.g., "10:"). An empty string is returned for an empty Threshold.
+func (t Threshold) String() string {
+	if t.IsEmpty() {
+		return ""
+	}
+
+	var prefix string
+	if t.Inverted {
+		prefix = "@"
+	}
+
+	formatFloat := func(f float64) string {
+		return strconv.FormatFloat(f, 'f', -1, 64)
+	}
+
+	var start string
+	switch {
+	case math.IsInf(t.Start, -1):
+		start = "~"
+	case t.Start == 0 && !math.IsInf(t.End, 1):
+		return prefix + formatFloat(t.End)
+	default:
+		start = formatFloat(t.Start)
+	}
+
+	var end string
+	if !math.IsInf(t.End, 1) {
+		end = formatFloat(t.End)
+	}
+
+	return prefix + start + ":" + end
+}
+
+// Evaluate returns true if an alert should be raised for the given value,
+// otherwise false. By default an alert is raised if the value is outside of
+// the range (endpoints are considered inside); if the threshold is inverted
+// an alert is raised if the value is inside of the range.
+func (t Threshold) Evaluate(value float64) bool {
+	inside := t.Start <= value && value <= t.End
+
+	if t.Inverted {
+		return inside
+	}
+
+	return !inside
+}
+
+// Contains reports whether the given value falls within the numeric range
+// of the threshold (endpoints included), such as when shading threshold
+// regions in a user interface. This is purely geometric membership: the
+// Inverted flag is ignored.
+//
+// This differs from Evaluate, which reports whether an alert should be
+// raised: for a standard threshold an alert is raised for values NOT
+// contained in the range, while for an inverted threshold an alert is raised
+// for values contained in the range. False is returned for an empty
+// threshold (no range) and for NaN.
+func (t Threshold) Contains(value float64) bool {
+	if t.IsEmpty() {
+		return false
+	}
+
+	return t.Start <= value && value <= t.End
+}
+
+// EvaluatePercent evaluates the given percentage value against the
+// threshold in the same manner as Evaluate, treating 0 and 100 as the
+// implicit bounds of the metric. This is intended for metrics using the "%"
+// Unit of Measurement which do not specify explicit Min and Max field values.
+//
+// The evaluation result is always returned, but an error is also returned if
+// the value is outside of the implicit 0 to 100 range (inclusive) or is NaN
+// so that the caller may decide how to handle an implausible percentage.
+//
+// NOTE: The implicit bounds are not considered by InferredBounds which only
+// uses explicit Min/Max field values and threshold ranges.
+func (t Threshold) EvaluatePercent(value float64) (bool, error) {
+	result := t.Evaluate(value)
+
+	if math.IsNaN(value) || value < 0 || value > 100 {
+		return result, fmt.Errorf(
+			"percentage value %v outside of implicit range 0:100: %w",
+			value,
+			ErrInvalidPerformanceDataFormat,
+		)
+	}
+
+	return result, nil
+}
+
+// ThresholdsConsistent asserts that the Warn and Crit thresholds for the
+// performance data metric are not logically contradictory. Nil is returned
+// if either threshold is empty or if either threshold is inverted (not
+// evaluated).
+//
+// For standard (non-inverted) ranges an alert is raised when a value is
+// outside of the range. A WARNING state (without a CRITICAL state) is only
+// possible for values outside of the Warn range but inside of the Crit
+// range. The thresholds are considered inconsistent if the Crit range is
+// contained within the Warn range (including identical ranges); in that case
+// the Crit alert region is a superset of the Warn alert region and the
+// metric can never be in a WARNING state only. An error wrapping
+// ErrPerformanceDataInconsistentThresholds is returned for this scenario.
+//
+// An error is also returned if either threshold cannot be parsed.
+func (pd PerformanceData) ThresholdsConsistent() error {
+	if strings.TrimSpace(pd.Warn) == "" || strings.TrimSpace(pd.Crit) == "" {
+		return nil
+	}
+
+	warn, err := ParseThreshold(pd.Warn)
+	if err != nil {
+		return fmt.Errorf("failed to parse warn field: %w", err)
+	}
+
+	crit, err := ParseThreshold(pd.Crit)
+	if err != nil {
+		return fmt.Errorf("failed to parse crit field: %w", err)
+	}
+
+	if warn.Inverted || crit.Inverted {
+		return nil
+	}
+
+	if warn.Start <= crit.Start && crit.End <= warn.End {
+		return fmt.Errorf(
+			"crit range %q of metric %q is contained within warn range %q: %w",
+			pd.Crit,
+			pd.Label,
+			pd.Warn,
+			ErrPerformanceDataInconsistentThresholds,
+		)
+	}
+
+	return nil
+}
+
+// InferredBounds returns the lower and upper bounds for the performance data
+// metric, intended for use by graphing tools as sensible axis limits. Each
+// bound is determined separately using this order of precedence:
+//
+//  1. explicit Min (lower bound) or Max (upper bound) field value
+//  2. finite Start (lower bound) or End (upper bound) of the Crit threshold
+//  3. finite Start (lower bound) or End (upper bound) of the Warn threshold
+//
+// A bound which cannot be determined is returned as math.Inf(-1) (lower) or
+// math.Inf(1) (upper). Unparseable field values are ignored. The ok return
+// value is false if neither bound could be determined (e.g., Min and Max are
+// empty and both thresholds are empty or open-ended).
+func (pd PerformanceData) InferredBounds() (min float64, max float64, ok bool) {
+	min, max = math.Inf(-1), math.Inf(1)
+
+	var thresholds []Threshold
+	for _, field := range []string{pd.Crit, pd.Warn} {
+		if t, err := ParseThreshold(field); err == nil {
+			thresholds = append(thresholds, t)
+		}
+	}
+
+	if v, err := strconv.ParseFloat(strings.TrimSpace(pd.Min), 64); err == nil {
+		min = v
+	} else {
+		for _, t := range thresholds {
+			if !math.IsInf(t.Start, 0) {
+				min = t.Start
+				break
+			}
+		}
+	}
+
+	if v, err := strconv.ParseFloat(strings.TrimSpace(pd.Max), 64); err == nil {
+		max = v
+	} else {
+		for _, t := range thresholds {
+			if !math.IsInf(t.End, 0) {
+				max = t.End
+				break
+			}
+		}
+	}
+
+	ok = !math.IsInf(min, 0) || !math.IsInf(max, 0)
+
+	return min, max, ok
+}
+
+// ThresholdBands returns the lower and upper bounds of the Warn and Crit
+// threshold ranges for use when rendering threshold bands on a graph.
+// Unbounded range ends (e.g., "10:" or "~:10") are clamped to the bounds
+// returned by InferredBounds; an end which cannot be clamped because the
+// corresponding bound cannot be inferred remains infinite. An empty
+// threshold imposes no constraint and is returned as the full inferred
+// range.
+//
+// The bands describe the ranges as written. For a standard threshold an
+// alert is raised for values outside of the band while for an inverted
+// threshold (see WarnIsInverted and CritIsInverted) an alert is raised for
+// values inside of the band. For example, a Warn threshold of "80" and a
+// Crit threshold of "90" with Min and Max field values of 0 and 100 give
+// bands of [0, 80] and [0, 90] while a Crit threshold of "@~:5" gives a band
+// of [0, 5].
+//
+// An error is returned if either threshold cannot be parsed.
+func (pd PerformanceData) ThresholdBands() (warnBand [2]float64, critBand [2]float64, err error) {
+	warn, err := pd.WarnThreshold()
+	if err != nil {
+		return warnBand, critBand, fmt.Errorf("failed to parse warn field: %w", err)
+	}
+
+	crit, err := pd.CritThreshold()
+	if err != nil {
+		return warnBand, critBand, fmt.Errorf("failed to parse crit field: %w", err)
+	}
+
+	min, max, _ := pd.InferredBounds()
+
+	band := func(t Threshold) [2]float64 {
+		if t.IsEmpty() {
+			return [2]float64{min, max}
+		}
+
+		lower, upper := t.Start, t.End
+		if math.IsInf(lower, -1) {
+			lower = min
+		}
+		if math.IsInf(upper, 1) {
+			upper = max
+		}
+
+		return [2]float64{lower, upper}
+	}
+
+	return band(warn), band(crit), nil
+}
+
+// AlertState evaluates the Value field against the Crit and Warn thresholds
+// and returns the corresponding plugin state exit code:
+//
+//   - StateCRITICALExitCode if the Crit threshold is breached
+//   - StateWARNINGExitCode if the Warn threshold is breached
+//   - StateOKExitCode otherwise (including when no thresholds are set)
+//
+// The Crit threshold takes precedence over the Warn threshold. An error and
+// StateUNKNOWNExitCode are returned if the Value field is "U" (error wraps
+// ErrPerformanceDataValueUndetermined), is not numeric or if either
+// threshold cannot be parsed.
+func (pd PerformanceData) AlertState() (int, error) {
+	if pd.IsValueUndetermined() {
+		return StateUNKNOWNExitCode, fmt.Errorf(
+			"unable to evaluate thresholds for metric %q: %w",
+			pd.Label,
+			ErrPerformanceDataValueUndetermined,
+		)
+	}
+
+	value, err := strconv.ParseFloat(strings.TrimSpace(pd.Value), 64)
+	if err != nil {
+		return StateUNKNOWNExitCode, fmt.Errorf(
+			"failed to parse value %q of metric %q: %w",
+			pd.Value,
+			pd.Label,
+			ErrInvalidPerformanceDataFormat,
+		)
+	}
+
+	return evaluateThresholds(value, pd.Warn, pd.Crit)
+}
+
+// HeadroomToCrit returns the signed distance from the Value field to the
+// nearest boundary of the Crit threshold. A positive result is the margin
+// remaining before the threshold is breached and a negative result is the
+// distance by which the threshold is already breached.
+//
+// For standard (non-inverted) thresholds a value at the boundary (e.g., 90
+// for a Crit threshold of "90") has zero headroom and is not breached. For
+// inverted thresholds the boundaries are included in the alert range, so a
+// value at the boundary has zero headroom but is already breached.
+//
+// Unbounded ends of an open-ended range are infinitely far away and are
+// never the nearest boundary unless both ends are unbounded; math.Inf(1) is
+// returned for a Crit threshold of "~:" (no bounds) or if the Crit field is
+// empty. For example, a value of 20 has 10 headroom for a Crit threshold of
+// "10:" (the lower bound) rather than infinite headroom.
+//
+// An error is returned if the Value field is "U" (error wraps
+// ErrPerformanceDataValueUndetermined), is not numeric or if the Crit
+// threshold cannot be parsed.
+func (pd PerformanceData) HeadroomToCrit() (float64, error) {
+	if pd.IsValueUndetermined() {
+		return 0, fmt.Errorf(
+			"unable to determine headroom for metric %q: %w",
+			pd.Label,
+			ErrPerformanceDataValueUndetermined,
+		)
+	}
+
+	value, err := strconv.ParseFloat(strings.TrimSpace(pd.Value), 64)
+	if err != nil {
+		return 0, fmt.Errorf(
+			"failed to parse value %q of metric %q: %w",
+			pd.Value,
+			pd.Label,
+			ErrInvalidPerformanceDataFormat,
+		)
+	}
+
+	crit, err := pd.CritThreshold()
+	if err != nil {
+		return 0, fmt.Errorf("failed to parse crit field: %w", err)
+	}
+
+	if crit.IsEmpty() {
+		return math.Inf(1), nil
+	}
+
+	switch {
+	case !crit.Inverted:
+		// Positive inside of the range, negative outside of it.
+		return math.Min(value-crit.Start, crit.End-value), nil
+
+	case value < crit.Start:
+		return crit.Start - value, nil
+
+	case value > crit.End:
+		return value - crit.End, nil
+
+	default:
+		// Inside of the inverted range; already breached.
+		return -math.Min(value-crit.Start, crit.End-value), nil
+	}
+}
+
+// AssertThresholds parses the given Warn and Crit threshold strings (in the
+// Nagios range format) and returns the plugin state exit code for the given
+// value. This is intended as a convenience for plugin unit tests asserting
+// threshold logic.
+//
+// The Crit threshold takes precedence over the Warn threshold:
+// StateCRITICALExitCode is returned if the Crit threshold is breached,
+// StateWARNINGExitCode if the Warn threshold is breached and StateOKExitCode
+// otherwise. An empty threshold string is not evaluated. An error and
+// StateUNKNOWNExitCode are returned if either threshold cannot be parsed.
+func AssertThresholds(value float64, warn string, crit string) (state int, err error) {
+	return evaluateThresholds(value, warn, crit)
+}
+
+// evaluateThresholds returns the plugin state exit code for the given value
+// evaluated against the given (optional) Warn and Crit threshold strings.
+func evaluateThresholds(value float64, warn string, crit string) (int, error) {
+	critThreshold, err := parseOptionalThreshold(crit)
+	if err != nil {
+		return StateUNKNOWNExitCode, fmt.Errorf("failed to parse crit field: %w", err)
+	}
+
+	warnThreshold, err := parseOptionalThreshold(warn)
+	if err != nil {
+		return StateUNKNOWNExitCode, fmt.Errorf("failed to parse warn field: %w", err)
+	}
+
+	switch {
+	case !critThreshold.IsEmpty() && critThreshold.Evaluate(value):
+		return StateCRITICALExitCode, nil
+	case !warnThreshold.IsEmpty() && warnThreshold.Evaluate(value):
+		return StateWARNINGExitCode, nil
+	default:
+		return StateOKExitCode, nil
+	}
+}
+
+// nonNegativeUnitsOfMeasurement are the units of measurement for which
+// metric values are assumed to be non-negative when the Min field is not
+// set.
+var nonNegativeUnitsOfMeasurement = []string{"%", "B", "KB", "MB", "GB", "TB", "c"}
+
+// Canonicalize returns a copy of the PerformanceData value with Warn and
+// Crit thresholds which impose no constraint on the metric replaced with an
+// empty string. A threshold is only collapsed if it is provably equivalent
+// to "no threshold" for the domain of the metric:
+//
+//   - the threshold is not inverted, and
+//   - the start of the range is at or below the lower bound of the domain,
+//     and
+//   - the end of the range is at or above the upper bound of the domain
+//
+// The lower bound of the domain is the Min field value if set, otherwise 0
+// for metrics using the "%", "B", "KB", "MB", "GB", "TB" or "c" units of
+// measurement, otherwise negative infinity. The upper bound of the domain is
+// the Max field value if set, otherwise 100 for metrics using the "%" unit
+// of measurement, otherwise positive infinity.
+//
+// For example, "~:" is always collapsed, "0:" is collapsed for a
+// non-negative metric and "100" (0:100) is collapsed for a percentage, but
+// "1:" is not collapsed. Inverted thresholds and thresholds which cannot be
+// parsed are left unchanged.
+func (pd PerformanceData) Canonicalize() PerformanceData {
+	canonical := pd.Clone()
+
+	domainMin, domainMax := math.Inf(-1), math.Inf(1)
+
+	switch {
+	case strings.TrimSpace(pd.Min) != "":
+		if v, err := strconv.ParseFloat(strings.TrimSpace(pd.Min), 64); err == nil {
+			domainMin = v
+		}
+	case inList(pd.UnitOfMeasurement, nonNegativeUnitsOfMeasurement, false):
+		domainMin = 0
+	}
+
+	switch {
+	case strings.TrimSpace(pd.Max) != "":
+		if v, err := strconv.ParseFloat(strings.TrimSpace(pd.Max), 64); err == nil {
+			domainMax = v
+		}
+	case pd.UnitOfMeasurement == "%":
+		domainMax = 100
+	}
+
+	unconstrained := func(field string) bool {
+		t, err := parseOptionalThreshold(field)
+		if err != nil || t.IsEmpty() || t.Inverted {
+			return false
+		}
+
+		return t.Start <= domainMin && t.End >= domainMax
+	}
+
+	if unconstrained(pd.Warn) {
+		canonical.Warn = ""
+	}
+
+	if unconstrained(pd.Crit) {
+		canonical.Crit = ""
+	}
+
+	return canonical
+}
+
+// WarnThreshold parses the Warn field into a Threshold value. A zero value
+// (empty) Threshold is returned if the Warn field is empty. An error is
+// returned if the field cannot be parsed.
+//
+// The field is parsed on every call; results are not cached.
+func (pd PerformanceData) WarnThreshold() (Threshold, error) {
+	return parseOptionalThreshold(pd.Warn)
+}
+
+// CritThreshold parses the Crit field into a Threshold value. A zero value
+// (empty) Threshold is returned if the Crit field is empty. An error is
+// returned if the field cannot be parsed.
+//
+// The field is parsed on every call; results are not cached.
+func (pd PerformanceData) CritThreshold() (Threshold, error) {
+	return parseOptionalThreshold(pd.Crit)
+}
+
+// WarnIsInverted reports whether the Warn field uses the inverted ("@")
+// range form, i.e., an alert is raised for values inside of the range rather
+// than outside of it. False is returned if the Warn field is empty. An error
+// is returned if the field cannot be parsed.
+func (pd PerformanceData) WarnIsInverted() (bool, error) {
+	t, err := pd.WarnThreshold()
+	if err != nil {
+		return false, err
+	}
+
+	return t.Inverted, nil
+}
+
+// CritIsInverted reports whether the Crit field uses the inverted ("@")
+// range form, i.e., an alert is raised for values inside of the range rather
+// than outside of it. False is returned if the Crit field is empty. An error
+// is returned if the field cannot be parsed.
+func (pd PerformanceData) CritIsInverted() (bool, error) {
+	t, err := pd.CritThreshold()
+	if err != nil {
+		return false, err
+	}
+
+	return t.Inverted, nil
+}
+
+// parseOptionalThreshold parses the given string into a Threshold value. A
+// zero value (empty) Threshold is returned if the input string is empty.
+func parseOptionalThreshold(s string) (Threshold, error) {
+	if strings.TrimSpace(s) == "" {
+		return Threshold{}, nil
+	}
+
+	return ParseThreshold(s)
+}
+
+// ThresholdMergePolicy indicates how two thresholds for the same metric are
+// reconciled by MergeThresholds.
+type ThresholdMergePolicy int
+
+const (
+	// ThresholdMergeStricter indicates that the merged threshold raises an
+	// alert for any value which either threshold raises an alert for.
+	ThresholdMergeStricter ThresholdMergePolicy = iota
+
+	// ThresholdMergeLooser indicates that the merged threshold only raises
+	// an alert for values which both thresholds raise an alert for.
+	ThresholdMergeLooser
+
+	// ThresholdMergePreferFirst indicates that the first threshold is used
+	// unless it is empty, in which case the second threshold is used.
+	ThresholdMergePreferFirst
+)
+
+// MergeThresholds merges the given thresholds (in the Nagios range format)
+// using the specified policy and returns the result in canonical form (see
+// Threshold.String). An empty threshold imposes no constraint.
+//
+// For standard (non-inverted) thresholds an alert is raised for values
+// outside of the range, so the stricter result is the intersection of the
+// ranges (e.g., "10:" and "~:50" give "10:50", "10:" and "20:" give "20:")
+// and the looser result is the smallest range containing both ranges (e.g.,
+// "10:20" and "30:40" give "10:40", "10:" and "~:50" give "~:"). An
+// open-ended range is looser than any bounded range on that side. For
+// inverted thresholds an alert is raised for values inside of the range, so
+// the roles are reversed: the stricter result is the smallest range
+// containing both ranges and the looser result is the intersection of the
+// ranges (empty if the ranges do not overlap).
+//
+// When merging with an empty threshold the stricter result is the non-empty
+// threshold and the looser result is empty.
+//
+// An error is returned if either threshold cannot be parsed, if an inverted
+// threshold is merged with a non-inverted threshold using the stricter or
+// looser policies, if the stricter merge of non-overlapping standard ranges
+// is requested (no value would be considered OK), if the stricter merge of
+// inverted ranges covers all values (e.g., "@~:5" and "@3:") or if
+// the policy is not recognized.
+func MergeThresholds(a string, b string, policy ThresholdMergePolicy) (string, error) {
+	first, err := parseOptionalThreshold(a)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse first threshold: %w", err)
+	}
+
+	second, err := parseOptionalThreshold(b)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse second threshold: %w", err)
+	}
+
+	switch policy {
+	case ThresholdMergePreferFirst:
+		if !first.IsEmpty() {
+			return first.String(), nil
+		}
+		return second.String(), nil
+
+	case ThresholdMergeStricter, ThresholdMergeLooser:
+	default:
+		return "", fmt.Errorf("unsupported threshold merge policy %d", policy)
+	}
+
+	if first.IsEmpty() || second.IsEmpty() {
+		if policy == ThresholdMergeLooser {
+			return "", nil
+		}
+		if first.IsEmpty() {
+			return second.String(), nil
+		}
+		return first.String(), nil
+	}
+
+	if first.Inverted != second.Inverted {
+		return "", fmt.Errorf(
+			"unable to merge inverted and non-inverted thresholds %q and %q: %w",
+			a,
+			b,
+			ErrInvalidPerformanceDataFormat,
+		)
+	}
+
+	// Determine whether the merged range is the intersection or the smallest
+	// range containing both ranges.
+	intersect := policy == ThresholdMergeStricter
+	if first.Inverted {
+		intersect = !intersect
+	}
+
+	merged := Threshold{Inverted: first.Inverted, specified: true}
+
+	switch {
+	case intersect:
+		merged.Start = math.Max(first.Start, second.Start)
+		merged.End = math.Min(first.End, second.End)
+
+		if merged.Start > merged.End {
+			if first.Inverted {
+				// Non-overlapping inverted ranges: no value is inside both.
+				return "", nil
+			}
+
+			return "", fmt.Errorf(
+				"thresholds %q and %q do not overlap: %w",
+				a,
+				b,
+				ErrInvalidPerformanceDataFormat,
+			)
+		}
+
+	default:
+		merged.Start = math.Min(first.Start, second.Start)
+		merged.End = math.Max(first.End, second.End)
+
+		if merged.Inverted && math.IsInf(merged.Start, -1) && math.IsInf(merged.End, 1) {
+			return "", fmt.Errorf(
+				"thresholds %q and %q together cover all values: %w",
+				a,
+				b,
+				ErrInvalidPerformanceDataFormat,
+			)
+		}
+	}
+
+	return merged.String(), nil
+}
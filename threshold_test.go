@@ -0,0 +1,997 @@
+// Copyright 2023 Adam Chalkley
+//
+// https://github.com/atc0005/go-nagios
+//
+// Licensed under the MIT License. See LICENSE file in the project root for
+// full license information.
+
+package nagios_test
+
+import (
+	"errors"
+	"math"
+	"testing"
+
+	"github.com/atc0005/go-nagios"
+)
+
+// TestParseThreshold asserts that threshold strings in the Nagios range
+// format are parsed as expected.
+func TestParseThreshold(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		input        string
+		want         nagios.Threshold
+		wantErr      bool
+		wantRangeErr bool
+	}{
+		"single value": {
+			input: "10",
+			want:  nagios.Threshold{Start: 0, End: 10},
+		},
+		"single zero value": {
+			input: "0",
+			want:  nagios.Threshold{Start: 0, End: 0},
+		},
+		"single negative value": {
+			input:        "-5",
+			wantErr:      true,
+			wantRangeErr: true,
+		},
+		"inverted single negative value": {
+			input:        "@-5",
+			wantErr:      true,
+			wantRangeErr: true,
+		},
+		"start to positive infinity": {
+			input: "10:",
+			want:  nagios.Threshold{Start: 10, End: math.Inf(1)},
+		},
+		"negative infinity to end": {
+			input: "~:10",
+			want:  nagios.Threshold{Start: math.Inf(-1), End: 10},
+		},
+		"negative infinity to positive infinity": {
+			input: "~:",
+			want:  nagios.Threshold{Start: math.Inf(-1), End: math.Inf(1)},
+		},
+		"start to end": {
+			input: "10:20",
+			want:  nagios.Threshold{Start: 10, End: 20},
+		},
+		"negative float start to end": {
+			input: "-5.5:-1",
+			want:  nagios.Threshold{Start: -5.5, End: -1},
+		},
+		"inverted start to end": {
+			input: "@10:20",
+			want:  nagios.Threshold{Start: 10, End: 20, Inverted: true},
+		},
+		"inverted negative infinity to end": {
+			input: "@~:10",
+			want:  nagios.Threshold{Start: math.Inf(-1), End: 10, Inverted: true},
+		},
+		"infinity token inside range": {
+			input:   "1~0",
+			wantErr: true,
+		},
+		"infinity token as end of range": {
+			input:   "10:~",
+			wantErr: true,
+		},
+		"start greater than end": {
+			input:        "20:10",
+			wantErr:      true,
+			wantRangeErr: true,
+		},
+		"empty": {
+			input:   "",
+			wantErr: true,
+		},
+	}
+
+	for name, tt := range tests {
+		tt := tt
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := nagios.ParseThreshold(tt.input)
+			switch {
+			case tt.wantErr:
+				if !errors.Is(err, nagios.ErrInvalidPerformanceDataFormat) {
+					t.Fatalf("\nwant error %v\ngot %v", nagios.ErrInvalidPerformanceDataFormat, err)
+				}
+				if tt.wantRangeErr && !errors.Is(err, nagios.ErrInvalidRangeThreshold) {
+					t.Fatalf("\nwant error %v\ngot %v", nagios.ErrInvalidRangeThreshold, err)
+				}
+				return
+			case err != nil:
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if got.Start != tt.want.Start || got.End != tt.want.End || got.Inverted != tt.want.Inverted {
+				t.Errorf("\nwant %+v\ngot %+v", tt.want, got)
+			}
+
+			if got.IsEmpty() {
+				t.Errorf("want parsed threshold to be non-empty")
+			}
+		})
+	}
+}
+
+// TestThresholdEvaluate asserts that values are evaluated against parsed
+// thresholds as expected.
+func TestThresholdEvaluate(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		threshold string
+		value     float64
+		want      bool
+	}{
+		"inside 0 to end":                  {threshold: "10", value: 10, want: false},
+		"above 0 to end":                   {threshold: "10", value: 11, want: true},
+		"below 0 to end":                   {threshold: "10", value: -1, want: true},
+		"below start to infinity":          {threshold: "10:", value: 9, want: true},
+		"very negative in -infinity range": {threshold: "~:10", value: -1e9, want: false},
+		"above -infinity range end":        {threshold: "~:10", value: 10.1, want: true},
+		"anything in -infinity to infinity": {
+			threshold: "~:", value: 1e300, want: false,
+		},
+		"inside inverted range":  {threshold: "@10:20", value: 10, want: true},
+		"outside inverted range": {threshold: "@10:20", value: 21, want: false},
+	}
+
+	for name, tt := range tests {
+		tt := tt
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			threshold, err := nagios.ParseThreshold(tt.threshold)
+			if err != nil {
+				t.Fatalf("failed to parse threshold %q: %v", tt.threshold, err)
+			}
+
+			if got := threshold.Evaluate(tt.value); got != tt.want {
+				t.Errorf("\nwant %t for value %v\ngot %t", tt.want, tt.value, got)
+			}
+		})
+	}
+}
+
+// TestPerformanceDataThresholdsConsistent asserts that contradictory Warn
+// and Crit thresholds are detected.
+func TestPerformanceDataThresholdsConsistent(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		warn    string
+		crit    string
+		wantErr error
+	}{
+		"warn below crit":               {warn: "80", crit: "90", wantErr: nil},
+		"warn and crit ranges":          {warn: "10:20", crit: "5:30", wantErr: nil},
+		"partially overlapping ranges":  {warn: "10:20", crit: "15:30", wantErr: nil},
+		"lower bound thresholds":        {warn: "20:", crit: "10:", wantErr: nil},
+		"crit contained in warn":        {warn: "90", crit: "80", wantErr: nagios.ErrPerformanceDataInconsistentThresholds},
+		"identical thresholds":          {warn: "80", crit: "80", wantErr: nagios.ErrPerformanceDataInconsistentThresholds},
+		"crit range inside warn range":  {warn: "0:100", crit: "10:20", wantErr: nagios.ErrPerformanceDataInconsistentThresholds},
+		"lower bound crit above warn":   {warn: "10:", crit: "20:", wantErr: nagios.ErrPerformanceDataInconsistentThresholds},
+		"inverted threshold not judged": {warn: "@10:20", crit: "15", wantErr: nil},
+		"empty crit":                    {warn: "80", crit: "", wantErr: nil},
+		"invalid warn":                  {warn: "@@", crit: "80", wantErr: nagios.ErrInvalidPerformanceDataFormat},
+	}
+
+	for name, tt := range tests {
+		tt := tt
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			pd := nagios.PerformanceData{Label: "metric", Value: "1", Warn: tt.warn, Crit: tt.crit}
+
+			err := pd.ThresholdsConsistent()
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("\nwant error %v\ngot %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+// TestPerformanceDataInferredBounds asserts that metric bounds are inferred
+// using the documented order of precedence.
+func TestPerformanceDataInferredBounds(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		pd      nagios.PerformanceData
+		wantMin float64
+		wantMax float64
+		wantOK  bool
+	}{
+		"explicit min and max win": {
+			pd:      nagios.PerformanceData{Warn: "80", Crit: "90", Min: "-10", Max: "100"},
+			wantMin: -10, wantMax: 100, wantOK: true,
+		},
+		"crit bounds": {
+			pd:      nagios.PerformanceData{Warn: "10:80", Crit: "5:90"},
+			wantMin: 5, wantMax: 90, wantOK: true,
+		},
+		"warn bounds used when crit open-ended": {
+			pd:      nagios.PerformanceData{Warn: "10:80", Crit: "~:"},
+			wantMin: 10, wantMax: 80, wantOK: true,
+		},
+		"crit lower bound with warn upper bound": {
+			pd:      nagios.PerformanceData{Warn: "80", Crit: "5:"},
+			wantMin: 5, wantMax: 80, wantOK: true,
+		},
+		"explicit max with inferred min": {
+			pd:      nagios.PerformanceData{Crit: "5:", Max: "100"},
+			wantMin: 5, wantMax: 100, wantOK: true,
+		},
+		"open-ended thresholds": {
+			pd:      nagios.PerformanceData{Warn: "~:", Crit: "~:"},
+			wantMin: math.Inf(-1), wantMax: math.Inf(1), wantOK: false,
+		},
+		"no thresholds": {
+			pd:      nagios.PerformanceData{},
+			wantMin: math.Inf(-1), wantMax: math.Inf(1), wantOK: false,
+		},
+	}
+
+	for name, tt := range tests {
+		tt := tt
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			min, max, ok := tt.pd.InferredBounds()
+			if min != tt.wantMin || max != tt.wantMax || ok != tt.wantOK {
+				t.Errorf(
+					"\nwant %v, %v, %t\ngot %v, %v, %t",
+					tt.wantMin, tt.wantMax, tt.wantOK, min, max, ok,
+				)
+			}
+		})
+	}
+}
+
+// TestPerformanceDataWarnCritThreshold asserts that the Warn and Crit fields
+// are parsed into Threshold values on demand.
+func TestPerformanceDataWarnCritThreshold(t *testing.T) {
+	t.Parallel()
+
+	pd := nagios.PerformanceData{Label: "errors", Value: "0", Warn: "0", Crit: ""}
+
+	warn, err := pd.WarnThreshold()
+	if err != nil {
+		t.Fatalf("unexpected error parsing warn threshold: %v", err)
+	}
+
+	if warn.IsEmpty() || warn.Start != 0 || warn.End != 0 {
+		t.Errorf("want non-empty 0:0 warn threshold, got %+v", warn)
+	}
+
+	crit, err := pd.CritThreshold()
+	if err != nil {
+		t.Fatalf("unexpected error parsing empty crit threshold: %v", err)
+	}
+
+	if !crit.IsEmpty() || crit != (nagios.Threshold{}) {
+		t.Errorf("want zero value empty crit threshold, got %+v", crit)
+	}
+
+	pd.Crit = "@@"
+	if _, err := pd.CritThreshold(); !errors.Is(err, nagios.ErrInvalidPerformanceDataFormat) {
+		t.Errorf("want error for invalid crit threshold, got %v", err)
+	}
+}
+
+// TestThresholdString asserts that parsed thresholds are rendered in
+// canonical form and survive a parse/render round trip.
+func TestThresholdString(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		input string
+		want  string
+	}{
+		"single value":             {input: "10", want: "10"},
+		"zero start shorthand":     {input: "0:10", want: "10"},
+		"start to infinity":        {input: "10:", want: "10:"},
+		"zero start to infinity":   {input: "0:", want: "0:"},
+		"negative infinity to end": {input: "~:10", want: "~:10"},
+		"unbounded":                {input: "~:", want: "~:"},
+		"start to end":             {input: "10:20", want: "10:20"},
+		"negative floats":          {input: "-5.5:-1", want: "-5.5:-1"},
+		"trailing zeros":           {input: "5.000", want: "5"},
+		"inverted single value":    {input: "@10", want: "@10"},
+		"inverted start to end":    {input: "@10:20", want: "@10:20"},
+		"inverted negative inf":    {input: "@~:10", want: "@~:10"},
+		"inverted start to inf":    {input: "@10:", want: "@10:"},
+		"inverted zero start":      {input: "@0:10", want: "@10"},
+	}
+
+	for name, tt := range tests {
+		tt := tt
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			threshold, err := nagios.ParseThreshold(tt.input)
+			if err != nil {
+				t.Fatalf("failed to parse threshold %q: %v", tt.input, err)
+			}
+
+			got := threshold.String()
+			if got != tt.want {
+				t.Fatalf("\nwant %q\ngot %q", tt.want, got)
+			}
+
+			reparsed, err := nagios.ParseThreshold(got)
+			if err != nil {
+				t.Fatalf("failed to parse rendered threshold %q: %v", got, err)
+			}
+
+			if reparsed != threshold {
+				t.Errorf("\nwant round trip result %+v\ngot %+v", threshold, reparsed)
+			}
+		})
+	}
+
+	if got := (nagios.Threshold{}).String(); got != "" {
+		t.Errorf("want empty string for empty threshold, got %q", got)
+	}
+}
+
+// TestNewThreshold asserts that thresholds constructed programmatically are
+// validated and rendered as expected.
+func TestNewThreshold(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		construct func() (nagios.Threshold, error)
+		want      string
+		wantErr   bool
+	}{
+		"start to end": {
+			construct: func() (nagios.Threshold, error) { return nagios.NewThreshold(10, 20, false) },
+			want:      "10:20",
+		},
+		"inverted start to end": {
+			construct: func() (nagios.Threshold, error) { return nagios.NewThreshold(10, 20, true) },
+			want:      "@10:20",
+		},
+		"unbounded": {
+			construct: func() (nagios.Threshold, error) { return nagios.NewThreshold(math.Inf(-1), math.Inf(1), false) },
+			want:      "~:",
+		},
+		"greater than": {
+			construct: func() (nagios.Threshold, error) { return nagios.NewThresholdGreaterThan(90) },
+			want:      "~:90",
+		},
+		"less than": {
+			construct: func() (nagios.Threshold, error) { return nagios.NewThresholdLessThan(5) },
+			want:      "5:",
+		},
+		"start greater than end": {
+			construct: func() (nagios.Threshold, error) { return nagios.NewThreshold(20, 10, false) },
+			wantErr:   true,
+		},
+		"NaN end": {
+			construct: func() (nagios.Threshold, error) { return nagios.NewThreshold(0, math.NaN(), false) },
+			wantErr:   true,
+		},
+		"negative infinity start": {
+			construct: func() (nagios.Threshold, error) { return nagios.NewThreshold(math.Inf(-1), -5, false) },
+			want:      "~:-5",
+		},
+		"positive infinity end": {
+			construct: func() (nagios.Threshold, error) { return nagios.NewThreshold(-5, math.Inf(1), true) },
+			want:      "@-5:",
+		},
+		"inverted unbounded": {
+			construct: func() (nagios.Threshold, error) { return nagios.NewThreshold(math.Inf(-1), math.Inf(1), true) },
+			wantErr:   true,
+		},
+		"positive infinity start and end": {
+			construct: func() (nagios.Threshold, error) { return nagios.NewThreshold(math.Inf(1), math.Inf(1), false) },
+			wantErr:   true,
+		},
+		"negative infinity start and end": {
+			construct: func() (nagios.Threshold, error) { return nagios.NewThreshold(math.Inf(-1), math.Inf(-1), false) },
+			wantErr:   true,
+		},
+		"positive infinity start": {
+			construct: func() (nagios.Threshold, error) { return nagios.NewThreshold(math.Inf(1), 10, false) },
+			wantErr:   true,
+		},
+		"negative infinity end": {
+			construct: func() (nagios.Threshold, error) { return nagios.NewThreshold(10, math.Inf(-1), true) },
+			wantErr:   true,
+		},
+	}
+
+	for name, tt := range tests {
+		tt := tt
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := tt.construct()
+			switch {
+			case tt.wantErr:
+				if !errors.Is(err, nagios.ErrInvalidRangeThreshold) {
+					t.Fatalf("\nwant error %v\ngot %v", nagios.ErrInvalidRangeThreshold, err)
+				}
+				if !errors.Is(err, nagios.ErrInvalidPerformanceDataFormat) {
+					t.Fatalf("\nwant error %v\ngot %v", nagios.ErrInvalidPerformanceDataFormat, err)
+				}
+				return
+			case err != nil:
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if got.IsEmpty() {
+				t.Errorf("want constructed threshold to be non-empty")
+			}
+
+			if got.String() != tt.want {
+				t.Errorf("\nwant %q\ngot %q", tt.want, got.String())
+			}
+
+			reparsed, err := nagios.ParseThreshold(got.String())
+			if err != nil {
+				t.Fatalf("failed to parse rendered threshold %q: %v", got.String(), err)
+			}
+
+			if reparsed != got {
+				t.Errorf("round trip:\nwant %+v\ngot %+v", got, reparsed)
+			}
+		})
+	}
+}
+
+// TestThresholdEvaluatePercent asserts that percentage values are evaluated
+// against thresholds using the implicit 0 to 100 range.
+func TestThresholdEvaluatePercent(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		threshold string
+		value     float64
+		want      bool
+		wantErr   bool
+	}{
+		"lower boundary inside":        {threshold: "90", value: 0, want: false},
+		"upper boundary outside":       {threshold: "90", value: 100, want: true},
+		"upper boundary at threshold":  {threshold: "100", value: 100, want: false},
+		"lower boundary outside":       {threshold: "10:", value: 0, want: true},
+		"open-ended range at boundary": {threshold: "~:90", value: 0, want: false},
+		"inverted range at boundary":   {threshold: "@95:100", value: 100, want: true},
+		"below implicit range":         {threshold: "90", value: -1, want: true, wantErr: true},
+		"above implicit range":         {threshold: "90", value: 100.5, want: true, wantErr: true},
+		"NaN value":                    {threshold: "90", value: math.NaN(), want: true, wantErr: true},
+	}
+
+	for name, tt := range tests {
+		tt := tt
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			threshold, err := nagios.ParseThreshold(tt.threshold)
+			if err != nil {
+				t.Fatalf("failed to parse threshold %q: %v", tt.threshold, err)
+			}
+
+			got, err := threshold.EvaluatePercent(tt.value)
+			switch {
+			case tt.wantErr && !errors.Is(err, nagios.ErrInvalidPerformanceDataFormat):
+				t.Errorf("\nwant error %v\ngot %v", nagios.ErrInvalidPerformanceDataFormat, err)
+			case !tt.wantErr && err != nil:
+				t.Errorf("unexpected error: %v", err)
+			}
+
+			if got != tt.want {
+				t.Errorf("\nwant %t for value %v\ngot %t", tt.want, tt.value, got)
+			}
+		})
+	}
+}
+
+// TestPerformanceDataAlertState asserts that the plugin state is derived
+// from the Warn and Crit thresholds as expected.
+func TestPerformanceDataAlertState(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		pd      nagios.PerformanceData
+		want    int
+		wantErr error
+	}{
+		"ok": {
+			pd:   nagios.PerformanceData{Label: "load1", Value: "0.5", Warn: "5", Crit: "10"},
+			want: nagios.StateOKExitCode,
+		},
+		"ok at warn boundary": {
+			pd:   nagios.PerformanceData{Label: "load1", Value: "5", Warn: "5", Crit: "10"},
+			want: nagios.StateOKExitCode,
+		},
+		"warn breach": {
+			pd:   nagios.PerformanceData{Label: "load1", Value: "6", Warn: "5", Crit: "10"},
+			want: nagios.StateWARNINGExitCode,
+		},
+		"crit breach": {
+			pd:   nagios.PerformanceData{Label: "load1", Value: "11", Warn: "5", Crit: "10"},
+			want: nagios.StateCRITICALExitCode,
+		},
+		"crit takes precedence": {
+			pd:   nagios.PerformanceData{Label: "free", Value: "1", Warn: "10:", Crit: "5:"},
+			want: nagios.StateCRITICALExitCode,
+		},
+		"crit only": {
+			pd:   nagios.PerformanceData{Label: "errors", Value: "3", Crit: "0"},
+			want: nagios.StateCRITICALExitCode,
+		},
+		"no thresholds": {
+			pd:   nagios.PerformanceData{Label: "errors", Value: "3"},
+			want: nagios.StateOKExitCode,
+		},
+		"undetermined value": {
+			pd:      nagios.PerformanceData{Label: "load1", Value: "U", Warn: "5", Crit: "10"},
+			want:    nagios.StateUNKNOWNExitCode,
+			wantErr: nagios.ErrPerformanceDataValueUndetermined,
+		},
+		"invalid threshold": {
+			pd:      nagios.PerformanceData{Label: "load1", Value: "1", Warn: "@@", Crit: "10"},
+			want:    nagios.StateUNKNOWNExitCode,
+			wantErr: nagios.ErrInvalidPerformanceDataFormat,
+		},
+	}
+
+	for name, tt := range tests {
+		tt := tt
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := tt.pd.AlertState()
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("\nwant error %v\ngot %v", tt.wantErr, err)
+			}
+
+			if got != tt.want {
+				t.Errorf("\nwant state %s\ngot %s", nagios.ExitCodeToStateLabel(tt.want), nagios.ExitCodeToStateLabel(got))
+			}
+		})
+	}
+}
+
+// TestAssertThresholds asserts that the plugin state is computed from
+// threshold strings as expected, including inverted and open-ended ranges.
+func TestAssertThresholds(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		value   float64
+		warn    string
+		crit    string
+		want    int
+		wantErr bool
+	}{
+		"ok":                         {value: 50, warn: "80", crit: "90", want: nagios.StateOKExitCode},
+		"warning":                    {value: 85, warn: "80", crit: "90", want: nagios.StateWARNINGExitCode},
+		"critical":                   {value: 95, warn: "80", crit: "90", want: nagios.StateCRITICALExitCode},
+		"open-ended lower bound ok":  {value: 50, warn: "20:", crit: "10:", want: nagios.StateOKExitCode},
+		"open-ended lower bound":     {value: 15, warn: "20:", crit: "10:", want: nagios.StateWARNINGExitCode},
+		"negative infinity range":    {value: -1000, warn: "~:50", crit: "~:100", want: nagios.StateOKExitCode},
+		"negative infinity breached": {value: 101, warn: "~:50", crit: "~:100", want: nagios.StateCRITICALExitCode},
+		"inverted warn inside":       {value: 15, warn: "@10:20", crit: "@0:5", want: nagios.StateWARNINGExitCode},
+		"inverted crit inside":       {value: 3, warn: "@10:20", crit: "@0:5", want: nagios.StateCRITICALExitCode},
+		"inverted outside":           {value: 7, warn: "@10:20", crit: "@0:5", want: nagios.StateOKExitCode},
+		"no thresholds":              {value: 7, want: nagios.StateOKExitCode},
+		"invalid crit":               {value: 7, warn: "10", crit: "10:5", want: nagios.StateUNKNOWNExitCode, wantErr: true},
+	}
+
+	for name, tt := range tests {
+		tt := tt
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := nagios.AssertThresholds(tt.value, tt.warn, tt.crit)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("nagios.AssertThresholds() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if got != tt.want {
+				t.Errorf("\nwant state %s\ngot %s", nagios.ExitCodeToStateLabel(tt.want), nagios.ExitCodeToStateLabel(got))
+			}
+		})
+	}
+}
+
+// TestPerformanceDataCanonicalize asserts that only thresholds which impose
+// no constraint on the metric are collapsed to empty strings.
+func TestPerformanceDataCanonicalize(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		pd       nagios.PerformanceData
+		wantWarn string
+		wantCrit string
+	}{
+		"unbounded range collapsed": {
+			pd:       nagios.PerformanceData{Label: "offset", Value: "-1", UnitOfMeasurement: "s", Warn: "~:", Crit: "~:10"},
+			wantWarn: "",
+			wantCrit: "~:10",
+		},
+		"zero to infinity collapsed for non-negative uom": {
+			pd:       nagios.PerformanceData{Label: "used", Value: "10", UnitOfMeasurement: "B", Warn: "0:", Crit: "1:"},
+			wantWarn: "",
+			wantCrit: "1:",
+		},
+		"zero to infinity collapsed for explicit min": {
+			pd:       nagios.PerformanceData{Label: "users", Value: "10", Warn: "0:", Crit: "5", Min: "0"},
+			wantWarn: "",
+			wantCrit: "5",
+		},
+		"zero to infinity retained for unknown domain": {
+			pd:       nagios.PerformanceData{Label: "offset", Value: "-1", UnitOfMeasurement: "s", Warn: "0:"},
+			wantWarn: "0:",
+		},
+		"one to infinity not collapsed": {
+			pd:       nagios.PerformanceData{Label: "used", Value: "10", UnitOfMeasurement: "%", Warn: "1:"},
+			wantWarn: "1:",
+		},
+		"full percentage range collapsed": {
+			pd:       nagios.PerformanceData{Label: "used", Value: "10", UnitOfMeasurement: "%", Warn: "100", Crit: "90"},
+			wantWarn: "",
+			wantCrit: "90",
+		},
+		"range covering explicit min and max collapsed": {
+			pd:       nagios.PerformanceData{Label: "temp", Value: "10", Warn: "-10:60", Crit: "-5:55", Min: "-10", Max: "60"},
+			wantWarn: "",
+			wantCrit: "-5:55",
+		},
+		"inverted unbounded range not collapsed": {
+			pd:       nagios.PerformanceData{Label: "temp", Value: "10", Warn: "@~:"},
+			wantWarn: "@~:",
+		},
+		"invalid threshold unchanged": {
+			pd:       nagios.PerformanceData{Label: "temp", Value: "10", Warn: "@@"},
+			wantWarn: "@@",
+		},
+	}
+
+	for name, tt := range tests {
+		tt := tt
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			original := tt.pd.Clone()
+
+			got := tt.pd.Canonicalize()
+			if got.Warn != tt.wantWarn || got.Crit != tt.wantCrit {
+				t.Errorf("\nwant warn %q, crit %q\ngot warn %q, crit %q", tt.wantWarn, tt.wantCrit, got.Warn, got.Crit)
+			}
+
+			if !tt.pd.Equal(original) {
+				t.Errorf("Canonicalize modified original value: %+v", tt.pd)
+			}
+		})
+	}
+}
+
+// TestMergeThresholds asserts that thresholds are merged as expected for
+// each supported policy.
+func TestMergeThresholds(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		a, b    string
+		policy  nagios.ThresholdMergePolicy
+		want    string
+		wantErr bool
+	}{
+		"stricter upper bounds":             {a: "80", b: "90", policy: nagios.ThresholdMergeStricter, want: "80"},
+		"looser upper bounds":               {a: "80", b: "90", policy: nagios.ThresholdMergeLooser, want: "90"},
+		"stricter lower open-ended":         {a: "10:", b: "20:", policy: nagios.ThresholdMergeStricter, want: "20:"},
+		"looser lower open-ended":           {a: "10:", b: "20:", policy: nagios.ThresholdMergeLooser, want: "10:"},
+		"stricter mixed open-ended":         {a: "10:", b: "~:50", policy: nagios.ThresholdMergeStricter, want: "10:50"},
+		"looser mixed open-ended":           {a: "10:", b: "~:50", policy: nagios.ThresholdMergeLooser, want: "~:"},
+		"looser disjoint ranges":            {a: "10:20", b: "30:40", policy: nagios.ThresholdMergeLooser, want: "10:40"},
+		"stricter disjoint ranges":          {a: "10:20", b: "30:40", policy: nagios.ThresholdMergeStricter, wantErr: true},
+		"stricter inverted ranges":          {a: "@10:20", b: "@15:30", policy: nagios.ThresholdMergeStricter, want: "@10:30"},
+		"looser inverted ranges":            {a: "@10:20", b: "@15:30", policy: nagios.ThresholdMergeLooser, want: "@15:20"},
+		"looser disjoint inverted ranges":   {a: "@10:20", b: "@30:40", policy: nagios.ThresholdMergeLooser, want: ""},
+		"stricter inverted open-ended":      {a: "@~:5", b: "@10:20", policy: nagios.ThresholdMergeStricter, want: "@~:20"},
+		"stricter inverted covering all":    {a: "@~:5", b: "@10:", policy: nagios.ThresholdMergeStricter, wantErr: true},
+		"stricter inverted overlapping all": {a: "@~:5", b: "@3:", policy: nagios.ThresholdMergeStricter, wantErr: true},
+		"stricter with empty":               {a: "", b: "90", policy: nagios.ThresholdMergeStricter, want: "90"},
+		"looser with empty":                 {a: "80", b: "", policy: nagios.ThresholdMergeLooser, want: ""},
+		"prefer first":                      {a: "0:80", b: "90", policy: nagios.ThresholdMergePreferFirst, want: "80"},
+		"prefer first when empty":           {a: "", b: "90", policy: nagios.ThresholdMergePreferFirst, want: "90"},
+		"mixed inversion":                   {a: "@10:20", b: "15", policy: nagios.ThresholdMergeStricter, wantErr: true},
+		"mixed inversion with prefer first": {a: "@10:20", b: "15", policy: nagios.ThresholdMergePreferFirst, want: "@10:20"},
+		"invalid threshold":                 {a: "@@", b: "15", policy: nagios.ThresholdMergePreferFirst, wantErr: true},
+		"unsupported policy":                {a: "10", b: "15", policy: nagios.ThresholdMergePolicy(99), wantErr: true},
+	}
+
+	for name, tt := range tests {
+		tt := tt
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := nagios.MergeThresholds(tt.a, tt.b, tt.policy)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("nagios.MergeThresholds() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if got != tt.want {
+				t.Errorf("\nwant %q\ngot %q", tt.want, got)
+			}
+
+			// Any merged threshold must be readable by this package.
+			if got != "" {
+				if _, err := nagios.ParseThreshold(got); err != nil {
+					t.Errorf("ParseThreshold(%q): unexpected error: %v", got, err)
+				}
+			}
+		})
+	}
+}
+
+// TestPerformanceDataWarnCritIsInverted asserts that the inversion flag of
+// the Warn and Crit thresholds is reported as expected.
+func TestPerformanceDataWarnCritIsInverted(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		threshold string
+		want      bool
+		wantErr   bool
+	}{
+		"inverted range": {threshold: "@10:20", want: true},
+		"plain value":    {threshold: "10", want: false},
+		"plain range":    {threshold: "10:20", want: false},
+		"empty":          {threshold: "", want: false},
+		"unparseable":    {threshold: "@@", wantErr: true},
+	}
+
+	for name, tt := range tests {
+		tt := tt
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			pd := nagios.PerformanceData{Label: "x", Value: "1", Warn: tt.threshold, Crit: tt.threshold}
+
+			for field, isInverted := range map[string]func() (bool, error){
+				"warn": pd.WarnIsInverted,
+				"crit": pd.CritIsInverted,
+			} {
+				got, err := isInverted()
+				if tt.wantErr {
+					if !errors.Is(err, nagios.ErrInvalidPerformanceDataFormat) {
+						t.Errorf("%s: \nwant error %v\ngot %v", field, nagios.ErrInvalidPerformanceDataFormat, err)
+					}
+					continue
+				}
+
+				if err != nil {
+					t.Fatalf("%s: unexpected error: %v", field, err)
+				}
+
+				if got != tt.want {
+					t.Errorf("%s: \nwant %t\ngot %t", field, tt.want, got)
+				}
+			}
+		})
+	}
+}
+
+// TestPerformanceDataHeadroomToCrit asserts that the signed distance to the
+// nearest Crit threshold boundary is returned as expected.
+func TestPerformanceDataHeadroomToCrit(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		value   string
+		crit    string
+		want    float64
+		wantErr error
+	}{
+		"below threshold":             {value: "75", crit: "90", want: 15},
+		"at threshold":                {value: "90", crit: "90", want: 0},
+		"breached":                    {value: "95.5", crit: "90", want: -5.5},
+		"below lower bound":           {value: "5", crit: "10:", want: -5},
+		"nearest bound is lower":      {value: "12", crit: "10:50", want: 2},
+		"nearest bound is upper":      {value: "45", crit: "10:50", want: 5},
+		"open-ended range":            {value: "20", crit: "10:", want: 10},
+		"open-ended range far above":  {value: "1e6", crit: "~:50", want: -999950},
+		"unbounded range":             {value: "20", crit: "~:", want: math.Inf(1)},
+		"empty crit":                  {value: "20", crit: "", want: math.Inf(1)},
+		"inverted outside below":      {value: "5", crit: "@10:20", want: 5},
+		"inverted outside above":      {value: "23", crit: "@10:20", want: 3},
+		"inverted inside":             {value: "12", crit: "@10:20", want: -2},
+		"inverted at boundary":        {value: "20", crit: "@10:20", want: 0},
+		"inverted open-ended outside": {value: "5", crit: "@10:", want: 5},
+		"undetermined value":          {value: "U", crit: "90", wantErr: nagios.ErrPerformanceDataValueUndetermined},
+		"non-numeric value":           {value: "abc", crit: "90", wantErr: nagios.ErrInvalidPerformanceDataFormat},
+		"unparseable crit":            {value: "20", crit: "@@", wantErr: nagios.ErrInvalidPerformanceDataFormat},
+	}
+
+	for name, tt := range tests {
+		tt := tt
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			pd := nagios.PerformanceData{Label: "x", Value: tt.value, Crit: tt.crit}
+
+			got, err := pd.HeadroomToCrit()
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("\nwant error %v\ngot %v", tt.wantErr, err)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if got != tt.want {
+				t.Errorf("\nwant %v\ngot %v", tt.want, got)
+			}
+		})
+	}
+}
+
+// TestThresholdContains asserts that geometric range membership is reported
+// independently of the inversion flag.
+func TestThresholdContains(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		threshold string
+		value     float64
+		want      bool
+	}{
+		"start boundary":            {threshold: "10:20", value: 10, want: true},
+		"end boundary":              {threshold: "10:20", value: 20, want: true},
+		"inside":                    {threshold: "10:20", value: 15, want: true},
+		"just below start":          {threshold: "10:20", value: 9.999, want: false},
+		"just above end":            {threshold: "10:20", value: 20.001, want: false},
+		"inverted inside":           {threshold: "@10:20", value: 15, want: true},
+		"inverted outside":          {threshold: "@10:20", value: 25, want: false},
+		"implicit zero start":       {threshold: "10", value: 0, want: true},
+		"implicit zero start below": {threshold: "10", value: -0.1, want: false},
+		"infinite end":              {threshold: "10:", value: math.MaxFloat64, want: true},
+		"infinite end positive inf": {threshold: "10:", value: math.Inf(1), want: true},
+		"infinite start":            {threshold: "~:10", value: math.Inf(-1), want: true},
+		"infinite start above end":  {threshold: "~:10", value: 11, want: false},
+		"unbounded":                 {threshold: "~:", value: -1e300, want: true},
+		"NaN":                       {threshold: "~:", value: math.NaN(), want: false},
+	}
+
+	for name, tt := range tests {
+		tt := tt
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			threshold, err := nagios.ParseThreshold(tt.threshold)
+			if err != nil {
+				t.Fatalf("failed to parse threshold %q: %v", tt.threshold, err)
+			}
+
+			if got := threshold.Contains(tt.value); got != tt.want {
+				t.Errorf("\nwant %t for value %v\ngot %t", tt.want, tt.value, got)
+			}
+		})
+	}
+
+	if (nagios.Threshold{}).Contains(0) {
+		t.Error("want empty threshold to contain no values")
+	}
+}
+
+// TestPerformanceDataThresholdBands asserts that threshold bands are
+// returned for plain, range and inverted thresholds with unbounded ends
+// clamped to the inferred bounds of the metric.
+func TestPerformanceDataThresholdBands(t *testing.T) {
+	t.Parallel()
+
+	inf := math.Inf(1)
+
+	tests := map[string]struct {
+		pd       nagios.PerformanceData
+		wantWarn [2]float64
+		wantCrit [2]float64
+		wantErr  bool
+	}{
+		"plain thresholds": {
+			pd:       nagios.PerformanceData{Value: "45", Warn: "80", Crit: "90", Min: "0", Max: "100"},
+			wantWarn: [2]float64{0, 80},
+			wantCrit: [2]float64{0, 90},
+		},
+		"ranges": {
+			pd:       nagios.PerformanceData{Value: "45", Warn: "10:80", Crit: "5:90", Min: "0", Max: "100"},
+			wantWarn: [2]float64{10, 80},
+			wantCrit: [2]float64{5, 90},
+		},
+		"open-ended ranges clamped to min and max": {
+			pd:       nagios.PerformanceData{Value: "45", Warn: "20:", Crit: "~:95", Min: "0", Max: "100"},
+			wantWarn: [2]float64{20, 100},
+			wantCrit: [2]float64{0, 95},
+		},
+		"open-ended ranges clamped to inferred bounds": {
+			pd:       nagios.PerformanceData{Value: "45", Warn: "10:", Crit: "~:90"},
+			wantWarn: [2]float64{10, 90},
+			wantCrit: [2]float64{10, 90},
+		},
+		"inverted thresholds": {
+			pd:       nagios.PerformanceData{Value: "45", Warn: "@~:10", Crit: "@~:5", Min: "0", Max: "100"},
+			wantWarn: [2]float64{0, 10},
+			wantCrit: [2]float64{0, 5},
+		},
+		"inverted range": {
+			pd:       nagios.PerformanceData{Value: "45", Warn: "@40:60", Crit: "@45:55", Min: "0", Max: "100"},
+			wantWarn: [2]float64{40, 60},
+			wantCrit: [2]float64{45, 55},
+		},
+		"empty warn uses inferred range": {
+			pd:       nagios.PerformanceData{Value: "45", Crit: "90", Min: "0", Max: "100"},
+			wantWarn: [2]float64{0, 100},
+			wantCrit: [2]float64{0, 90},
+		},
+		"upper bound cannot be inferred": {
+			pd:       nagios.PerformanceData{Value: "45", Crit: "10:"},
+			wantWarn: [2]float64{10, inf},
+			wantCrit: [2]float64{10, inf},
+		},
+		"unparseable warn": {
+			pd:      nagios.PerformanceData{Value: "45", Warn: "80%", Crit: "90"},
+			wantErr: true,
+		},
+		"unparseable crit": {
+			pd:      nagios.PerformanceData{Value: "45", Warn: "80", Crit: "~"},
+			wantErr: true,
+		},
+	}
+
+	for name, tt := range tests {
+		tt := tt
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			warn, crit, err := tt.pd.ThresholdBands()
+			if tt.wantErr {
+				if !errors.Is(err, nagios.ErrInvalidPerformanceDataFormat) {
+					t.Fatalf("\nwant error %v\ngot %v", nagios.ErrInvalidPerformanceDataFormat, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if warn != tt.wantWarn || crit != tt.wantCrit {
+				t.Errorf(
+					"\nwant warn %v, crit %v\ngot warn %v, crit %v",
+					tt.wantWarn, tt.wantCrit, warn, crit,
+				)
+			}
+		})
+	}
+}
@@ -10,6 +10,7 @@ package nagios
 
 import (
 	"fmt"
+	"regexp"
 	"strings"
 	"testing"
 
@@ -301,6 +302,122 @@ func TestNonEmptyClientPerfDataAndConstructedPluginRetainsExistingTimeMetric(t *
 	}
 }
 
+// TestRegexpsCompiledAtInit asserts that the package level compiled regular
+// expressions are initialized from the expected source patterns.
+func TestRegexpsCompiledAtInit(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		re      *regexp.Regexp
+		pattern string
+	}{
+		"value field":           {re: perfDataValueFieldRe, pattern: perfDataValueFieldRegex},
+		"min max fields":        {re: perfDataMinMaxFieldsRe, pattern: perfDataMinMaxFieldsRegex},
+		"threshold range":       {re: perfDataThresholdRangeSyntaxRe, pattern: perfDataThresholdRangeSyntaxRegex},
+		"value and uom fields":  {re: perfDataValueAndUoMFieldsRe, pattern: perfDataValueAndUoMFieldsRegex},
+		"uom and value fields":  {re: perfDataUoMAndValueFieldsRe, pattern: perfDataUoMAndValueFieldsRegex},
+		"openmetrics name":      {re: metricNameRe, pattern: metricNameRegex},
+		"range digit or inf":    {re: rangeDigitOrInfinityRe},
+		"range invert and span": {re: rangeOptionalInvertAndRangeRe},
+		"range first half":      {re: rangeFirstHalfRe},
+		"range end":             {re: rangeEndRe},
+	}
+
+	for name, tt := range tests {
+		if tt.re == nil {
+			t.Errorf("%s: regular expression not compiled", name)
+			continue
+		}
+
+		if tt.pattern != "" && tt.re.String() != tt.pattern {
+			t.Errorf("%s:\nwant pattern %q\ngot %q", name, tt.pattern, tt.re.String())
+		}
+	}
+}
+
+// TestValidationDoesNotCompileRegexps asserts that parsing and validation
+// helpers do not compile regular expressions on each call by asserting the
+// number of allocations per call.
+//
+// NOTE: This test is intentionally not run in parallel as
+// testing.AllocsPerRun measures allocations across all goroutines. The test
+// is skipped when the race detector is enabled as its instrumentation
+// allocates (see TestRegexpsCompiledAtInit for coverage in that case).
+func TestValidationDoesNotCompileRegexps(t *testing.T) {
+	if raceEnabled {
+		t.Skip("allocation counts are not reliable with the race detector enabled")
+	}
+
+	tests := map[string]struct {
+		fn        func()
+		maxAllocs float64
+	}{
+		"validate value field": {
+			fn:        func() { _ = validatePerfDataValueField("0.260") },
+			maxAllocs: 0,
+		},
+		"validate warn field": {
+			fn:        func() { _ = validatePerfDataWarnField("@10:20") },
+			maxAllocs: 0,
+		},
+		"validate crit field": {
+			fn:        func() { _ = validatePerfDataCritField("~:20") },
+			maxAllocs: 0,
+		},
+		"validate min field": {
+			fn:        func() { _ = validatePerfDataMinField("0") },
+			maxAllocs: 0,
+		},
+		"validate max field": {
+			fn:        func() { _ = validatePerfDataMaxField("100") },
+			maxAllocs: 0,
+		},
+		"parse threshold": {
+			fn:        func() { _, _ = ParseThreshold("@10:20") },
+			maxAllocs: 0,
+		},
+	}
+
+	for name, tt := range tests {
+		if allocs := testing.AllocsPerRun(100, tt.fn); allocs > tt.maxAllocs {
+			t.Errorf("%s:\nwant no more than %v allocations per run\ngot %v", name, tt.maxAllocs, allocs)
+		}
+	}
+}
+
+// TestExtractValueAndUoMReusesCompiledRegexps asserts that extracting the
+// value and unit of measurement uses the package level compiled regular
+// expressions instead of compiling (and assigning) new ones on each call.
+//
+// NOTE: Allocations are not counted here as the returned submatch slices
+// allocate a varying amount depending on build flags (e.g., -race).
+func TestExtractValueAndUoMReusesCompiledRegexps(t *testing.T) {
+	t.Parallel()
+
+	valueAndUoMRe := perfDataValueAndUoMFieldsRe
+	uomAndValueRe := perfDataUoMAndValueFieldsRe
+
+	if valueAndUoMRe == nil || uomAndValueRe == nil {
+		t.Fatal("value and unit of measurement regular expressions not compiled")
+	}
+
+	inputs := []string{"49ms", "0.260", "ms49", "100%", "U"}
+
+	for i := 0; i < 3; i++ {
+		for _, input := range inputs {
+			_, _, _ = extractValueAndUoM(input, PerfDataParseOptions{})
+		}
+	}
+
+	if perfDataValueAndUoMFieldsRe != valueAndUoMRe {
+		t.Error("value and uom regular expression replaced after use")
+	}
+
+	if perfDataUoMAndValueFieldsRe != uomAndValueRe {
+		t.Error("uom and value regular expression replaced after use")
+	}
+}
+
 // addTestTimeMetric attaches a test `time` performance data metric regardless
 // of whether an existing value is present in the collection. The test metric
 // is also returned as a convenience.
@@ -322,3 +439,68 @@ func addTestTimeMetric(t *testing.T, p *Plugin) PerformanceData {
 
 	return runtimeMetric
 }
+
+// TestFastValidateScannersMatchRegexps asserts that the regular expression
+// free scanners used by FastValidate agree with the regular expressions used
+// by Validate for a broad corpus of inputs.
+func TestFastValidateScannersMatchRegexps(t *testing.T) {
+	t.Parallel()
+
+	corpus := []string{
+		"", "0", "1", "-1", "+1", "1.5", "-1.5", "+1.5", ".5", "-.5", "5.",
+		"1.2.3", "-", "+", "+-", "--1", "1-2", "1e3", "1E3", "-1e-3", "0x10",
+		"1,5", " 1", "1 ", "U", "u", "NaN", "Inf", "-Infinity", "abc", "1abc",
+		"~:", "~:1", "~:-1.5", "~:1.", "~:~", "~", ":", ":1", "@", "@~:",
+		"@~:1", "@~:-1.5", "@1", "@-1.5", "@1:", "@-1.5:", "@1:2", "@-5.1:-1",
+		"@@1", "@1:@2", "1:", "-1:", "1.5:", "1.:", "0:1", "-5:0.4", "0.1:1.1",
+		"1:2:3", "1::2", "1:-", "1:~", "10:5", "-.5:1", "1:.5", "1.5.5:2",
+		"1:2.", "@~", "~1", "1~:", "12345678901234567890", "1:٣", "٣", "UU",
+		"Up", "5ms", "abc5", "x-y",
+	}
+
+	for _, input := range corpus {
+		if want, got := perfDataValueFieldRe.MatchString(input), containsPerfDataValue(input); got != want {
+			t.Errorf("containsPerfDataValue(%q):\nwant %v\ngot %v", input, want, got)
+		}
+
+		if want, got := perfDataMinMaxFieldsRe.MatchString(input), containsPerfDataNumeric(input); got != want {
+			t.Errorf("containsPerfDataNumeric(%q):\nwant %v\ngot %v", input, want, got)
+		}
+
+		if want, got := perfDataThresholdRangeSyntaxRe.MatchString(input), isPerfDataThresholdSyntax(input); got != want {
+			t.Errorf("isPerfDataThresholdSyntax(%q):\nwant %v\ngot %v", input, want, got)
+		}
+	}
+}
+
+// TestCountPerfDataMetricsMatchesSplit asserts that counting metrics agrees
+// with splitting the input into metrics and that counting stops once the
+// given limit is exceeded.
+func TestCountPerfDataMetricsMatchesSplit(t *testing.T) {
+	t.Parallel()
+
+	inputs := []string{
+		"", " ", "a=1", " a=1 ", "a=1 b=2", "a=1\tb=2\n c=3", "a=1\u00a0b=2",
+		"a=1;;;; b=2;;;;", "a=1,b=2", "a=1, b=2,,", " , a=1 ,\n, ",
+	}
+
+	optsTests := []PerfDataParseOptions{
+		{},
+		{MetricSeparators: ","},
+		{MetricSeparators: ",\n"},
+	}
+
+	for _, opts := range optsTests {
+		for _, input := range inputs {
+			want := len(splitPerfDataMetrics(input, opts))
+			if got := countPerfDataMetrics(input, opts, len(input)+1); got != want {
+				t.Errorf("%q (separators %q):\nwant %d\ngot %d", input, opts.MetricSeparators, want, got)
+			}
+		}
+	}
+
+	large := strings.Repeat("a=1 ", 1000)
+	if got := countPerfDataMetrics(large, PerfDataParseOptions{}, 10); got != 11 {
+		t.Errorf("\nwant counting to stop at %d\ngot %d", 11, got)
+	}
+}
@@ -0,0 +1,254 @@
+// Copyright 2023 Adam Chalkley
+//
+// https://github.com/atc0005/go-nagios
+//
+// Licensed under the MIT License. See LICENSE file in the project root for
+// full license information.
+
+package nagios
+
+import (
+	"math"
+	"strconv"
+	"strings"
+)
+
+// UoM is a typed representation of a performance data Unit of Measurement.
+// This is intended for client code which branches on the unit type (e.g.,
+// formatting byte values differently from time values); the
+// PerformanceData.UnitOfMeasurement field remains a string for flexibility.
+type UoM int
+
+// Units of Measurement documented by the Nagios Plugin Dev Guidelines.
+const (
+	// UoMUnknown indicates a Unit of Measurement not documented by the
+	// Nagios Plugin Dev Guidelines.
+	UoMUnknown UoM = iota
+
+	// UoMNone indicates that no Unit of Measurement is specified; the value
+	// is assumed to be a number (int or float) of things (e.g., users,
+	// processes, load averages).
+	UoMNone
+
+	// UoMSeconds indicates a value in seconds ("s").
+	UoMSeconds
+
+	// UoMMicroseconds indicates a value in microseconds ("us").
+	UoMMicroseconds
+
+	// UoMMilliseconds indicates a value in milliseconds ("ms").
+	UoMMilliseconds
+
+	// UoMPercent indicates a percentage value ("%").
+	UoMPercent
+
+	// UoMBytes indicates a value in bytes ("B").
+	UoMBytes
+
+	// UoMKilobytes indicates a value in kilobytes ("KB").
+	UoMKilobytes
+
+	// UoMMegabytes indicates a value in megabytes ("MB").
+	UoMMegabytes
+
+	// UoMGigabytes indicates a value in gigabytes ("GB").
+	UoMGigabytes
+
+	// UoMTerabytes indicates a value in terabytes ("TB").
+	UoMTerabytes
+
+	// UoMCounter indicates a continuous counter value ("c"), such as bytes
+	// transmitted on an interface.
+	UoMCounter
+)
+
+// uomStrings maps each known UoM value to its string representation.
+var uomStrings = map[UoM]string{
+	UoMNone:         "",
+	UoMSeconds:      "s",
+	UoMMicroseconds: "us",
+	UoMMilliseconds: "ms",
+	UoMPercent:      "%",
+	UoMBytes:        "B",
+	UoMKilobytes:    "KB",
+	UoMMegabytes:    "MB",
+	UoMGigabytes:    "GB",
+	UoMTerabytes:    "TB",
+	UoMCounter:      "c",
+}
+
+// uomDisplayNames maps each known UoM value with a Unit of Measurement to the
+// name used as a suffix by the DisplayKey method.
+var uomDisplayNames = map[UoM]string{
+	UoMSeconds:      "seconds",
+	UoMMicroseconds: "microseconds",
+	UoMMilliseconds: "milliseconds",
+	UoMPercent:      "percent",
+	UoMBytes:        "bytes",
+	UoMKilobytes:    "kilobytes",
+	UoMMegabytes:    "megabytes",
+	UoMGigabytes:    "gigabytes",
+	UoMTerabytes:    "terabytes",
+	UoMCounter:      "counter",
+}
+
+// ParseUoM returns the UoM value for the given Unit of Measurement string
+// and true if the unit is one documented by the Nagios Plugin Dev
+// Guidelines. An empty string is parsed as UoMNone. UoMUnknown and false are
+// returned for any other (unknown) unit. Matching is case-sensitive.
+func ParseUoM(s string) (UoM, bool) {
+	for uom, str := range uomStrings {
+		if str == s {
+			return uom, true
+		}
+	}
+
+	return UoMUnknown, false
+}
+
+// String returns the Unit of Measurement string (e.g., "ms") for the UoM
+// value. An empty string is returned for UoMNone and "unknown" is returned
+// for UoMUnknown (or any unsupported value).
+func (u UoM) String() string {
+	if str, ok := uomStrings[u]; ok {
+		return str
+	}
+
+	return "unknown"
+}
+
+// TypedUoM returns the UoM value for the UnitOfMeasurement field and true if
+// the unit is one documented by the Nagios Plugin Dev Guidelines, otherwise
+// UoMUnknown and false.
+func (pd PerformanceData) TypedUoM() (UoM, bool) {
+	return ParseUoM(pd.UnitOfMeasurement)
+}
+
+// CanonicalizeUoM converts the given Unit of Measurement to its canonical
+// form if it is a case variant of one of the KnownUnitsOfMeasurement values
+// (e.g., "kb" to "KB", "S" to "s"). Leading and trailing whitespace is
+// removed. Unknown units are returned unmodified (aside from whitespace
+// removal).
+//
+// Because "C" is commonly used to indicate degrees Celsius it is NOT
+// converted to "c" (continuous counter).
+func CanonicalizeUoM(s string) string {
+	uom := strings.TrimSpace(s)
+
+	if inList(uom, KnownUnitsOfMeasurement, false) {
+		return uom
+	}
+
+	for _, known := range KnownUnitsOfMeasurement {
+		if known == UoMCounter.String() {
+			continue
+		}
+
+		if strings.EqualFold(uom, known) {
+			return known
+		}
+	}
+
+	return uom
+}
+
+// humanReadableUnit is a display unit used by HumanReadable along with its
+// size relative to the base unit of its family (bytes or seconds).
+type humanReadableUnit struct {
+	name string
+	size float64
+}
+
+// humanReadableByteUnits are the display units for byte values, largest
+// first. Multiples of bytes use powers of 1024.
+var humanReadableByteUnits = []humanReadableUnit{
+	{name: "TB", size: 1 << 40},
+	{name: "GB", size: 1 << 30},
+	{name: "MB", size: 1 << 20},
+	{name: "KB", size: 1 << 10},
+	{name: "B", size: 1},
+}
+
+// humanReadableTimeUnits are the display units for time values, largest
+// first.
+var humanReadableTimeUnits = []humanReadableUnit{
+	{name: "s", size: 1},
+	{name: "ms", size: 1e-3},
+	{name: "us", size: 1e-6},
+}
+
+// HumanReadable returns the Value and Unit of Measurement in a form suitable
+// for the human readable portion of plugin output. Byte values are scaled to
+// the largest unit (using powers of 1024) for which the value is at least 1
+// (e.g., "1536MB" becomes "1.5 GB") and time values are scaled likewise
+// (e.g., "0.26s" becomes "260 ms"). Scaled values are rounded to at most two
+// decimal places. Percentages are shown with the "%" sign attached (e.g.,
+// "90%").
+//
+// Values with an unknown or unscaled Unit of Measurement (e.g., "c"), values
+// without a Unit of Measurement and non-numeric values (e.g., "U") are
+// returned verbatim as value followed by unit.
+func (pd PerformanceData) HumanReadable() string {
+	verbatim := pd.Value + pd.UnitOfMeasurement
+
+	var units []humanReadableUnit
+	var base float64
+
+	uom, _ := pd.TypedUoM()
+	switch uom {
+	case UoMBytes, UoMKilobytes, UoMMegabytes, UoMGigabytes, UoMTerabytes:
+		units = humanReadableByteUnits
+	case UoMSeconds, UoMMilliseconds, UoMMicroseconds:
+		units = humanReadableTimeUnits
+	default:
+		return verbatim
+	}
+
+	for _, unit := range units {
+		if unit.name == pd.UnitOfMeasurement {
+			base = unit.size
+			break
+		}
+	}
+
+	value, err := strconv.ParseFloat(strings.TrimSpace(pd.Value), 64)
+	if err != nil || math.IsNaN(value) || math.IsInf(value, 0) {
+		return verbatim
+	}
+
+	value *= base
+
+	// Default to the smallest unit for values too small for any other.
+	display := units[len(units)-1]
+	for _, unit := range units {
+		if math.Abs(value) >= unit.size {
+			display = unit
+			break
+		}
+	}
+
+	scaled := math.Round(value/display.size*100) / 100
+
+	return strconv.FormatFloat(scaled, 'f', -1, 64) + " " + display.name
+}
+
+// DisplayKey returns a key combining the Label field with the name of the
+// Unit of Measurement (e.g., "response_time_seconds" for a "response_time"
+// metric in "s" or "used_kilobytes" for a "used" metric in "KB") for use as
+// a key in storage which encodes the unit.
+//
+// The label is returned as-is if no Unit of Measurement is specified or if
+// the unit is not one documented by the Nagios Plugin Dev Guidelines (see
+// ParseUoM); unknown units are omitted rather than guessed at. The label is
+// not sanitized and values are not scaled; see PerfDataToOpenMetrics for
+// fully sanitized metric names using base units.
+func (pd PerformanceData) DisplayKey() string {
+	uom, _ := pd.TypedUoM()
+
+	name, ok := uomDisplayNames[uom]
+	if !ok {
+		return pd.Label
+	}
+
+	return pd.Label + "_" + name
+}
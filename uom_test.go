@@ -0,0 +1,238 @@
+// Copyright 2023 Adam Chalkley
+//
+// https://github.com/atc0005/go-nagios
+//
+// Licensed under the MIT License. See LICENSE file in the project root for
+// full license information.
+
+package nagios_test
+
+import (
+	"testing"
+
+	"github.com/atc0005/go-nagios"
+)
+
+// TestParseUoM asserts that Unit of Measurement strings are converted to the
+// expected typed UoM values and back again.
+func TestParseUoM(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		input  string
+		want   nagios.UoM
+		wantOK bool
+	}{
+		"none":         {input: "", want: nagios.UoMNone, wantOK: true},
+		"seconds":      {input: "s", want: nagios.UoMSeconds, wantOK: true},
+		"microseconds": {input: "us", want: nagios.UoMMicroseconds, wantOK: true},
+		"milliseconds": {input: "ms", want: nagios.UoMMilliseconds, wantOK: true},
+		"percent":      {input: "%", want: nagios.UoMPercent, wantOK: true},
+		"bytes":        {input: "B", want: nagios.UoMBytes, wantOK: true},
+		"kilobytes":    {input: "KB", want: nagios.UoMKilobytes, wantOK: true},
+		"megabytes":    {input: "MB", want: nagios.UoMMegabytes, wantOK: true},
+		"gigabytes":    {input: "GB", want: nagios.UoMGigabytes, wantOK: true},
+		"terabytes":    {input: "TB", want: nagios.UoMTerabytes, wantOK: true},
+		"counter":      {input: "c", want: nagios.UoMCounter, wantOK: true},
+		"unknown":      {input: "d", want: nagios.UoMUnknown, wantOK: false},
+		"wrong case":   {input: "kb", want: nagios.UoMUnknown, wantOK: false},
+	}
+
+	for name, tt := range tests {
+		tt := tt
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			got, ok := nagios.ParseUoM(tt.input)
+			if got != tt.want || ok != tt.wantOK {
+				t.Fatalf("\nwant %v, %t\ngot %v, %t", tt.want, tt.wantOK, got, ok)
+			}
+
+			if ok && got.String() != tt.input {
+				t.Errorf("\nwant String() %q\ngot %q", tt.input, got.String())
+			}
+		})
+	}
+}
+
+// TestPerformanceDataTypedUoM asserts that the typed UoM value is returned
+// for a PerformanceData value.
+func TestPerformanceDataTypedUoM(t *testing.T) {
+	t.Parallel()
+
+	pd := nagios.PerformanceData{Label: "time", Value: "49", UnitOfMeasurement: "ms"}
+	if got, ok := pd.TypedUoM(); got != nagios.UoMMilliseconds || !ok {
+		t.Errorf("\nwant %v, true\ngot %v, %t", nagios.UoMMilliseconds, got, ok)
+	}
+
+	pd.UnitOfMeasurement = "d"
+	if got, ok := pd.TypedUoM(); got != nagios.UoMUnknown || ok {
+		t.Errorf("\nwant %v, false\ngot %v, %t", nagios.UoMUnknown, got, ok)
+	}
+
+	if got := nagios.UoMUnknown.String(); got != "unknown" {
+		t.Errorf("\nwant %q\ngot %q", "unknown", got)
+	}
+}
+
+// TestCanonicalizeUoM asserts that case variants of known units are
+// converted to their canonical form and that unknown units are untouched.
+func TestCanonicalizeUoM(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		input string
+		want  string
+	}{
+		"lowercase kilobytes":         {input: "kb", want: "KB"},
+		"uppercase seconds":           {input: "S", want: "s"},
+		"mixed case milliseconds":     {input: "Ms", want: "ms"},
+		"lowercase bytes":             {input: "b", want: "B"},
+		"surrounding whitespace":      {input: " MB ", want: "MB"},
+		"canonical unit":              {input: "%", want: "%"},
+		"counter":                     {input: "c", want: "c"},
+		"celsius not treated counter": {input: "C", want: "C"},
+		"unknown unit passthrough":    {input: "d", want: "d"},
+		"empty":                       {input: "", want: ""},
+	}
+
+	for name, tt := range tests {
+		tt := tt
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			if got := nagios.CanonicalizeUoM(tt.input); got != tt.want {
+				t.Errorf("\nwant %q\ngot %q", tt.want, got)
+			}
+		})
+	}
+}
+
+// TestParsePerfDataWithOptionsCanonicalizeUoM asserts that units of
+// measurement are canonicalized during parsing only when requested.
+func TestParsePerfDataWithOptionsCanonicalizeUoM(t *testing.T) {
+	t.Parallel()
+
+	const input string = `used=10kb;;;; time=49MS;;;;`
+
+	perfData, err := nagios.ParsePerfDataWithOptions(input, nagios.PerfDataParseOptions{CanonicalizeUoM: true})
+	if err != nil {
+		t.Fatalf("failed to parse input: %v", err)
+	}
+
+	if perfData[0].UnitOfMeasurement != "KB" || perfData[1].UnitOfMeasurement != "ms" {
+		t.Errorf("want canonicalized units, got %q and %q",
+			perfData[0].UnitOfMeasurement, perfData[1].UnitOfMeasurement)
+	}
+
+	perfData, err = nagios.ParsePerfData(input)
+	if err != nil {
+		t.Fatalf("failed to parse input: %v", err)
+	}
+
+	if perfData[0].UnitOfMeasurement != "kb" || perfData[1].UnitOfMeasurement != "MS" {
+		t.Errorf("want original units, got %q and %q",
+			perfData[0].UnitOfMeasurement, perfData[1].UnitOfMeasurement)
+	}
+}
+
+// TestPerformanceDataHumanReadable asserts that values are scaled and
+// formatted as expected based on their Unit of Measurement.
+func TestPerformanceDataHumanReadable(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		value string
+		uom   string
+		want  string
+	}{
+		"megabytes to gigabytes":       {value: "1536", uom: "MB", want: "1.5 GB"},
+		"bytes to kilobytes":           {value: "2048", uom: "B", want: "2 KB"},
+		"small bytes unchanged":        {value: "512", uom: "B", want: "512 B"},
+		"terabytes not scaled further": {value: "2048", uom: "TB", want: "2048 TB"},
+		"kilobytes rounded":            {value: "1000", uom: "KB", want: "1000 KB"},
+		"gigabytes to megabytes":       {value: "0.5", uom: "GB", want: "512 MB"},
+		"zero bytes":                   {value: "0", uom: "B", want: "0 B"},
+		"seconds to milliseconds":      {value: "0.26", uom: "s", want: "260 ms"},
+		"milliseconds to seconds":      {value: "1500", uom: "ms", want: "1.5 s"},
+		"microseconds unchanged":       {value: "49", uom: "us", want: "49 us"},
+		"seconds not scaled further":   {value: "3600", uom: "s", want: "3600 s"},
+		"negative seconds":             {value: "-0.26", uom: "s", want: "-260 ms"},
+		"percent":                      {value: "90", uom: "%", want: "90%"},
+		"counter":                      {value: "1024", uom: "c", want: "1024c"},
+		"no unit":                      {value: "0.260", uom: "", want: "0.260"},
+		"unknown unit":                 {value: "21.5", uom: "C", want: "21.5C"},
+		"undetermined value":           {value: "U", uom: "MB", want: "UMB"},
+	}
+
+	for name, tt := range tests {
+		tt := tt
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			pd := nagios.PerformanceData{Label: "metric", Value: tt.value, UnitOfMeasurement: tt.uom}
+			if got := pd.HumanReadable(); got != tt.want {
+				t.Errorf("\nwant %q\ngot %q", tt.want, got)
+			}
+		})
+	}
+}
+
+// TestPerformanceDataDisplayKey asserts that known units of measurement are
+// appended to the label and that unknown units are omitted.
+func TestPerformanceDataDisplayKey(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		perfData nagios.PerformanceData
+		want     string
+	}{
+		"seconds": {
+			perfData: nagios.PerformanceData{Label: "response_time", Value: "0.25", UnitOfMeasurement: "s"},
+			want:     "response_time_seconds",
+		},
+		"milliseconds": {
+			perfData: nagios.PerformanceData{Label: "time", Value: "49", UnitOfMeasurement: "ms"},
+			want:     "time_milliseconds",
+		},
+		"percent": {
+			perfData: nagios.PerformanceData{Label: "cpu", Value: "45", UnitOfMeasurement: "%"},
+			want:     "cpu_percent",
+		},
+		"kilobytes": {
+			perfData: nagios.PerformanceData{Label: "used", Value: "512", UnitOfMeasurement: "KB"},
+			want:     "used_kilobytes",
+		},
+		"counter": {
+			perfData: nagios.PerformanceData{Label: "rx", Value: "1024", UnitOfMeasurement: "c"},
+			want:     "rx_counter",
+		},
+		"no unit": {
+			perfData: nagios.PerformanceData{Label: "load1", Value: "0.26"},
+			want:     "load1",
+		},
+		"unknown unit omitted": {
+			perfData: nagios.PerformanceData{Label: "expires_leaf", Value: "62", UnitOfMeasurement: "d"},
+			want:     "expires_leaf",
+		},
+		"case variant omitted": {
+			perfData: nagios.PerformanceData{Label: "used", Value: "512", UnitOfMeasurement: "kb"},
+			want:     "used",
+		},
+	}
+
+	for name, tt := range tests {
+		tt := tt
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			if got := tt.perfData.DisplayKey(); got != tt.want {
+				t.Errorf("\nwant %q\ngot %q", tt.want, got)
+			}
+		})
+	}
+}